@@ -1,94 +1,289 @@
-package main
-
-import (
-	"log"
-	"os"
-	"time"
-
-	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
-	"go.uber.org/zap"
-
-	"mqfm-backend/internal/config"
-	adminController "mqfm-backend/internal/controllers/auth/admin"
-	userController "mqfm-backend/internal/controllers/auth/user"
-	catAdminController "mqfm-backend/internal/controllers/category/admin"
-	likeUserController "mqfm-backend/internal/controllers/likes/user"
-	lsController "mqfm-backend/internal/controllers/livestream"
-	playlistUserController "mqfm-backend/internal/controllers/playlist/user"
-	audioAdminController "mqfm-backend/internal/controllers/podcast/audio/admin"
-	lsModel "mqfm-backend/internal/models/livestream"
-	"mqfm-backend/internal/routes"
-	adminAuthService "mqfm-backend/internal/services/auth/admin"
-	userAuthRepo "mqfm-backend/internal/repositories/auth/user"
-	userAuthService "mqfm-backend/internal/services/auth/user"
-	catAdminService "mqfm-backend/internal/services/category/admin"
-	likeUserService "mqfm-backend/internal/services/likes/user"
-	lsService "mqfm-backend/internal/services/livestream"
-	playlistUserService "mqfm-backend/internal/services/playlist/user"
-	audioAdminService "mqfm-backend/internal/services/podcast/audio/admin"
-	"mqfm-backend/internal/utils"
-
-)
-
-func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using system environment variables")
-	}
-
-	youtubeAPIKey := os.Getenv("YOUTUBE_API_KEY")
-	if youtubeAPIKey == "" {
-		log.Fatal("YOUTUBE_API_KEY is missing in .env")
-	}
-
-	config.ConnectDatabase()
-	db := config.DB
-
-	db.AutoMigrate(&lsModel.LiveStream{})
-
-	r := gin.Default()
-	r.Static("/uploads", "./uploads")
-
-	adminRepo := adminAuthService.NewAdminAuthService(db)
-	adminCtrl := adminController.NewAdminAuthController(adminRepo)
-
-	userRepository := userAuthRepo.NewUserAuthRepository(db)
-	userService := userAuthService.NewUserAuthService(userRepository)
-	userCtrl := userController.NewUserAuthController(userService)
-
-	catRepo := catAdminService.NewAdminCategoryService(db)
-	catCtrl := catAdminController.NewAdminCategoryController(catRepo)
-
-	audioRepo := audioAdminService.NewAdminAudioService(db)
-	audioCtrl := audioAdminController.NewAdminAudioController(audioRepo, catRepo)
-
-	playlistRepo := playlistUserService.NewUserPlaylistService(db)
-	playlistCtrl := playlistUserController.NewUserPlaylistController(playlistRepo)
-
-	likeRepo := likeUserService.NewUserLikeService(db)
-	likeCtrl := likeUserController.NewUserLikeController(likeRepo)
-
-	mqfmChannelID := "UCwa0rj5KY6bWoVzJtgoiaDw"
-	lsRepo := lsService.NewLiveStreamService(db, youtubeAPIKey)
-	lsCtrl := lsController.NewLiveStreamController(lsRepo)
-
-	go func() {
-		utils.Log.Info("🚀 [Scheduler] Background Task Started: Checking YouTube Live Status...")
-		for {
-			if err := lsRepo.UpdateLiveStatus(mqfmChannelID); err != nil {
-				utils.Log.Error("⚠️ [Scheduler] Error updating status", zap.Error(err))
-			}
-			time.Sleep(10 * time.Minute)
-		}
-	}()
-
-	routes.SetupRoutes(r, adminCtrl, userCtrl, catCtrl, audioCtrl, playlistCtrl, likeCtrl, lsCtrl)
-
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	utils.Log.Info("✅ Server running", zap.String("port", port))
-	r.Run(":" + port)
-}
\ No newline at end of file
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+
+	"mqfm-backend/internal/config"
+	adminController "mqfm-backend/internal/controllers/auth/admin"
+	userController "mqfm-backend/internal/controllers/auth/user"
+	avatarController "mqfm-backend/internal/controllers/avatar"
+	catalogController "mqfm-backend/internal/controllers/catalog"
+	catAdminController "mqfm-backend/internal/controllers/category/admin"
+	duplicateController "mqfm-backend/internal/controllers/duplicate"
+	jobController "mqfm-backend/internal/controllers/job"
+	likeUserController "mqfm-backend/internal/controllers/likes/user"
+	lsController "mqfm-backend/internal/controllers/livestream"
+	mediaController "mqfm-backend/internal/controllers/media"
+	notificationController "mqfm-backend/internal/controllers/notification"
+	playlistUserController "mqfm-backend/internal/controllers/playlist/user"
+	audioAdminController "mqfm-backend/internal/controllers/podcast/audio/admin"
+	feedController "mqfm-backend/internal/controllers/podcast/feed"
+	importController "mqfm-backend/internal/controllers/podcast/importer"
+	showController "mqfm-backend/internal/controllers/podcast/show"
+	speakerController "mqfm-backend/internal/controllers/podcast/speaker"
+	tagController "mqfm-backend/internal/controllers/tag"
+	trashController "mqfm-backend/internal/controllers/trash"
+	uploadController "mqfm-backend/internal/controllers/upload"
+	lsModel "mqfm-backend/internal/models/livestream"
+	userAuthRepo "mqfm-backend/internal/repositories/auth/user"
+	"mqfm-backend/internal/routes"
+	adminAuthService "mqfm-backend/internal/services/auth/admin"
+	userAuthService "mqfm-backend/internal/services/auth/user"
+	avatarService "mqfm-backend/internal/services/avatar"
+	catalogService "mqfm-backend/internal/services/catalog"
+	catAdminService "mqfm-backend/internal/services/category/admin"
+	duplicateService "mqfm-backend/internal/services/duplicate"
+	jobService "mqfm-backend/internal/services/job"
+	likeUserService "mqfm-backend/internal/services/likes/user"
+	lsService "mqfm-backend/internal/services/livestream"
+	"mqfm-backend/internal/services/media"
+	notificationService "mqfm-backend/internal/services/notification"
+	playlistUserService "mqfm-backend/internal/services/playlist/user"
+	audioAdminService "mqfm-backend/internal/services/podcast/audio/admin"
+	feedService "mqfm-backend/internal/services/podcast/feed"
+	importService "mqfm-backend/internal/services/podcast/importer"
+	showService "mqfm-backend/internal/services/podcast/show"
+	speakerService "mqfm-backend/internal/services/podcast/speaker"
+	"mqfm-backend/internal/services/podcast/transcode"
+	"mqfm-backend/internal/services/podcast/waveform"
+	revisionService "mqfm-backend/internal/services/revision"
+	tagService "mqfm-backend/internal/services/tag"
+	trashService "mqfm-backend/internal/services/trash"
+	uploadService "mqfm-backend/internal/services/upload"
+	"mqfm-backend/internal/storage"
+	"mqfm-backend/internal/utils"
+
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	youtubeAPIKey := os.Getenv("YOUTUBE_API_KEY")
+	if youtubeAPIKey == "" {
+		log.Fatal("YOUTUBE_API_KEY is missing in .env")
+	}
+
+	config.ConnectDatabase()
+	db := config.DB
+
+	db.AutoMigrate(&lsModel.LiveStream{})
+
+	storageConfig := config.LoadStorageConfig()
+	if storageConfig.Driver == "s3" && os.Getenv("UPLOAD_STAGING_DIR") == "" {
+		log.Fatal("UPLOAD_STAGING_DIR must point to a folder shared by every instance when STORAGE_DRIVER is s3")
+	}
+	store, err := storage.New(storageConfig)
+	if err != nil {
+		log.Fatal("Storage initialisation failed: ", err)
+	}
+
+	r := gin.Default()
+	if local, ok := store.(*storage.LocalStorage); ok {
+		r.Static("/uploads", local.Path("uploads"))
+	} else {
+		// Keep old relative media paths working by redirecting to the bucket.
+		r.GET("/uploads/*filepath", func(c *gin.Context) {
+			c.Redirect(http.StatusFound, store.URL("uploads"+c.Param("filepath")))
+		})
+	}
+
+	uploadConfig := config.LoadUploadConfig()
+	ingestRepo := media.NewIngestService(store, uploadConfig)
+	cleanupRepo := media.NewCleanupService(db, store)
+
+	tusRepo := uploadService.NewTusService(db, store, ingestRepo, uploadConfig.MaxAudioBytes)
+	tusCtrl := uploadController.NewTusController(tusRepo)
+
+	adminRepo := adminAuthService.NewAdminAuthService(db)
+	adminCtrl := adminController.NewAdminAuthController(adminRepo)
+	if err := adminRepo.SeedAdmin(getEnv("ADMIN_USERNAME", "admin"), os.Getenv("ADMIN_EMAIL"), os.Getenv("ADMIN_PASSWORD")); err != nil {
+		utils.Log.Warn("⚠️ [Admin] No admin account", zap.Error(err))
+	}
+
+	userRepository := userAuthRepo.NewUserAuthRepository(db)
+	userService := userAuthService.NewUserAuthService(userRepository, ingestRepo, cleanupRepo)
+	userCtrl := userController.NewUserAuthController(userService)
+
+	avatarRepo := avatarService.NewAvatarService(db, store)
+	avatarCtrl := avatarController.NewAvatarController(avatarRepo)
+
+	revisionRepo := revisionService.NewRevisionService(db)
+
+	catRepo := catAdminService.NewAdminCategoryService(db, cleanupRepo, revisionRepo)
+	catCtrl := catAdminController.NewAdminCategoryController(catRepo, ingestRepo)
+	if err := catRepo.BackfillSlugs(); err != nil {
+		utils.Log.Warn("⚠️ [Category] Slug backfill failed", zap.Error(err))
+	}
+
+	var encoder transcode.Encoder
+	if ffmpeg, err := transcode.NewFFmpegEncoder(os.Getenv("FFMPEG_PATH")); err != nil {
+		utils.Log.Warn("⚠️ [Transcode] ffmpeg not available, transcoding disabled", zap.Error(err))
+	} else {
+		encoder = ffmpeg
+	}
+	transcodeRepo := transcode.NewTranscodeService(db, store, cleanupRepo, encoder)
+	transcodeRepo.Start()
+
+	waveformRepo := waveform.NewWaveformService(db, store)
+	waveformRepo.Start()
+
+	showRepo := showService.NewShowService(db, cleanupRepo)
+	showCtrl := showController.NewShowController(showRepo, catRepo, ingestRepo)
+
+	speakerRepo := speakerService.NewSpeakerService(db, cleanupRepo)
+	speakerCtrl := speakerController.NewSpeakerController(speakerRepo, ingestRepo)
+
+	tagRepo := tagService.NewTagService(db)
+	tagCtrl := tagController.NewTagController(tagRepo)
+
+	audioRepo := audioAdminService.NewAdminAudioService(db, cleanupRepo, revisionRepo)
+	chapterRepo := audioAdminService.NewChapterService(db, store, ingestRepo, cleanupRepo)
+	audioCtrl := audioAdminController.NewAdminAudioController(audioRepo, catRepo, showRepo, speakerRepo, tagRepo, transcodeRepo, ingestRepo, tusRepo, chapterRepo, waveformRepo)
+
+	notificationRepo := notificationService.NewNotificationService(db)
+	notificationCtrl := notificationController.NewNotificationController(notificationRepo)
+
+	reviewRepo := audioAdminService.NewReviewService(db, notificationRepo)
+	reviewCtrl := audioAdminController.NewAudioReviewController(reviewRepo, audioRepo)
+
+	transcriptRepo := audioAdminService.NewTranscriptService(db)
+	transcriptCtrl := audioAdminController.NewAudioTranscriptController(transcriptRepo, audioRepo)
+
+	chapterCtrl := audioAdminController.NewAudioChapterController(chapterRepo, audioRepo, ingestRepo)
+
+	waveformCtrl := audioAdminController.NewAudioWaveformController(waveformRepo, audioRepo)
+
+	jobRepo := jobService.NewJobService(db)
+	jobCtrl := jobController.NewJobController(jobRepo)
+
+	duplicateRepo := duplicateService.NewDuplicateService(db, store, cleanupRepo, jobRepo)
+	duplicateCtrl := duplicateController.NewDuplicateController(duplicateRepo)
+
+	variantRepo := media.NewVariantBackfillService(db, ingestRepo, jobRepo)
+	mediaCtrl := mediaController.NewMediaController(variantRepo)
+
+	importRepo := importService.NewRSSImportService(db, jobRepo, transcodeRepo, ingestRepo, cleanupRepo)
+	importCtrl := importController.NewRSSImportController(importRepo, catRepo)
+
+	bulkImportRepo := importService.NewBulkImportService(db, jobRepo, audioRepo, tagRepo, transcodeRepo, ingestRepo, uploadConfig.MaxArchiveBytes)
+	bulkImportCtrl := importController.NewBulkImportController(bulkImportRepo)
+	if err := bulkImportRepo.ResumeUnfinished(); err != nil {
+		utils.Log.Warn("⚠️ [Bulk Import] Failed to resume unfinished imports", zap.Error(err))
+	}
+
+	feedRepo := feedService.NewFeedService(db, store, feedService.Config{
+		BaseURL:     getEnv("PUBLIC_BASE_URL", "http://localhost:8080"),
+		Title:       getEnv("PODCAST_TITLE", "MQFM"),
+		Description: getEnv("PODCAST_DESCRIPTION", "Kajian dan podcast MQFM"),
+		Author:      getEnv("PODCAST_AUTHOR", "MQFM"),
+		OwnerEmail:  os.Getenv("PODCAST_OWNER_EMAIL"),
+		ImageURL:    os.Getenv("PODCAST_IMAGE_URL"),
+		Language:    getEnv("PODCAST_LANGUAGE", "id"),
+		Category:    getEnv("PODCAST_CATEGORY", "Religion & Spirituality"),
+		Subcategory: getEnv("PODCAST_SUBCATEGORY", "Islam"),
+	})
+	feedCtrl := feedController.NewFeedController(feedRepo)
+
+	playlistRepo := playlistUserService.NewUserPlaylistService(db, cleanupRepo)
+	playlistCtrl := playlistUserController.NewUserPlaylistController(playlistRepo, ingestRepo)
+
+	likeRepo := likeUserService.NewUserLikeService(db)
+	likeCtrl := likeUserController.NewUserLikeController(likeRepo)
+
+	trashRepo := trashService.NewTrashService(db, cleanupRepo, config.LoadTrashConfig().Retention)
+	trashCtrl := trashController.NewTrashController(trashRepo)
+
+	catalogRepo := catalogService.NewCatalogService(db, store, ingestRepo, cleanupRepo, jobRepo, catRepo, showRepo, audioRepo, tagRepo, transcodeRepo, uploadConfig.MaxArchiveBytes)
+	catalogCtrl := catalogController.NewCatalogController(catalogRepo)
+	if err := catalogRepo.BackfillExternalIDs(); err != nil {
+		utils.Log.Warn("⚠️ [Catalog] Failed to backfill external IDs", zap.Error(err))
+	}
+	if err := catalogRepo.ResumeUnfinished(); err != nil {
+		utils.Log.Warn("⚠️ [Catalog] Failed to resume unfinished imports", zap.Error(err))
+	}
+
+	mqfmChannelID := "UCwa0rj5KY6bWoVzJtgoiaDw"
+	lsRepo := lsService.NewLiveStreamService(db, youtubeAPIKey)
+	lsCtrl := lsController.NewLiveStreamController(lsRepo)
+
+	go func() {
+		utils.Log.Info("🚀 [Scheduler] Background Task Started: Checking YouTube Live Status...")
+		for {
+			if err := lsRepo.UpdateLiveStatus(mqfmChannelID); err != nil {
+				utils.Log.Error("⚠️ [Scheduler] Error updating status", zap.Error(err))
+			}
+			time.Sleep(10 * time.Minute)
+		}
+	}()
+
+	go func() {
+		for {
+			if err := audioRepo.ApplySchedule(); err != nil {
+				utils.Log.Error("⚠️ [Scheduler] Error applying publishing schedule", zap.Error(err))
+			}
+			time.Sleep(1 * time.Minute)
+		}
+	}()
+
+	go func() {
+		for {
+			if err := tusRepo.CleanupExpired(); err != nil {
+				utils.Log.Error("⚠️ [Scheduler] Error cleaning expired uploads", zap.Error(err))
+			}
+			time.Sleep(1 * time.Hour)
+		}
+	}()
+
+	go func() {
+		for {
+			if err := trashRepo.PurgeExpired(); err != nil {
+				utils.Log.Error("⚠️ [Scheduler] Error purging expired trash", zap.Error(err))
+			}
+			time.Sleep(1 * time.Hour)
+		}
+	}()
+
+	go func() {
+		for {
+			if err := transcodeRepo.EnqueueMissing(); err != nil {
+				utils.Log.Error("⚠️ [Scheduler] Error queueing missing renditions", zap.Error(err))
+			}
+			time.Sleep(10 * time.Minute)
+		}
+	}()
+
+	go func() {
+		for {
+			if err := waveformRepo.EnqueueMissing(); err != nil {
+				utils.Log.Error("⚠️ [Scheduler] Error queueing missing waveforms", zap.Error(err))
+			}
+			time.Sleep(10 * time.Minute)
+		}
+	}()
+
+	routes.SetupRoutes(r, adminCtrl, userCtrl, catCtrl, audioCtrl, reviewCtrl, transcriptCtrl, chapterCtrl, waveformCtrl, showCtrl, speakerCtrl, tagCtrl, feedCtrl, importCtrl, bulkImportCtrl, jobCtrl, duplicateCtrl, mediaCtrl, avatarCtrl, notificationCtrl, tusCtrl, playlistCtrl, likeCtrl, lsCtrl, trashCtrl, catalogCtrl)
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	utils.Log.Info("✅ Server running", zap.String("port", port))
+	r.Run(":" + port)
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
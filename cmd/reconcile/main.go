@@ -0,0 +1,68 @@
+// Command reconcile compares stored media against the database. By default it
+// only reports; pass -fix to delete orphaned files and clear dangling references.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"mqfm-backend/internal/config"
+	"mqfm-backend/internal/services/media"
+	"mqfm-backend/internal/storage"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "delete orphaned files and clear dangling references")
+	grace := flag.Duration("grace", time.Hour, "ignore files modified more recently than this")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	config.ConnectDatabase()
+
+	store, err := storage.New(config.LoadStorageConfig())
+	if err != nil {
+		log.Fatal("Storage initialisation failed: ", err)
+	}
+
+	report, err := media.NewCleanupService(config.DB, store).Reconcile(*fix, *grace)
+	if err != nil {
+		log.Fatal("Reconciliation failed: ", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	fmt.Printf("Scanned %d files\n", report.Scanned)
+
+	fmt.Printf("\nOrphaned files (%d):\n", len(report.Orphans))
+	for _, obj := range report.Orphans {
+		fmt.Printf("  %s (%d bytes, modified %s)\n", obj.Key, obj.Size, obj.LastModified.Format(time.RFC3339))
+	}
+
+	fmt.Printf("\nDangling references (%d):\n", len(report.Dangling))
+	for _, ref := range report.Dangling {
+		fmt.Printf("  %s.%s id=%d -> %s\n", ref.Table, ref.Column, ref.ID, ref.Key)
+	}
+
+	if *fix {
+		fmt.Printf("\nDeleted %d files, cleared %d references\n", report.DeletedOrphans, report.ClearedReferences)
+	} else if len(report.Orphans) > 0 || len(report.Dangling) > 0 {
+		fmt.Println("\nDry run: re-run with -fix to apply.")
+	}
+}
@@ -1,36 +1,105 @@
-package config
-
-import (
-	"fmt"
-
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
-
-	adminModel "mqfm-backend/internal/models/auth/admin"
-	userModel "mqfm-backend/internal/models/auth/user"
-	categoryAdminModel "mqfm-backend/internal/models/category/admin"
-	audioAdminModel "mqfm-backend/internal/models/podcast/audio/admin"
-	playlistModel "mqfm-backend/internal/models/playlist/user"
-	likeModel "mqfm-backend/internal/models/likes/user" 
-	"mqfm-backend/internal/utils"
-
-)
-
-var DB *gorm.DB
-
-func ConnectDatabase() {
-	database, err := gorm.Open(sqlite.Open("mqfm.db"), &gorm.Config{})
-	if err != nil {
-		utils.Log.Fatal(fmt.Sprintf("Database connection failed: %v", err))
-	}
-
-	database.AutoMigrate(
-		&adminModel.Admin{},
-		&userModel.User{},
-		&categoryAdminModel.Category{},
-		&audioAdminModel.Audio{},
-		&playlistModel.Playlist{},
-		&likeModel.Like{}, 
-	)
-	DB = database
-}
\ No newline at end of file
+package config
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	adminModel "mqfm-backend/internal/models/auth/admin"
+	userModel "mqfm-backend/internal/models/auth/user"
+	categoryAdminModel "mqfm-backend/internal/models/category/admin"
+	jobModel "mqfm-backend/internal/models/job"
+	likeModel "mqfm-backend/internal/models/likes/user"
+	notificationModel "mqfm-backend/internal/models/notification"
+	playlistModel "mqfm-backend/internal/models/playlist/user"
+	audioAdminModel "mqfm-backend/internal/models/podcast/audio/admin"
+	showModel "mqfm-backend/internal/models/podcast/show"
+	speakerModel "mqfm-backend/internal/models/podcast/speaker"
+	revisionModel "mqfm-backend/internal/models/revision"
+	tagModel "mqfm-backend/internal/models/tag"
+	uploadModel "mqfm-backend/internal/models/upload"
+	"mqfm-backend/internal/utils"
+
+)
+
+var DB *gorm.DB
+
+const databaseFile = "mqfm.db"
+
+func ConnectDatabase() {
+	// Migrations rebuild SQLite tables, which must happen with foreign keys
+	// off, so the schema is migrated on its own connection first.
+	database, err := gorm.Open(sqlite.Open(databaseFile), &gorm.Config{})
+	if err != nil {
+		utils.Log.Fatal(fmt.Sprintf("Database connection failed: %v", err))
+	}
+
+	if err := repairCategoryReferences(database); err != nil {
+		utils.Log.Fatal(fmt.Sprintf("Category reference repair failed: %v", err))
+	}
+
+	database.AutoMigrate(
+		&adminModel.Admin{},
+		&userModel.User{},
+		&categoryAdminModel.Category{},
+		&audioAdminModel.Audio{},
+		&audioAdminModel.AudioRendition{},
+		&audioAdminModel.AudioChapter{},
+		&audioAdminModel.AudioWaveform{},
+		&audioAdminModel.AudioReview{},
+		&audioAdminModel.AudioTranscript{},
+		&audioAdminModel.TranscriptCue{},
+		&showModel.Show{},
+		&speakerModel.Speaker{},
+		&tagModel.Tag{},
+		&playlistModel.Playlist{},
+		&likeModel.Like{},
+		&jobModel.Job{},
+		&uploadModel.Upload{},
+		&uploadModel.UploadChunk{},
+		&notificationModel.Notification{},
+		&revisionModel.Revision{},
+	)
+	if sqlDB, err := database.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	database, err = gorm.Open(sqlite.Open(databaseFile+"?_foreign_keys=on"), &gorm.Config{})
+	if err != nil {
+		utils.Log.Fatal(fmt.Sprintf("Database connection failed: %v", err))
+	}
+	DB = database
+}
+
+// repairCategoryReferences clears category IDs that point nowhere before the
+// foreign keys are added: 0 used to mean "no category", and deleting a
+// category used to leave its audios and shows pointing at the deleted row.
+func repairCategoryReferences(db *gorm.DB) error {
+	if !db.Migrator().HasTable("categories") {
+		return nil
+	}
+
+	live := "SELECT id FROM categories WHERE deleted_at IS NULL"
+	for _, table := range []string{"audios", "shows"} {
+		if !db.Migrator().HasTable(table) {
+			continue
+		}
+		result := db.Exec("UPDATE "+table+" SET category_id = NULL WHERE category_id = 0 OR category_id NOT IN ("+live+")")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			utils.Log.Info("[Category] Cleared dangling category references",
+				zap.String("table", table),
+				zap.Int64("rows", result.RowsAffected),
+			)
+		}
+	}
+
+	if db.Migrator().HasColumn("categories", "parent_id") {
+		return db.Exec("UPDATE categories SET parent_id = NULL WHERE parent_id = 0 OR parent_id NOT IN (SELECT id FROM categories)").Error
+	}
+	return nil
+}
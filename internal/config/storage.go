@@ -0,0 +1,49 @@
+package config
+
+import "os"
+
+// StorageConfig selects and configures the media storage driver.
+//
+// Media and partial tus uploads go through the driver, so any instance can
+// serve them. Import archives are staged under StagingDir instead, since
+// archives are read in place; with the s3 driver, where several instances
+// usually share one bucket, UPLOAD_STAGING_DIR must be set to a folder every
+// instance mounts, so imports can be resumed by whichever restarts first.
+type StorageConfig struct {
+	Driver        string
+	LocalRoot     string
+	PublicBaseURL string
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3UseSSL    bool
+	S3PublicURL string
+}
+
+func LoadStorageConfig() StorageConfig {
+	cfg := StorageConfig{
+		Driver:        os.Getenv("STORAGE_DRIVER"),
+		LocalRoot:     os.Getenv("STORAGE_LOCAL_ROOT"),
+		PublicBaseURL: os.Getenv("PUBLIC_BASE_URL"),
+		S3Endpoint:    os.Getenv("S3_ENDPOINT"),
+		S3Region:      os.Getenv("S3_REGION"),
+		S3Bucket:      os.Getenv("S3_BUCKET"),
+		S3AccessKey:   os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:   os.Getenv("S3_SECRET_KEY"),
+		S3UseSSL:      os.Getenv("S3_USE_SSL") != "false",
+		S3PublicURL:   os.Getenv("S3_PUBLIC_URL"),
+	}
+	if cfg.Driver == "" {
+		cfg.Driver = "local"
+	}
+	if cfg.LocalRoot == "" {
+		cfg.LocalRoot, _ = os.Getwd()
+	}
+	if cfg.PublicBaseURL == "" {
+		cfg.PublicBaseURL = "http://localhost:8080"
+	}
+	return cfg
+}
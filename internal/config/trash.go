@@ -0,0 +1,21 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// TrashConfig controls how long soft-deleted content is kept before the
+// retention job purges it. A zero Retention keeps it until purged by hand.
+type TrashConfig struct {
+	Retention time.Duration
+}
+
+func LoadTrashConfig() TrashConfig {
+	days := 30
+	if value, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil && value >= 0 {
+		days = value
+	}
+	return TrashConfig{Retention: time.Duration(days) * 24 * time.Hour}
+}
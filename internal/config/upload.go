@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// UploadConfig bounds what the media ingestion component accepts.
+type UploadConfig struct {
+	MaxAudioBytes int64
+	MaxImageBytes int64
+	// MaxArchiveBytes bounds ZIP archives sent to the bulk importer.
+	MaxArchiveBytes int64
+}
+
+func LoadUploadConfig() UploadConfig {
+	return UploadConfig{
+		MaxAudioBytes:   envMegabytes("UPLOAD_MAX_AUDIO_MB", 300),
+		MaxImageBytes:   envMegabytes("UPLOAD_MAX_IMAGE_MB", 10),
+		MaxArchiveBytes: envMegabytes("UPLOAD_MAX_ARCHIVE_MB", 4096),
+	}
+}
+
+// StagingDir returns the named folder under the staging root, where import
+// archives wait until they are processed. The root is UPLOAD_STAGING_DIR, or
+// the system temp folder; it must not sit inside the served uploads tree.
+func StagingDir(name string) string {
+	root := os.Getenv("UPLOAD_STAGING_DIR")
+	if root == "" {
+		root = filepath.Join(os.TempDir(), "mqfm-staging")
+	}
+	return filepath.Join(root, name)
+}
+
+func envMegabytes(key string, fallback int64) int64 {
+	if value, err := strconv.ParseInt(os.Getenv(key), 10, 64); err == nil && value > 0 {
+		return value << 20
+	}
+	return fallback << 20
+}
@@ -0,0 +1,72 @@
+package avatar
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	avatarService "mqfm-backend/internal/services/avatar"
+	"mqfm-backend/internal/utils"
+)
+
+type AvatarController struct {
+	service *avatarService.AvatarService
+}
+
+func NewAvatarController(s *avatarService.AvatarService) *AvatarController {
+	return &AvatarController{service: s}
+}
+
+// Show serves a user's avatar. ?size= is the edge in pixels, rounded up to one
+// of the served sizes, and ?format=svg|png picks the encoding for generated
+// avatars.
+func (ctrl *AvatarController) Show(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	size := avatarService.DefaultSize
+	if raw := c.Query("size"); raw != "" {
+		size, err = strconv.Atoi(raw)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid size", nil)
+			return
+		}
+		size = avatarService.SnapSize(size)
+	}
+
+	format := c.DefaultQuery("format", avatarService.FormatSVG)
+	if format != avatarService.FormatSVG && format != avatarService.FormatPNG {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid format", "format must be svg or png")
+		return
+	}
+
+	avatar, err := ctrl.service.Render(uint(id), size, format, c.GetHeader("If-None-Match"))
+	if err != nil {
+		if errors.Is(err, avatarService.ErrUserNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "User not found", err.Error())
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to render avatar", err.Error())
+		return
+	}
+
+	if avatar.RedirectURL != "" {
+		c.Redirect(http.StatusFound, avatar.RedirectURL)
+		return
+	}
+
+	c.Header("ETag", avatar.ETag)
+	c.Header("Last-Modified", avatar.LastModified.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "public, max-age=3600")
+	if avatar.NotModified {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, avatar.ContentType, avatar.Body)
+}
@@ -0,0 +1,73 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	catalogService "mqfm-backend/internal/services/catalog"
+	"mqfm-backend/internal/utils"
+)
+
+type CatalogController struct {
+	service *catalogService.CatalogService
+}
+
+func NewCatalogController(s *catalogService.CatalogService) *CatalogController {
+	return &CatalogController{service: s}
+}
+
+// Export streams the catalogue as a ZIP archive. ?media=false leaves the
+// media files out.
+func (ctrl *CatalogController) Export(c *gin.Context) {
+	withMedia := c.DefaultQuery("media", "true") != "false"
+
+	filename := fmt.Sprintf("catalog-%s.zip", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure can only cut the download short.
+	if err := ctrl.service.Export(c.Writer, withMedia); err != nil {
+		utils.Log.Error("[Catalog] Export failed", zap.Error(err))
+	}
+}
+
+// Import takes an export archive in the "archive" field. With ?dry_run=true
+// it only reports what would be created and updated; otherwise the import
+// runs as a job once every record has passed its checks.
+func (ctrl *CatalogController) Import(c *gin.Context) {
+	file, err := c.FormFile("archive")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Archive file is required", err.Error())
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	report, jobID, err := ctrl.service.Import(utils.GetUserID(c), file, dryRun)
+	if err != nil {
+		var planErr *catalogService.PlanError
+		switch {
+		case errors.As(err, &planErr):
+			utils.ErrorResponse(c, http.StatusUnprocessableEntity, planErr.Error(), planErr.Report)
+		case errors.Is(err, catalogService.ErrArchiveTooLarge):
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Failed to import catalog", err.Error())
+		case errors.Is(err, catalogService.ErrNoManifest), errors.Is(err, catalogService.ErrUnsupportedVersion):
+			utils.ErrorResponse(c, http.StatusUnprocessableEntity, "Failed to import catalog", err.Error())
+		default:
+			utils.Log.Error("Catalog import error: " + err.Error())
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to import catalog", err.Error())
+		}
+		return
+	}
+
+	if dryRun {
+		utils.SuccessResponse(c, http.StatusOK, "Import planned", report)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusAccepted, "Import started", gin.H{"job_id": jobID, "plan": report})
+}
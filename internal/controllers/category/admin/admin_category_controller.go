@@ -1,143 +1,398 @@
-package admin
-
-import (
-	"net/http"
-	"strconv"
-
-	"github.com/gin-gonic/gin"
-
-	categoryModel "mqfm-backend/internal/models/category/admin"
-	categoryService "mqfm-backend/internal/services/category/admin"
-	"mqfm-backend/internal/utils"
-
-)
-
-type AdminCategoryController struct {
-	service *categoryService.AdminCategoryService
-}
-
-func NewAdminCategoryController(s *categoryService.AdminCategoryService) *AdminCategoryController {
-	return &AdminCategoryController{service: s}
-}
-
-func (ctrl *AdminCategoryController) Create(c *gin.Context) {
-	var input struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
-	}
-
-	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
-		return
-	}
-
-	category := categoryModel.Category{
-		Name:        input.Name,
-		Description: input.Description,
-	}
-
-	if err := ctrl.service.Create(&category); err != nil {
-		utils.Log.Error("Category creation error: " + err.Error())
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create category", err.Error())
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusCreated, "Category created successfully", category)
-}
-
-func (ctrl *AdminCategoryController) FindAll(c *gin.Context) {
-	categories, err := ctrl.service.FindAll()
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch categories", err.Error())
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusOK, "Categories retrieved successfully", categories)
-}
-
-func (ctrl *AdminCategoryController) FindByID(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
-		return
-	}
-
-	category, err := ctrl.service.FindByID(uint(id))
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Category not found", err.Error())
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusOK, "Category retrieved successfully", category)
-}
-
-func (ctrl *AdminCategoryController) Update(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
-		return
-	}
-
-	var input struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-	}
-
-	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid update data", err.Error())
-		return
-	}
-
-	updates := make(map[string]interface{})
-	if input.Name != "" {
-		updates["name"] = input.Name
-	}
-	if input.Description != "" {
-		updates["description"] = input.Description
-	}
-
-	updatedCategory, err := ctrl.service.Update(uint(id), updates)
-	if err != nil {
-		utils.Log.Error("Category update error: " + err.Error())
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update category", err.Error())
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusOK, "Category updated successfully", updatedCategory)
-}
-
-func (ctrl *AdminCategoryController) Delete(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
-		return
-	}
-
-	if err := ctrl.service.Delete(uint(id)); err != nil {
-		utils.Log.Error("Category deletion error: " + err.Error())
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete category", err.Error())
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusOK, "Category deleted successfully", nil)
-}
-
-func (ctrl *AdminCategoryController) Search(c *gin.Context) {
-	query := c.Query("q")
-
-	if query == "" {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Search keyword is required", nil)
-		return
-	}
-
-	categories, err := ctrl.service.Search(query)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search categories", err.Error())
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusOK, "Categories found successfully", categories)
-}
\ No newline at end of file
+package admin
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	categoryModel "mqfm-backend/internal/models/category/admin"
+	categoryService "mqfm-backend/internal/services/category/admin"
+	"mqfm-backend/internal/services/media"
+	revisionService "mqfm-backend/internal/services/revision"
+	"mqfm-backend/internal/utils"
+
+)
+
+type AdminCategoryController struct {
+	service *categoryService.AdminCategoryService
+	ingest  *media.IngestService
+}
+
+func NewAdminCategoryController(s *categoryService.AdminCategoryService, is *media.IngestService) *AdminCategoryController {
+	return &AdminCategoryController{service: s, ingest: is}
+}
+
+// Create takes JSON, or multipart form data when uploading icon_file or
+// cover_file. The slug defaults to one derived from the name.
+func (ctrl *AdminCategoryController) Create(c *gin.Context) {
+	var input struct {
+		Name        string                `json:"name" form:"name" binding:"required"`
+		Description string                `json:"description" form:"description"`
+		Slug        string                `json:"slug" form:"slug"`
+		ParentID    uint                  `json:"parent_id" form:"parent_id"`
+		Position    int                   `json:"position" form:"position"`
+		IconFile    *multipart.FileHeader `json:"-" form:"icon_file"`
+		CoverFile   *multipart.FileHeader `json:"-" form:"cover_file"`
+	}
+
+	if err := c.ShouldBind(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	category := categoryModel.Category{
+		Name:        input.Name,
+		Description: input.Description,
+		Slug:        input.Slug,
+		Position:    input.Position,
+	}
+	if input.ParentID != 0 {
+		category.ParentID = &input.ParentID
+	}
+
+	icon, err := ctrl.storeImage(input.IconFile)
+	if err != nil {
+		utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload icon", err.Error())
+		return
+	}
+	if icon != nil {
+		category.Icon = icon.Path
+		category.IconHash = icon.Hash
+		category.IconVariants = icon.Variants
+	}
+
+	cover, err := ctrl.storeImage(input.CoverFile)
+	if err != nil {
+		if icon != nil {
+			ctrl.service.Discard(append(icon.Variants.Keys(), icon.Path)...)
+		}
+		utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload cover", err.Error())
+		return
+	}
+	if cover != nil {
+		category.Cover = cover.Path
+		category.CoverHash = cover.Hash
+		category.CoverVariants = cover.Variants
+	}
+
+	if err := ctrl.service.Create(&category); err != nil {
+		utils.Log.Error("Category creation error: " + err.Error())
+		utils.ErrorResponse(c, statusFor(err), "Failed to create category", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Category created successfully", category)
+}
+
+func (ctrl *AdminCategoryController) FindAll(c *gin.Context) {
+	categories, err := ctrl.service.FindAll()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch categories", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categories retrieved successfully", categories)
+}
+
+func (ctrl *AdminCategoryController) FindByID(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	category, err := ctrl.service.FindByID(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Category not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category retrieved successfully", category)
+}
+
+// Update changes the fields that were sent. A parent_id of 0 moves the
+// category to the top level.
+func (ctrl *AdminCategoryController) Update(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	var input struct {
+		Name        string                `json:"name" form:"name"`
+		Description string                `json:"description" form:"description"`
+		Slug        string                `json:"slug" form:"slug"`
+		ParentID    *uint                 `json:"parent_id" form:"parent_id"`
+		Position    *int                  `json:"position" form:"position"`
+		IconFile    *multipart.FileHeader `json:"-" form:"icon_file"`
+		CoverFile   *multipart.FileHeader `json:"-" form:"cover_file"`
+	}
+
+	if err := c.ShouldBind(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid update data", err.Error())
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if input.Name != "" {
+		updates["name"] = input.Name
+	}
+	if input.Description != "" {
+		updates["description"] = input.Description
+	}
+	if input.Slug != "" {
+		updates["slug"] = input.Slug
+	}
+	if input.ParentID != nil {
+		var parentID *uint
+		if *input.ParentID != 0 {
+			parentID = input.ParentID
+		}
+		updates["parent_id"] = parentID
+	}
+	if input.Position != nil {
+		updates["position"] = *input.Position
+	}
+
+	icon, err := ctrl.storeImage(input.IconFile)
+	if err != nil {
+		utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload icon", err.Error())
+		return
+	}
+	if icon != nil {
+		updates["icon"] = icon.Path
+		updates["icon_hash"] = icon.Hash
+		updates["icon_variants"] = icon.Variants
+	}
+
+	cover, err := ctrl.storeImage(input.CoverFile)
+	if err != nil {
+		if icon != nil {
+			ctrl.service.Discard(append(icon.Variants.Keys(), icon.Path)...)
+		}
+		utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload cover", err.Error())
+		return
+	}
+	if cover != nil {
+		updates["cover"] = cover.Path
+		updates["cover_hash"] = cover.Hash
+		updates["cover_variants"] = cover.Variants
+	}
+
+	updatedCategory, err := ctrl.service.Update(uint(id), utils.GetUserID(c), updates)
+	if err != nil {
+		utils.Log.Error("Category update error: " + err.Error())
+		utils.ErrorResponse(c, statusFor(err), "Failed to update category", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category updated successfully", updatedCategory)
+}
+
+// Delete refuses with 409 while audios or shows are still filed under the
+// category unless ?reassign_to= names the category to move them to.
+func (ctrl *AdminCategoryController) Delete(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	var reassignTo *uint
+	if raw := c.Query("reassign_to"); raw != "" {
+		target, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid reassign_to", nil)
+			return
+		}
+		value := uint(target)
+		reassignTo = &value
+	}
+
+	if err := ctrl.service.Delete(uint(id), reassignTo); err != nil {
+		utils.Log.Error("Category deletion error: " + err.Error())
+		utils.ErrorResponse(c, statusFor(err), "Failed to delete category", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category deleted successfully", nil)
+}
+
+func (ctrl *AdminCategoryController) Search(c *gin.Context) {
+	query := c.Query("q")
+
+	if query == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Search keyword is required", nil)
+		return
+	}
+
+	categories, err := ctrl.service.Search(query)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search categories", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categories found successfully", categories)
+}
+
+func (ctrl *AdminCategoryController) FindBySlug(c *gin.Context) {
+	category, err := ctrl.service.FindBySlug(c.Param("slug"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Category not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category retrieved successfully", category)
+}
+
+// Tree returns the nested categories with public audio counts per node.
+func (ctrl *AdminCategoryController) Tree(c *gin.Context) {
+	tree, err := ctrl.service.Tree()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch category tree", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category tree retrieved successfully", tree)
+}
+
+// Reorder takes {"ids": [...]} and numbers the categories in that order.
+func (ctrl *AdminCategoryController) Reorder(c *gin.Context) {
+	var input struct {
+		IDs []uint `json:"ids" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	if err := ctrl.service.Reorder(input.IDs); err != nil {
+		utils.Log.Error("Category reorder error: " + err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to reorder categories", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categories reordered successfully", nil)
+}
+
+// MoveAudios takes {"to": id, "audio_ids": [...]} and moves those audios, or
+// all of the category's audios when audio_ids is empty, to the target.
+func (ctrl *AdminCategoryController) MoveAudios(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	var input struct {
+		To       uint   `json:"to" binding:"required"`
+		AudioIDs []uint `json:"audio_ids"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	moved, err := ctrl.service.MoveAudios(uint(id), input.To, input.AudioIDs)
+	if err != nil {
+		utils.Log.Error("Category move error: " + err.Error())
+		utils.ErrorResponse(c, statusFor(err), "Failed to move audios", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audios moved successfully", gin.H{"moved": moved})
+}
+
+// Revisions lists the category's edit history, newest first.
+func (ctrl *AdminCategoryController) Revisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	revisions, err := ctrl.service.Revisions(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Category not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Revisions retrieved successfully", revisions)
+}
+
+// DiffRevisions compares the revisions numbered ?from= and ?to=.
+func (ctrl *AdminCategoryController) DiffRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "from and to must be revision numbers", nil)
+		return
+	}
+
+	diff, err := ctrl.service.DiffRevisions(uint(id), from, to)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to compare revisions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Revisions compared successfully", diff)
+}
+
+// Revert sets the category back to an earlier revision.
+func (ctrl *AdminCategoryController) Revert(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid revision number", nil)
+		return
+	}
+
+	category, err := ctrl.service.Revert(uint(id), number, utils.GetUserID(c))
+	if err != nil {
+		utils.Log.Error("Category revert error: " + err.Error())
+		utils.ErrorResponse(c, statusFor(err), "Failed to revert category", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category reverted successfully", category)
+}
+
+// storeImage ingests an uploaded icon or cover, returning nil without a file.
+func (ctrl *AdminCategoryController) storeImage(file *multipart.FileHeader) (*media.Asset, error) {
+	if file == nil {
+		return nil, nil
+	}
+	return ctrl.ingest.IngestFile(media.KindImage, "categories", file)
+}
+
+// statusFor maps a service error to a response code.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, categoryService.ErrInvalidParent), errors.Is(err, categoryService.ErrInvalidTarget):
+		return http.StatusBadRequest
+	case errors.Is(err, categoryService.ErrCategoryInUse):
+		return http.StatusConflict
+	case errors.Is(err, revisionService.ErrRevisionNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
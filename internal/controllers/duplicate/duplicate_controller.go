@@ -0,0 +1,87 @@
+package duplicate
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	duplicateService "mqfm-backend/internal/services/duplicate"
+	"mqfm-backend/internal/utils"
+)
+
+type DuplicateController struct {
+	service *duplicateService.DuplicateService
+}
+
+func NewDuplicateController(s *duplicateService.DuplicateService) *DuplicateController {
+	return &DuplicateController{service: s}
+}
+
+func (ctrl *DuplicateController) Audios(c *gin.Context) {
+	groups, err := ctrl.service.AudioGroups()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch duplicate audios", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Duplicate audios retrieved successfully", groups)
+}
+
+func (ctrl *DuplicateController) Images(c *gin.Context) {
+	groups, err := ctrl.service.ImageGroups()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch duplicate images", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Duplicate images retrieved successfully", groups)
+}
+
+func (ctrl *DuplicateController) MergeAudios(c *gin.Context) {
+	var input struct {
+		KeepID   uint   `json:"keep_id" binding:"required"`
+		MergeIDs []uint `json:"merge_ids" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	audio, err := ctrl.service.MergeAudios(input.KeepID, input.MergeIDs)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to merge audios", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audios merged successfully", audio)
+}
+
+func (ctrl *DuplicateController) MergeImage(c *gin.Context) {
+	var input struct {
+		Hash string `json:"hash" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	group, err := ctrl.service.MergeImage(input.Hash)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to merge images", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Images merged successfully", group)
+}
+
+func (ctrl *DuplicateController) Backfill(c *gin.Context) {
+	jobID, err := ctrl.service.StartBackfill(utils.GetUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start hash backfill", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Hash backfill started", gin.H{"job_id": jobID})
+}
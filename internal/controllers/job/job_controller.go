@@ -0,0 +1,46 @@
+package job
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	jobService "mqfm-backend/internal/services/job"
+	"mqfm-backend/internal/utils"
+)
+
+type JobController struct {
+	service *jobService.JobService
+}
+
+func NewJobController(s *jobService.JobService) *JobController {
+	return &JobController{service: s}
+}
+
+func (ctrl *JobController) FindAll(c *gin.Context) {
+	jobs, err := ctrl.service.FindAll(c.Query("type"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch jobs", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Jobs retrieved successfully", jobs)
+}
+
+func (ctrl *JobController) FindByID(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	job, err := ctrl.service.FindByID(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Job not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Job retrieved successfully", job)
+}
@@ -0,0 +1,28 @@
+package media
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mqfm-backend/internal/services/media"
+	"mqfm-backend/internal/utils"
+)
+
+type MediaController struct {
+	variants *media.VariantBackfillService
+}
+
+func NewMediaController(vs *media.VariantBackfillService) *MediaController {
+	return &MediaController{variants: vs}
+}
+
+func (ctrl *MediaController) BackfillVariants(c *gin.Context) {
+	jobID, err := ctrl.variants.Start(utils.GetUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start variants backfill", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Variants backfill started", gin.H{"job_id": jobID})
+}
@@ -0,0 +1,55 @@
+package notification
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	notificationService "mqfm-backend/internal/services/notification"
+	"mqfm-backend/internal/utils"
+)
+
+type NotificationController struct {
+	service *notificationService.NotificationService
+}
+
+func NewNotificationController(s *notificationService.NotificationService) *NotificationController {
+	return &NotificationController{service: s}
+}
+
+// FindAll lists the caller's notifications; ?unread=true leaves out read ones.
+func (ctrl *NotificationController) FindAll(c *gin.Context) {
+	notifications, err := ctrl.service.FindAll(utils.GetUserID(c), c.Query("unread") == "true")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch notifications", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notifications retrieved successfully", notifications)
+}
+
+func (ctrl *NotificationController) MarkRead(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	if err := ctrl.service.MarkRead(uint(id), utils.GetUserID(c)); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Notification not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notification marked as read", nil)
+}
+
+func (ctrl *NotificationController) MarkAllRead(c *gin.Context) {
+	if err := ctrl.service.MarkAllRead(utils.GetUserID(c)); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to mark notifications as read", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notifications marked as read", nil)
+}
@@ -1,27 +1,26 @@
 package user
 
 import (
-	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	mediaModel "mqfm-backend/internal/models/media"
 	playlistModel "mqfm-backend/internal/models/playlist/user"
+	"mqfm-backend/internal/services/media"
 	playlistService "mqfm-backend/internal/services/playlist/user"
 	"mqfm-backend/internal/utils"
 )
 
 type UserPlaylistController struct {
 	service *playlistService.UserPlaylistService
+	ingest  *media.IngestService
 }
 
-func NewUserPlaylistController(s *playlistService.UserPlaylistService) *UserPlaylistController {
-	return &UserPlaylistController{service: s}
+func NewUserPlaylistController(s *playlistService.UserPlaylistService, is *media.IngestService) *UserPlaylistController {
+	return &UserPlaylistController{service: s, ingest: is}
 }
 
 func (ctrl *UserPlaylistController) GetMyPlaylists(c *gin.Context) {
@@ -90,38 +89,33 @@ func (ctrl *UserPlaylistController) Create(c *gin.Context) {
 	}
 
 	file, _ := c.FormFile("image_file")
-	var imagePath string
+	var imagePath, imageHash string
+	var imageVariants mediaModel.ImageVariants
+	var palette *mediaModel.ColorPalette
 
 	if file != nil {
-		pwd, _ := os.Getwd()
-		uploadDir := filepath.Join(pwd, "uploads", "playlists")
-		if err := os.MkdirAll(uploadDir, 0755); err != nil {
-			utils.Log.Error("[Controller] Failed to create upload directory",
+		asset, err := ctrl.ingest.IngestFile(media.KindImage, "playlists", file)
+		if err != nil {
+			utils.Log.Warn("[Controller] Rejected playlist image",
 				zap.Error(err),
-				zap.String("path", uploadDir),
+				zap.Uint("user_id", userID),
 			)
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create directory", err.Error())
+			utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload image", err.Error())
 			return
 		}
-
-		filename := fmt.Sprintf("%d_%d_%s", userID, time.Now().Unix(), file.Filename)
-		fullPath := filepath.Join(uploadDir, filename)
-
-		if err := c.SaveUploadedFile(file, fullPath); err != nil {
-			utils.Log.Error("[Controller] Failed to save uploaded file",
-				zap.Error(err),
-				zap.String("path", fullPath),
-			)
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to upload image", err.Error())
-			return
-		}
-		imagePath = "uploads/playlists/" + filename
+		imagePath = asset.Path
+		imageHash = asset.Hash
+		imageVariants = asset.Variants
+		palette = asset.Palette
 	}
 
 	newPlaylist := playlistModel.Playlist{
-		UserID:   userID,
-		Name:     input.Name,
-		ImageURL: imagePath,
+		UserID:        userID,
+		Name:          input.Name,
+		ImageURL:      imagePath,
+		ImageHash:     imageHash,
+		ImageVariants: imageVariants,
+		Palette:       palette,
 	}
 
 	if err := ctrl.service.Create(&newPlaylist); err != nil {
@@ -188,4 +182,4 @@ func (ctrl *UserPlaylistController) GetDetail(c *gin.Context) {
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Playlist detail retrieved", playlist)
-}
\ No newline at end of file
+}
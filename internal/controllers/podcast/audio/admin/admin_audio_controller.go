@@ -1,267 +1,749 @@
-package admin
-
-import (
-	"fmt"
-	"mime/multipart"
-	"net/http"
-	"os"
-	"path/filepath"
-	"strconv"
-	"time"
-
-	"github.com/gin-gonic/gin"
-
-	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
-	categoryService "mqfm-backend/internal/services/category/admin" // Import Service Category
-	audioService "mqfm-backend/internal/services/podcast/audio/admin"
-	"mqfm-backend/internal/utils"
-
-)
-
-type AdminAudioController struct {
-	service         *audioService.AdminAudioService
-	categoryService *categoryService.AdminCategoryService // Tambahkan field ini
-}
-
-// Update Constructor: Menerima Category Service juga
-func NewAdminAudioController(s *audioService.AdminAudioService, cs *categoryService.AdminCategoryService) *AdminAudioController {
-	return &AdminAudioController{
-		service:         s,
-		categoryService: cs,
-	}
-}
-
-func (ctrl *AdminAudioController) Create(c *gin.Context) {
-	var input struct {
-		Title         string                `form:"title" binding:"required"`
-		Description   string                `form:"description"`
-		CategoryID    uint                  `form:"category_id"`
-		AudioFile     *multipart.FileHeader `form:"audio_file"`
-		ThumbnailFile *multipart.FileHeader `form:"thumbnail_file"`
-	}
-
-	if err := c.ShouldBind(&input); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err.Error())
-		return
-	}
-
-	// --- VALIDASI KATEGORI ---
-	// Jika CategoryID diisi (tidak 0), cek apakah ada di database
-	if input.CategoryID != 0 {
-		if _, err := ctrl.categoryService.FindByID(input.CategoryID); err != nil {
-			utils.ErrorResponse(c, http.StatusNotFound, "Category ID not found", err.Error())
-			return
-		}
-	}
-	// -------------------------
-
-	pwd, _ := os.Getwd()
-	fmt.Println("DEBUG: Aplikasi berjalan di:", pwd)
-
-	var audioPathDB string
-	if input.AudioFile != nil {
-		uploadDir := filepath.Join(pwd, "uploads", "audios")
-		if err := os.MkdirAll(uploadDir, 0755); err != nil {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create audio directory", err.Error())
-			return
-		}
-
-		audioFilename := fmt.Sprintf("%d_%s", time.Now().Unix(), input.AudioFile.Filename)
-		fullSavePath := filepath.Join(uploadDir, audioFilename)
-
-		fmt.Println("DEBUG: Menyimpan Audio ke:", fullSavePath)
-
-		if err := c.SaveUploadedFile(input.AudioFile, fullSavePath); err != nil {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to upload audio file", err.Error())
-			return
-		}
-
-		audioPathDB = "uploads/audios/" + audioFilename
-	}
-
-	var thumbnailPathDB string
-	if input.ThumbnailFile != nil {
-		uploadDir := filepath.Join(pwd, "uploads", "thumbnails")
-		if err := os.MkdirAll(uploadDir, 0755); err != nil {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create thumbnail directory", err.Error())
-			return
-		}
-
-		thumbFilename := fmt.Sprintf("%d_%s", time.Now().Unix(), input.ThumbnailFile.Filename)
-		fullSavePath := filepath.Join(uploadDir, thumbFilename)
-
-		fmt.Println("DEBUG: Menyimpan Thumbnail ke:", fullSavePath)
-
-		if err := c.SaveUploadedFile(input.ThumbnailFile, fullSavePath); err != nil {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to upload thumbnail", err.Error())
-			return
-		}
-
-		thumbnailPathDB = "uploads/thumbnails/" + thumbFilename
-	}
-
-	audio := audioModel.Audio{
-		Title:       input.Title,
-		Description: input.Description,
-		AudioURL:    audioPathDB,
-		Thumbnail:   thumbnailPathDB,
-		CategoryID:  input.CategoryID,
-	}
-
-	if err := ctrl.service.Create(&audio); err != nil {
-		utils.Log.Error("Audio creation error: " + err.Error())
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create audio", err.Error())
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusCreated, "Audio created successfully", audio)
-}
-
-func (ctrl *AdminAudioController) FindAll(c *gin.Context) {
-	audios, err := ctrl.service.FindAll()
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch audios", err.Error())
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusOK, "Audios retrieved successfully", audios)
-}
-
-func (ctrl *AdminAudioController) FindByID(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
-		return
-	}
-
-	audio, err := ctrl.service.FindByID(uint(id))
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusOK, "Audio retrieved successfully", audio)
-}
-
-func (ctrl *AdminAudioController) Update(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
-		return
-	}
-
-	var input struct {
-		Title         string                `form:"title"`
-		Description   string                `form:"description"`
-		CategoryID    uint                  `form:"category_id"`
-		AudioFile     *multipart.FileHeader `form:"audio_file"`
-		ThumbnailFile *multipart.FileHeader `form:"thumbnail_file"`
-	}
-
-	if err := c.ShouldBind(&input); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid update data", err.Error())
-		return
-	}
-
-	// --- VALIDASI KATEGORI (UPDATE) ---
-	if input.CategoryID != 0 {
-		if _, err := ctrl.categoryService.FindByID(input.CategoryID); err != nil {
-			utils.ErrorResponse(c, http.StatusNotFound, "Category ID not found", err.Error())
-			return
-		}
-	}
-	// ----------------------------------
-
-	updates := make(map[string]interface{})
-
-	if input.Title != "" {
-		updates["title"] = input.Title
-	}
-	if input.Description != "" {
-		updates["description"] = input.Description
-	}
-	if input.CategoryID != 0 {
-		updates["category_id"] = input.CategoryID
-	}
-
-	pwd, _ := os.Getwd()
-
-	if input.AudioFile != nil {
-		uploadDir := filepath.Join(pwd, "uploads", "audios")
-		if err := os.MkdirAll(uploadDir, 0755); err != nil {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create audio directory", err.Error())
-			return
-		}
-
-		audioFilename := fmt.Sprintf("%d_%s", time.Now().Unix(), input.AudioFile.Filename)
-		fullSavePath := filepath.Join(uploadDir, audioFilename)
-
-		if err := c.SaveUploadedFile(input.AudioFile, fullSavePath); err != nil {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to upload audio file", err.Error())
-			return
-		}
-		updates["audio_url"] = "uploads/audios/" + audioFilename
-	}
-
-	if input.ThumbnailFile != nil {
-		uploadDir := filepath.Join(pwd, "uploads", "thumbnails")
-		if err := os.MkdirAll(uploadDir, 0755); err != nil {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create thumbnail directory", err.Error())
-			return
-		}
-
-		thumbFilename := fmt.Sprintf("%d_%s", time.Now().Unix(), input.ThumbnailFile.Filename)
-		fullSavePath := filepath.Join(uploadDir, thumbFilename)
-
-		if err := c.SaveUploadedFile(input.ThumbnailFile, fullSavePath); err != nil {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to upload thumbnail", err.Error())
-			return
-		}
-		updates["thumbnail"] = "uploads/thumbnails/" + thumbFilename
-	}
-
-	updatedAudio, err := ctrl.service.Update(uint(id), updates)
-	if err != nil {
-		utils.Log.Error("Audio update error: " + err.Error())
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update audio", err.Error())
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusOK, "Audio updated successfully", updatedAudio)
-}
-
-func (ctrl *AdminAudioController) Delete(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
-		return
-	}
-
-	if err := ctrl.service.Delete(uint(id)); err != nil {
-		utils.Log.Error("Audio deletion error: " + err.Error())
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete audio", err.Error())
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusOK, "Audio deleted successfully", nil)
-}
-
-func (ctrl *AdminAudioController) Search(c *gin.Context) {
-	query := c.Query("q")
-	
-	if query == "" {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Search keyword is required", nil)
-		return
-	}
-
-	audios, err := ctrl.service.Search(query)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search audios", err.Error())
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusOK, "Audios found successfully", audios)
-}
\ No newline at end of file
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	adminModel "mqfm-backend/internal/models/auth/admin"
+	mediaModel "mqfm-backend/internal/models/media"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	speakerModel "mqfm-backend/internal/models/podcast/speaker"
+	tagModel "mqfm-backend/internal/models/tag"
+	categoryService "mqfm-backend/internal/services/category/admin" // Import Service Category
+	"mqfm-backend/internal/services/media"
+	audioService "mqfm-backend/internal/services/podcast/audio/admin"
+	showService "mqfm-backend/internal/services/podcast/show"
+	speakerService "mqfm-backend/internal/services/podcast/speaker"
+	"mqfm-backend/internal/services/podcast/transcode"
+	"mqfm-backend/internal/services/podcast/waveform"
+	revisionService "mqfm-backend/internal/services/revision"
+	tagService "mqfm-backend/internal/services/tag"
+	uploadService "mqfm-backend/internal/services/upload"
+	"mqfm-backend/internal/utils"
+
+)
+
+type AdminAudioController struct {
+	service         *audioService.AdminAudioService
+	categoryService *categoryService.AdminCategoryService // Tambahkan field ini
+	showService     *showService.ShowService
+	speakerService  *speakerService.SpeakerService
+	tagService      *tagService.TagService
+	transcoder      *transcode.TranscodeService
+	ingest          *media.IngestService
+	uploads         *uploadService.TusService
+	chapters        *audioService.ChapterService
+	waveforms       *waveform.WaveformService
+}
+
+// Update Constructor: Menerima Category Service juga
+func NewAdminAudioController(s *audioService.AdminAudioService, cs *categoryService.AdminCategoryService, ss *showService.ShowService, sps *speakerService.SpeakerService, tgs *tagService.TagService, ts *transcode.TranscodeService, is *media.IngestService, us *uploadService.TusService, chs *audioService.ChapterService, ws *waveform.WaveformService) *AdminAudioController {
+	return &AdminAudioController{
+		service:         s,
+		categoryService: cs,
+		showService:     ss,
+		speakerService:  sps,
+		tagService:      tgs,
+		transcoder:      ts,
+		ingest:          is,
+		uploads:         us,
+		chapters:        chs,
+		waveforms:       ws,
+	}
+}
+
+func (ctrl *AdminAudioController) Create(c *gin.Context) {
+	var input struct {
+		Title         string                `form:"title" binding:"required"`
+		Description   string                `form:"description"`
+		CategoryID    uint                  `form:"category_id"`
+		AudioFile     *multipart.FileHeader `form:"audio_file"`
+		ThumbnailFile *multipart.FileHeader `form:"thumbnail_file"`
+		// IDs of completed resumable uploads, used instead of the multipart files.
+		AudioUploadID     string `form:"audio_upload_id"`
+		ThumbnailUploadID string `form:"thumbnail_upload_id"`
+		// "warn" (default) saves and reports duplicates, "reject" refuses them.
+		OnDuplicate string `form:"on_duplicate" binding:"omitempty,oneof=warn reject"`
+		// Without an episode number the audio is appended to its season.
+		ShowID        uint `form:"show_id"`
+		SeasonNumber  int  `form:"season_number"`
+		EpisodeNumber int  `form:"episode_number"`
+
+		SpeakerIDs []uint `form:"speaker_ids"`
+		TagIDs     []uint `form:"tag_ids"`
+	}
+
+	if err := c.ShouldBind(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err.Error())
+		return
+	}
+
+	// --- VALIDASI KATEGORI ---
+	// Jika CategoryID diisi (tidak 0), cek apakah ada di database
+	var categoryID *uint
+	if input.CategoryID != 0 {
+		if _, err := ctrl.categoryService.FindByID(input.CategoryID); err != nil {
+			utils.ErrorResponse(c, http.StatusNotFound, "Category ID not found", err.Error())
+			return
+		}
+		categoryID = &input.CategoryID
+	}
+	// -------------------------
+
+	var showID *uint
+	if input.ShowID != 0 {
+		if _, err := ctrl.showService.FindByID(input.ShowID); err != nil {
+			utils.ErrorResponse(c, http.StatusNotFound, "Show ID not found", err.Error())
+			return
+		}
+		showID = &input.ShowID
+	}
+
+	speakers, err := ctrl.speakerService.FindByIDs(input.SpeakerIDs)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Speaker ID not found", err.Error())
+		return
+	}
+	tags, err := ctrl.tagService.FindByIDs(input.TagIDs)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Tag ID not found", err.Error())
+		return
+	}
+
+	sched, err := parseSchedule(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid publishing schedule", err.Error())
+		return
+	}
+	// Producers can only create drafts; publishing goes through review.
+	if utils.GetRole(c) == adminModel.RoleProducer {
+		if (sched.Status != "" && sched.Status != audioModel.StatusDraft) || sched.PublishAt != nil {
+			utils.ErrorResponse(c, http.StatusForbidden, "Producers cannot publish audios; submit them for review instead", nil)
+			return
+		}
+		sched = audioService.Schedule{Status: audioModel.StatusDraft}
+	}
+
+	var audioPathDB, audioHash string
+	var audioSize int64
+	var audioDuration int
+	audioAsset, err := ctrl.storeMedia(c, media.KindAudio, "", input.AudioFile, input.AudioUploadID)
+	if err != nil {
+		utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload audio file", err.Error())
+		return
+	}
+	if audioAsset != nil {
+		audioPathDB = audioAsset.Path
+		audioHash = audioAsset.Hash
+		audioSize = audioAsset.Size
+		audioDuration = audioAsset.Duration
+	}
+
+	duplicates, ok := ctrl.checkDuplicates(c, audioAsset, 0, input.OnDuplicate)
+	if !ok {
+		return
+	}
+
+	var thumbnailPathDB, thumbnailHash string
+	var thumbnailVariants mediaModel.ImageVariants
+	var palette *mediaModel.ColorPalette
+	thumbAsset, err := ctrl.storeMedia(c, media.KindImage, "thumbnails", input.ThumbnailFile, input.ThumbnailUploadID)
+	if err != nil {
+		ctrl.service.Discard(audioPathDB)
+		utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload thumbnail", err.Error())
+		return
+	}
+	if thumbAsset != nil {
+		thumbnailPathDB = thumbAsset.Path
+		thumbnailHash = thumbAsset.Hash
+		thumbnailVariants = thumbAsset.Variants
+		palette = thumbAsset.Palette
+	}
+
+	audio := audioModel.Audio{
+		Title:             input.Title,
+		Description:       input.Description,
+		AudioURL:          audioPathDB,
+		AudioHash:         audioHash,
+		Thumbnail:         thumbnailPathDB,
+		ThumbnailHash:     thumbnailHash,
+		ThumbnailVariants: thumbnailVariants,
+		Palette:           palette,
+		Duration:          audioDuration,
+		FileSize:          audioSize,
+		CategoryID:        categoryID,
+		ShowID:            showID,
+		SeasonNumber:      input.SeasonNumber,
+		EpisodeNumber:     input.EpisodeNumber,
+		CreatedBy:         utils.GetUserID(c),
+		Speakers:          speakers,
+		Tags:              tags,
+	}
+
+	if err := ctrl.service.Create(&audio, sched); err != nil {
+		utils.Log.Error("Audio creation error: " + err.Error())
+		utils.ErrorResponse(c, statusFor(err), "Failed to create audio", err.Error())
+		return
+	}
+
+	if audio.AudioURL != "" {
+		ctrl.importChapters(&audio)
+		ctrl.transcoder.Enqueue(audio.ID)
+		ctrl.waveforms.Enqueue(audio.ID)
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, duplicateMessage("Audio created successfully", duplicates), audio)
+}
+
+// FindAll lists public audios; ?tags=fiqh,zakat keeps those with every tag.
+func (ctrl *AdminAudioController) FindAll(c *gin.Context) {
+	audios, err := ctrl.service.FindAll(tagFilter(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch audios", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audios retrieved successfully", audios)
+}
+
+func (ctrl *AdminAudioController) FindByID(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	audio, err := ctrl.service.FindByID(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audio retrieved successfully", audio)
+}
+
+// FindAllAdmin lists audios in every state; ?status= narrows it to one.
+func (ctrl *AdminAudioController) FindAllAdmin(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && !slices.Contains(audioModel.Statuses, status) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid status", nil)
+		return
+	}
+
+	audios, err := ctrl.service.FindAllAdmin(status)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch audios", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audios retrieved successfully", audios)
+}
+
+// Preview returns an audio in any state, including drafts.
+func (ctrl *AdminAudioController) Preview(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	audio, err := ctrl.service.Preview(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audio retrieved successfully", audio)
+}
+
+func (ctrl *AdminAudioController) Update(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	var input struct {
+		Title         string                `form:"title"`
+		Description   string                `form:"description"`
+		CategoryID    uint                  `form:"category_id"`
+		AudioFile     *multipart.FileHeader `form:"audio_file"`
+		ThumbnailFile *multipart.FileHeader `form:"thumbnail_file"`
+		// IDs of completed resumable uploads, used instead of the multipart files.
+		AudioUploadID     string `form:"audio_upload_id"`
+		ThumbnailUploadID string `form:"thumbnail_upload_id"`
+		// "warn" (default) saves and reports duplicates, "reject" refuses them.
+		OnDuplicate string `form:"on_duplicate" binding:"omitempty,oneof=warn reject"`
+	}
+
+	if err := c.ShouldBind(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid update data", err.Error())
+		return
+	}
+
+	// --- VALIDASI KATEGORI (UPDATE) ---
+	if input.CategoryID != 0 {
+		if _, err := ctrl.categoryService.FindByID(input.CategoryID); err != nil {
+			utils.ErrorResponse(c, http.StatusNotFound, "Category ID not found", err.Error())
+			return
+		}
+	}
+	// ----------------------------------
+
+	sched, err := parseSchedule(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid publishing schedule", err.Error())
+		return
+	}
+	if utils.GetRole(c) == adminModel.RoleProducer {
+		if sched != (audioService.Schedule{}) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Producers cannot change the publishing status", nil)
+			return
+		}
+		if !producerCanEdit(c, ctrl.service, uint(id)) {
+			return
+		}
+	}
+
+	updates := make(map[string]interface{})
+
+	if input.Title != "" {
+		updates["title"] = input.Title
+	}
+	if input.Description != "" {
+		updates["description"] = input.Description
+	}
+	if input.CategoryID != 0 {
+		updates["category_id"] = input.CategoryID
+	}
+	if !ctrl.episodeUpdates(c, updates) {
+		return
+	}
+	speakers, setSpeakers, ok := ctrl.speakerUpdates(c)
+	if !ok {
+		return
+	}
+	tags, setTags, ok := ctrl.tagUpdates(c)
+	if !ok {
+		return
+	}
+
+	audioAsset, err := ctrl.storeMedia(c, media.KindAudio, "", input.AudioFile, input.AudioUploadID)
+	if err != nil {
+		utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload audio file", err.Error())
+		return
+	}
+	if audioAsset != nil {
+		updates["audio_url"] = audioAsset.Path
+		updates["audio_hash"] = audioAsset.Hash
+		updates["file_size"] = audioAsset.Size
+		updates["duration"] = audioAsset.Duration
+	}
+
+	duplicates, ok := ctrl.checkDuplicates(c, audioAsset, uint(id), input.OnDuplicate)
+	if !ok {
+		return
+	}
+
+	thumbAsset, err := ctrl.storeMedia(c, media.KindImage, "thumbnails", input.ThumbnailFile, input.ThumbnailUploadID)
+	if err != nil {
+		if audioAsset != nil {
+			ctrl.service.Discard(audioAsset.Path)
+		}
+		utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload thumbnail", err.Error())
+		return
+	}
+	if thumbAsset != nil {
+		updates["thumbnail"] = thumbAsset.Path
+		updates["thumbnail_hash"] = thumbAsset.Hash
+		updates["thumbnail_variants"] = thumbAsset.Variants
+		updates["palette"] = thumbAsset.Palette
+	}
+
+	updatedAudio, err := ctrl.service.Update(uint(id), utils.GetUserID(c), updates, sched)
+	if err != nil {
+		utils.Log.Error("Audio update error: " + err.Error())
+		utils.ErrorResponse(c, statusFor(err), "Failed to update audio", err.Error())
+		return
+	}
+
+	if setSpeakers {
+		if err := ctrl.service.SetSpeakers(updatedAudio.ID, speakers); err != nil {
+			utils.Log.Error("Audio speakers update error: " + err.Error())
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update audio speakers", err.Error())
+			return
+		}
+		updatedAudio.Speakers = speakers
+	}
+	if setTags {
+		if err := ctrl.service.SetTags(updatedAudio.ID, tags); err != nil {
+			utils.Log.Error("Audio tags update error: " + err.Error())
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update audio tags", err.Error())
+			return
+		}
+		updatedAudio.Tags = tags
+	}
+
+	if _, ok := updates["audio_url"]; ok {
+		ctrl.importChapters(updatedAudio)
+		ctrl.transcoder.Enqueue(updatedAudio.ID)
+		ctrl.waveforms.Enqueue(updatedAudio.ID)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, duplicateMessage("Audio updated successfully", duplicates), updatedAudio)
+}
+
+func (ctrl *AdminAudioController) Delete(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	if utils.GetRole(c) == adminModel.RoleProducer && !producerCanEdit(c, ctrl.service, uint(id)) {
+		return
+	}
+
+	if err := ctrl.service.Delete(uint(id)); err != nil {
+		utils.Log.Error("Audio deletion error: " + err.Error())
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete audio", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audio deleted successfully", nil)
+}
+
+// Revisions lists the audio's edit history, newest first.
+func (ctrl *AdminAudioController) Revisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	revisions, err := ctrl.service.Revisions(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Revisions retrieved successfully", revisions)
+}
+
+// DiffRevisions compares the revisions numbered ?from= and ?to=.
+func (ctrl *AdminAudioController) DiffRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "from and to must be revision numbers", nil)
+		return
+	}
+
+	diff, err := ctrl.service.DiffRevisions(uint(id), from, to)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to compare revisions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Revisions compared successfully", diff)
+}
+
+// Revert sets the audio back to an earlier revision. Producers can only
+// revert their own drafts, as with Update.
+func (ctrl *AdminAudioController) Revert(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid revision number", nil)
+		return
+	}
+
+	if utils.GetRole(c) == adminModel.RoleProducer && !producerCanEdit(c, ctrl.service, uint(id)) {
+		return
+	}
+
+	audio, err := ctrl.service.Revert(uint(id), number, utils.GetUserID(c))
+	if err != nil {
+		utils.Log.Error("Audio revert error: " + err.Error())
+		utils.ErrorResponse(c, statusFor(err), "Failed to revert audio", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audio reverted successfully", audio)
+}
+
+// Search takes the same ?tags= filter as FindAll.
+func (ctrl *AdminAudioController) Search(c *gin.Context) {
+	query := c.Query("q")
+
+	if query == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Search keyword is required", nil)
+		return
+	}
+
+	audios, err := ctrl.service.Search(query, tagFilter(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search audios", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audios found successfully", audios)
+}
+
+// episodeUpdates adds show_id, season_number and episode_number to updates
+// when they were sent. An empty or zero show_id takes the audio out of its show.
+func (ctrl *AdminAudioController) episodeUpdates(c *gin.Context, updates map[string]interface{}) bool {
+	if raw, ok := c.GetPostForm("show_id"); ok {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if raw != "" && err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid show ID", nil)
+			return false
+		}
+		var showID *uint
+		if id != 0 {
+			if _, err := ctrl.showService.FindByID(uint(id)); err != nil {
+				utils.ErrorResponse(c, http.StatusNotFound, "Show ID not found", err.Error())
+				return false
+			}
+			value := uint(id)
+			showID = &value
+		}
+		updates["show_id"] = showID
+	}
+
+	for _, field := range []string{"season_number", "episode_number"} {
+		if raw, ok := c.GetPostForm(field); ok && raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				utils.ErrorResponse(c, http.StatusBadRequest, "Invalid "+strings.ReplaceAll(field, "_", " "), nil)
+				return false
+			}
+			updates[field] = n
+		}
+	}
+	return true
+}
+
+// speakerUpdates reads speaker_ids when it was sent. A single empty value
+// removes every speaker from the audio. On bad IDs it answers the request
+// itself and returns ok = false.
+func (ctrl *AdminAudioController) speakerUpdates(c *gin.Context) (speakers []speakerModel.Speaker, sent bool, ok bool) {
+	ids, sent, ok := formIDs(c, "speaker_ids")
+	if !sent || !ok {
+		return nil, sent, ok
+	}
+
+	speakers, err := ctrl.speakerService.FindByIDs(ids)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Speaker ID not found", err.Error())
+		return nil, true, false
+	}
+	return speakers, true, true
+}
+
+// tagUpdates is speakerUpdates for tag_ids.
+func (ctrl *AdminAudioController) tagUpdates(c *gin.Context) (tags []tagModel.Tag, sent bool, ok bool) {
+	ids, sent, ok := formIDs(c, "tag_ids")
+	if !sent || !ok {
+		return nil, sent, ok
+	}
+
+	tags, err := ctrl.tagService.FindByIDs(ids)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Tag ID not found", err.Error())
+		return nil, true, false
+	}
+	return tags, true, true
+}
+
+// formIDs reads a repeated ID field, skipping empty values so a lone empty
+// value means "none".
+func formIDs(c *gin.Context, field string) (ids []uint, sent bool, ok bool) {
+	raw, sent := c.GetPostFormArray(field)
+	if !sent {
+		return nil, false, true
+	}
+
+	ids = make([]uint, 0, len(raw))
+	for _, value := range raw {
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid "+strings.ReplaceAll(field, "_", " "), nil)
+			return nil, true, false
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, true, true
+}
+
+// tagFilter reads ?tags=, a comma-separated list of tag slugs.
+func tagFilter(c *gin.Context) []string {
+	var slugs []string
+	for _, slug := range strings.Split(c.Query("tags"), ",") {
+		if slug = strings.TrimSpace(slug); slug != "" {
+			slugs = append(slugs, slug)
+		}
+	}
+	return slugs
+}
+
+// statusFor maps a service error to a response code.
+func statusFor(err error) int {
+	var inputErr *audioService.InputError
+	if errors.As(err, &inputErr) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, revisionService.ErrRevisionNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// producerCanEdit lets producers change their own drafts while they are not
+// waiting for or past review. It answers the request itself when they can't.
+func producerCanEdit(c *gin.Context, audios *audioService.AdminAudioService, id uint) bool {
+	audio, err := audios.Preview(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return false
+	}
+	if audio.CreatedBy != utils.GetUserID(c) {
+		utils.ErrorResponse(c, http.StatusForbidden, "You can only change your own audios", nil)
+		return false
+	}
+	if audio.Status != audioModel.StatusDraft || audio.ReviewStatus == audioModel.ReviewSubmitted || audio.ReviewStatus == audioModel.ReviewApproved {
+		utils.ErrorResponse(c, http.StatusForbidden, "Audio is under review or already approved", nil)
+		return false
+	}
+	return true
+}
+
+// parseSchedule reads status, publish_at and unpublish_at (RFC 3339). An empty
+// unpublish_at clears it.
+func parseSchedule(c *gin.Context) (audioService.Schedule, error) {
+	var sched audioService.Schedule
+
+	sched.Status = c.PostForm("status")
+	if sched.Status != "" && !slices.Contains(audioModel.Statuses, sched.Status) {
+		return sched, fmt.Errorf("status must be one of %s", strings.Join(audioModel.Statuses, ", "))
+	}
+
+	if raw := c.PostForm("publish_at"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return sched, errors.New("publish_at must be an RFC 3339 timestamp")
+		}
+		sched.PublishAt = &t
+	}
+
+	if raw, ok := c.GetPostForm("unpublish_at"); ok {
+		if raw == "" {
+			sched.ClearUnpublishAt = true
+		} else {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return sched, errors.New("unpublish_at must be an RFC 3339 timestamp")
+			}
+			sched.UnpublishAt = &t
+		}
+	}
+	return sched, nil
+}
+
+// storeMedia ingests either the multipart file or a completed tus upload.
+// It returns nil when neither was supplied.
+func (ctrl *AdminAudioController) storeMedia(c *gin.Context, kind media.Kind, subdir string, file *multipart.FileHeader, uploadID string) (*media.Asset, error) {
+	if file != nil {
+		return ctrl.ingest.IngestFile(kind, subdir, file)
+	}
+	if uploadID != "" {
+		return ctrl.uploads.Consume(uploadID, utils.GetUserID(c), kind, subdir)
+	}
+	return nil, nil
+}
+
+// importChapters adds the chapters tagged in a newly stored audio file. The
+// audio is already saved, so a failure is only logged.
+func (ctrl *AdminAudioController) importChapters(audio *audioModel.Audio) {
+	chapters, err := ctrl.chapters.ImportID3(audio.ID)
+	if err != nil {
+		utils.Log.Warn("[Chapters] Failed to import ID3 chapters", zap.Uint("audio_id", audio.ID), zap.Error(err))
+		return
+	}
+	if len(chapters) > 0 {
+		audio.Chapters = chapters
+	}
+}
+
+// checkDuplicates looks for other audios with the same content. In reject mode
+// it answers 409 itself, discards the upload and returns false.
+func (ctrl *AdminAudioController) checkDuplicates(c *gin.Context, asset *media.Asset, excludeID uint, mode string) ([]audioModel.Audio, bool) {
+	if asset == nil {
+		return nil, true
+	}
+
+	duplicates, err := ctrl.service.FindByAudioHash(asset.Hash, excludeID)
+	if err != nil {
+		ctrl.service.Discard(asset.Path)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check for duplicates", err.Error())
+		return nil, false
+	}
+	if len(duplicates) == 0 {
+		return nil, true
+	}
+
+	utils.Log.Warn("Duplicate audio upload",
+		zap.String("hash", asset.Hash),
+		zap.Uints("duplicate_ids", audioIDs(duplicates)),
+	)
+	if mode == "reject" {
+		ctrl.service.Discard(asset.Path)
+		utils.ErrorResponse(c, http.StatusConflict, "Audio file already exists", gin.H{"duplicate_ids": audioIDs(duplicates)})
+		return nil, false
+	}
+	return duplicates, true
+}
+
+func duplicateMessage(message string, duplicates []audioModel.Audio) string {
+	if len(duplicates) == 0 {
+		return message
+	}
+	ids := make([]string, 0, len(duplicates))
+	for _, id := range audioIDs(duplicates) {
+		ids = append(ids, strconv.FormatUint(uint64(id), 10))
+	}
+	return message + "; the file is identical to audio " + strings.Join(ids, ", ")
+}
+
+func audioIDs(audios []audioModel.Audio) []uint {
+	ids := make([]uint, 0, len(audios))
+	for _, a := range audios {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
@@ -0,0 +1,198 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	adminModel "mqfm-backend/internal/models/auth/admin"
+	"mqfm-backend/internal/services/media"
+	audioService "mqfm-backend/internal/services/podcast/audio/admin"
+	"mqfm-backend/internal/utils"
+)
+
+type AudioChapterController struct {
+	service *audioService.ChapterService
+	audios  *audioService.AdminAudioService
+	ingest  *media.IngestService
+}
+
+func NewAudioChapterController(s *audioService.ChapterService, as *audioService.AdminAudioService, is *media.IngestService) *AudioChapterController {
+	return &AudioChapterController{service: s, audios: as, ingest: is}
+}
+
+// PodcastJSON serves a public audio's chapters as a Podcasting 2.0 JSON
+// chapters file, the one linked from the RSS feeds.
+func (ctrl *AudioChapterController) PodcastJSON(c *gin.Context) {
+	id, ok := audioID(c)
+	if !ok {
+		return
+	}
+	if _, err := ctrl.audios.FindByID(id); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return
+	}
+
+	doc, err := ctrl.service.PodcastChapters(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch chapters", err.Error())
+		return
+	}
+	c.Header("Content-Type", "application/json+chapters; charset=utf-8")
+	c.JSON(http.StatusOK, doc)
+}
+
+// FindAll lists the chapters of an audio in any state.
+func (ctrl *AudioChapterController) FindAll(c *gin.Context) {
+	id, ok := audioID(c)
+	if !ok {
+		return
+	}
+	if _, err := ctrl.audios.Preview(id); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return
+	}
+
+	chapters, err := ctrl.service.List(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch chapters", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Chapters retrieved successfully", chapters)
+}
+
+// Create adds a chapter from the title, start_ms, end_ms and url fields, with
+// an optional picture in image_file.
+func (ctrl *AudioChapterController) Create(c *gin.Context) {
+	id, ok := ctrl.editableAudio(c)
+	if !ok {
+		return
+	}
+	input, ok := ctrl.chapterInput(c)
+	if !ok {
+		return
+	}
+
+	chapter, err := ctrl.service.Create(id, input)
+	if err != nil {
+		utils.ErrorResponse(c, chapterStatus(err), "Failed to create chapter", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Chapter created successfully", chapter)
+}
+
+// Update changes the fields that were sent. An empty end_ms clears the end
+// time and remove_image=true drops the picture.
+func (ctrl *AudioChapterController) Update(c *gin.Context) {
+	id, ok := ctrl.editableAudio(c)
+	if !ok {
+		return
+	}
+	chapterID, err := strconv.ParseUint(c.Param("chapterID"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid chapter ID format", nil)
+		return
+	}
+	input, ok := ctrl.chapterInput(c)
+	if !ok {
+		return
+	}
+
+	chapter, err := ctrl.service.Update(id, uint(chapterID), input)
+	if err != nil {
+		utils.ErrorResponse(c, chapterStatus(err), "Failed to update chapter", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Chapter updated successfully", chapter)
+}
+
+func (ctrl *AudioChapterController) Delete(c *gin.Context) {
+	id, ok := ctrl.editableAudio(c)
+	if !ok {
+		return
+	}
+	chapterID, err := strconv.ParseUint(c.Param("chapterID"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid chapter ID format", nil)
+		return
+	}
+
+	if err := ctrl.service.Delete(id, uint(chapterID)); err != nil {
+		utils.ErrorResponse(c, chapterStatus(err), "Failed to delete chapter", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Chapter deleted successfully", nil)
+}
+
+// editableAudio reads the audio ID and checks the caller may change it,
+// answering the request itself when not.
+func (ctrl *AudioChapterController) editableAudio(c *gin.Context) (uint, bool) {
+	id, ok := audioID(c)
+	if !ok {
+		return 0, false
+	}
+	if _, err := ctrl.audios.Preview(id); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return 0, false
+	}
+	if utils.GetRole(c) == adminModel.RoleProducer && !producerCanEdit(c, ctrl.audios, id) {
+		return 0, false
+	}
+	return id, true
+}
+
+// chapterInput reads the chapter fields that were sent and ingests the
+// picture. On bad input it answers the request itself.
+func (ctrl *AudioChapterController) chapterInput(c *gin.Context) (audioService.ChapterInput, bool) {
+	var input audioService.ChapterInput
+	if title, ok := c.GetPostForm("title"); ok {
+		input.Title = &title
+	}
+	if link, ok := c.GetPostForm("url"); ok {
+		input.URL = &link
+	}
+	if raw, ok := c.GetPostForm("start_ms"); ok {
+		ms, err := strconv.Atoi(raw)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid start_ms", nil)
+			return input, false
+		}
+		input.StartMs = &ms
+	}
+	if raw, ok := c.GetPostForm("end_ms"); ok {
+		if raw == "" {
+			input.ClearEndMs = true
+		} else {
+			ms, err := strconv.Atoi(raw)
+			if err != nil {
+				utils.ErrorResponse(c, http.StatusBadRequest, "Invalid end_ms", nil)
+				return input, false
+			}
+			input.EndMs = &ms
+		}
+	}
+	input.RemoveImage = c.PostForm("remove_image") == "true"
+
+	if file, err := c.FormFile("image_file"); err == nil {
+		asset, err := ctrl.ingest.IngestFile(media.KindImage, "chapters", file)
+		if err != nil {
+			utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload chapter image", err.Error())
+			return input, false
+		}
+		input.Image = asset
+	}
+	return input, true
+}
+
+func chapterStatus(err error) int {
+	if errors.Is(err, audioService.ErrChapterNotFound) {
+		return http.StatusNotFound
+	}
+	return statusFor(err)
+}
@@ -0,0 +1,123 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	adminModel "mqfm-backend/internal/models/auth/admin"
+	audioService "mqfm-backend/internal/services/podcast/audio/admin"
+	"mqfm-backend/internal/utils"
+)
+
+type AudioReviewController struct {
+	service *audioService.ReviewService
+	audios  *audioService.AdminAudioService
+}
+
+func NewAudioReviewController(s *audioService.ReviewService, as *audioService.AdminAudioService) *AudioReviewController {
+	return &AudioReviewController{service: s, audios: as}
+}
+
+func (ctrl *AudioReviewController) Pending(c *gin.Context) {
+	audios, err := ctrl.service.Pending()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch review queue", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Review queue retrieved successfully", audios)
+}
+
+// Submit sends a draft for review with an optional comment. Producers can
+// only submit their own audios.
+func (ctrl *AudioReviewController) Submit(c *gin.Context) {
+	id, ok := audioID(c)
+	if !ok {
+		return
+	}
+
+	if utils.GetRole(c) == adminModel.RoleProducer {
+		audio, err := ctrl.audios.Preview(id)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+			return
+		}
+		if audio.CreatedBy != utils.GetUserID(c) {
+			utils.ErrorResponse(c, http.StatusForbidden, "You can only submit your own audios", nil)
+			return
+		}
+	}
+
+	audio, err := ctrl.service.Submit(id, utils.GetUserID(c), c.PostForm("comment"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to submit audio", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audio submitted for review", audio)
+}
+
+// Approve accepts a submission. The optional status, publish_at and
+// unpublish_at fields schedule its release; by default it is published now.
+func (ctrl *AudioReviewController) Approve(c *gin.Context) {
+	id, ok := audioID(c)
+	if !ok {
+		return
+	}
+
+	sched, err := parseSchedule(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid publishing schedule", err.Error())
+		return
+	}
+
+	audio, err := ctrl.service.Approve(id, utils.GetUserID(c), c.PostForm("comment"), sched)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to approve audio", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audio approved", audio)
+}
+
+// Reject sends a submission back; the comment field is required.
+func (ctrl *AudioReviewController) Reject(c *gin.Context) {
+	id, ok := audioID(c)
+	if !ok {
+		return
+	}
+
+	audio, err := ctrl.service.Reject(id, utils.GetUserID(c), c.PostForm("comment"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to reject audio", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audio rejected", audio)
+}
+
+func (ctrl *AudioReviewController) History(c *gin.Context) {
+	id, ok := audioID(c)
+	if !ok {
+		return
+	}
+
+	reviews, err := ctrl.service.History(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch review history", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Review history retrieved successfully", reviews)
+}
+
+func audioID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,149 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	adminModel "mqfm-backend/internal/models/auth/admin"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	audioService "mqfm-backend/internal/services/podcast/audio/admin"
+	"mqfm-backend/internal/utils"
+)
+
+type AudioTranscriptController struct {
+	service *audioService.TranscriptService
+	audios  *audioService.AdminAudioService
+}
+
+func NewAudioTranscriptController(s *audioService.TranscriptService, as *audioService.AdminAudioService) *AudioTranscriptController {
+	return &AudioTranscriptController{service: s, audios: as}
+}
+
+// Upload takes a WebVTT or SRT file in the "file" field and stores it as the
+// transcript in the language named in the path, replacing any there was.
+func (ctrl *AudioTranscriptController) Upload(c *gin.Context) {
+	id, ok := audioID(c)
+	if !ok {
+		return
+	}
+	if _, err := ctrl.audios.Preview(id); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return
+	}
+	if utils.GetRole(c) == adminModel.RoleProducer && !producerCanEdit(c, ctrl.audios, id) {
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Transcript file is required", err.Error())
+		return
+	}
+	src, err := file.Open()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to read transcript", err.Error())
+		return
+	}
+	defer src.Close()
+
+	transcript, err := ctrl.service.Upload(id, utils.GetUserID(c), c.Param("language"), file.Filename, src)
+	if err != nil {
+		utils.ErrorResponse(c, transcriptStatus(err), "Failed to upload transcript", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Transcript uploaded successfully", transcript)
+}
+
+// FindAll lists the languages a public audio has transcripts in.
+func (ctrl *AudioTranscriptController) FindAll(c *gin.Context) {
+	ctrl.list(c, ctrl.audios.FindByID)
+}
+
+// PreviewAll is FindAll for audios in any state.
+func (ctrl *AudioTranscriptController) PreviewAll(c *gin.Context) {
+	ctrl.list(c, ctrl.audios.Preview)
+}
+
+// Find returns a public audio's transcript as JSON cues, or as a WebVTT file
+// with ?format=vtt.
+func (ctrl *AudioTranscriptController) Find(c *gin.Context) {
+	ctrl.show(c, ctrl.audios.FindByID)
+}
+
+// Preview is Find for audios in any state.
+func (ctrl *AudioTranscriptController) Preview(c *gin.Context) {
+	ctrl.show(c, ctrl.audios.Preview)
+}
+
+func (ctrl *AudioTranscriptController) Delete(c *gin.Context) {
+	id, ok := audioID(c)
+	if !ok {
+		return
+	}
+	if utils.GetRole(c) == adminModel.RoleProducer && !producerCanEdit(c, ctrl.audios, id) {
+		return
+	}
+
+	if err := ctrl.service.Delete(id, c.Param("language")); err != nil {
+		utils.ErrorResponse(c, transcriptStatus(err), "Failed to delete transcript", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Transcript deleted successfully", nil)
+}
+
+func (ctrl *AudioTranscriptController) list(c *gin.Context, find func(uint) (*audioModel.Audio, error)) {
+	id, ok := audioID(c)
+	if !ok {
+		return
+	}
+	if _, err := find(id); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return
+	}
+
+	transcripts, err := ctrl.service.List(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch transcripts", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Transcripts retrieved successfully", transcripts)
+}
+
+func (ctrl *AudioTranscriptController) show(c *gin.Context, find func(uint) (*audioModel.Audio, error)) {
+	id, ok := audioID(c)
+	if !ok {
+		return
+	}
+	if _, err := find(id); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return
+	}
+
+	transcript, err := ctrl.service.Find(id, c.Param("language"))
+	if err != nil {
+		utils.ErrorResponse(c, transcriptStatus(err), "Failed to fetch transcript", err.Error())
+		return
+	}
+
+	if c.Query("format") == audioModel.TranscriptVTT {
+		c.Data(http.StatusOK, "text/vtt; charset=utf-8", []byte(audioService.FormatVTT(transcript.Cues)))
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Transcript retrieved successfully", transcript)
+}
+
+func transcriptStatus(err error) int {
+	var transcriptErr *audioService.TranscriptError
+	switch {
+	case errors.As(err, &transcriptErr):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, audioService.ErrTranscriptNotFound):
+		return http.StatusNotFound
+	}
+	return statusFor(err)
+}
@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	adminModel "mqfm-backend/internal/models/auth/admin"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	"mqfm-backend/internal/services/media"
+	audioService "mqfm-backend/internal/services/podcast/audio/admin"
+	"mqfm-backend/internal/services/podcast/waveform"
+	"mqfm-backend/internal/utils"
+)
+
+type AudioWaveformController struct {
+	service *waveform.WaveformService
+	audios  *audioService.AdminAudioService
+}
+
+func NewAudioWaveformController(s *waveform.WaveformService, as *audioService.AdminAudioService) *AudioWaveformController {
+	return &AudioWaveformController{service: s, audios: as}
+}
+
+// Find serves a public audio's peaks in the audiowaveform JSON format, or as
+// its binary .dat file with ?format=dat. ?resolution picks low, medium
+// (default) or high detail.
+func (ctrl *AudioWaveformController) Find(c *gin.Context) {
+	id, ok := audioID(c)
+	if !ok {
+		return
+	}
+	audio, err := ctrl.audios.FindByID(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "dat" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "format must be json or dat", nil)
+		return
+	}
+
+	peaks, err := ctrl.service.Find(id, c.Query("resolution"))
+	switch {
+	case errors.Is(err, waveform.ErrUnknownResolution):
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid resolution", err.Error())
+		return
+	case errors.Is(err, waveform.ErrWaveformNotFound) || (err == nil && peaks.Source != audio.AudioURL):
+		// Peaks for a replaced file are not served while new ones are made.
+		utils.ErrorResponse(c, http.StatusNotFound, "Waveform not available", gin.H{"waveform_status": audio.WaveformStatus})
+		return
+	case err != nil:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch waveform", err.Error())
+		return
+	}
+
+	if format == "dat" {
+		c.Data(http.StatusOK, "application/octet-stream", waveform.ToDat(peaks))
+		return
+	}
+	c.JSON(http.StatusOK, waveform.ToJSON(peaks))
+}
+
+// Regenerate queues the audio's peaks to be computed again.
+func (ctrl *AudioWaveformController) Regenerate(c *gin.Context) {
+	id, ok := audioID(c)
+	if !ok {
+		return
+	}
+	audio, err := ctrl.audios.Preview(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return
+	}
+	if utils.GetRole(c) == adminModel.RoleProducer && !producerCanEdit(c, ctrl.audios, id) {
+		return
+	}
+	if media.NormalizeKey(audio.AudioURL) == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Audio has no stored file to draw a waveform from", nil)
+		return
+	}
+
+	ctrl.service.Enqueue(id)
+	utils.SuccessResponse(c, http.StatusAccepted, "Waveform generation queued", gin.H{"waveform_status": audioModel.WaveformPending})
+}
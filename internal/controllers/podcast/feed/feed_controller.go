@@ -0,0 +1,62 @@
+package feed
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	feedService "mqfm-backend/internal/services/podcast/feed"
+	"mqfm-backend/internal/utils"
+)
+
+type FeedController struct {
+	service *feedService.FeedService
+}
+
+func NewFeedController(s *feedService.FeedService) *FeedController {
+	return &FeedController{service: s}
+}
+
+func (ctrl *FeedController) Global(c *gin.Context) {
+	feed, err := ctrl.service.GlobalFeed()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to build feed", err.Error())
+		return
+	}
+
+	writeFeed(c, feed)
+}
+
+func (ctrl *FeedController) Category(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	feed, err := ctrl.service.CategoryFeed(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Category not found", err.Error())
+		return
+	}
+
+	writeFeed(c, feed)
+}
+
+// writeFeed sends the feed, answering 304 when the client already holds it.
+// Only the ETag is compared: it is a hash of the body, so it changes with
+// anything in the feed, while a date can miss edits that leave the feed.
+func writeFeed(c *gin.Context, feed *feedService.Feed) {
+	c.Header("ETag", feed.ETag)
+	c.Header("Last-Modified", feed.LastModified.Format(http.TimeFormat))
+	c.Header("Cache-Control", "public, max-age=300")
+
+	if utils.ETagMatches(c.GetHeader("If-None-Match"), feed.ETag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", feed.Body)
+}
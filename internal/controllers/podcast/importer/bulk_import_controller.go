@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	importService "mqfm-backend/internal/services/podcast/importer"
+	"mqfm-backend/internal/utils"
+)
+
+type BulkImportController struct {
+	service *importService.BulkImportService
+}
+
+func NewBulkImportController(s *importService.BulkImportService) *BulkImportController {
+	return &BulkImportController{service: s}
+}
+
+// Start takes a ZIP archive in the "archive" field holding the media files
+// and a manifest.csv or manifest.json. Every row is checked before the import
+// job starts; invalid rows are listed in the errors.
+func (ctrl *BulkImportController) Start(c *gin.Context) {
+	file, err := c.FormFile("archive")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Archive file is required", err.Error())
+		return
+	}
+
+	jobID, err := ctrl.service.Start(utils.GetUserID(c), file)
+	if err != nil {
+		var manifestErr *importService.ManifestError
+		switch {
+		case errors.As(err, &manifestErr):
+			utils.ErrorResponse(c, http.StatusUnprocessableEntity, manifestErr.Message, manifestErr.Rows)
+		case errors.Is(err, importService.ErrArchiveTooLarge):
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Failed to start import", err.Error())
+		default:
+			utils.Log.Error("Bulk import error: " + err.Error())
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start import", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Import started", gin.H{"job_id": jobID})
+}
+
+// Resume restarts a failed import from the rows it had not imported yet.
+func (ctrl *BulkImportController) Resume(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return
+	}
+
+	if err := ctrl.service.Resume(uint(id)); err != nil {
+		status := http.StatusNotFound
+		switch {
+		case errors.Is(err, importService.ErrNotResumable):
+			status = http.StatusConflict
+		case errors.Is(err, importService.ErrArchiveGone):
+			status = http.StatusGone
+		}
+		utils.ErrorResponse(c, status, "Failed to resume import", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Import resumed", gin.H{"job_id": id})
+}
@@ -0,0 +1,53 @@
+package importer
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	categoryService "mqfm-backend/internal/services/category/admin"
+	importService "mqfm-backend/internal/services/podcast/importer"
+	"mqfm-backend/internal/utils"
+)
+
+type RSSImportController struct {
+	service         *importService.RSSImportService
+	categoryService *categoryService.AdminCategoryService
+}
+
+func NewRSSImportController(s *importService.RSSImportService, cs *categoryService.AdminCategoryService) *RSSImportController {
+	return &RSSImportController{
+		service:         s,
+		categoryService: cs,
+	}
+}
+
+func (ctrl *RSSImportController) Start(c *gin.Context) {
+	var input struct {
+		FeedURL    string `json:"feed_url" binding:"required,url"`
+		CategoryID uint   `json:"category_id"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	if input.CategoryID != 0 {
+		if _, err := ctrl.categoryService.FindByID(input.CategoryID); err != nil {
+			utils.ErrorResponse(c, http.StatusNotFound, "Category ID not found", err.Error())
+			return
+		}
+	}
+
+	jobID, err := ctrl.service.Start(utils.GetUserID(c), importService.RSSImportPayload{
+		FeedURL:    input.FeedURL,
+		CategoryID: input.CategoryID,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to start import", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Import started", gin.H{"job_id": jobID})
+}
@@ -0,0 +1,241 @@
+package show
+
+import (
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	showModel "mqfm-backend/internal/models/podcast/show"
+	categoryService "mqfm-backend/internal/services/category/admin"
+	"mqfm-backend/internal/services/media"
+	showService "mqfm-backend/internal/services/podcast/show"
+	"mqfm-backend/internal/utils"
+)
+
+type ShowController struct {
+	service         *showService.ShowService
+	categoryService *categoryService.AdminCategoryService
+	ingest          *media.IngestService
+}
+
+func NewShowController(s *showService.ShowService, cs *categoryService.AdminCategoryService, is *media.IngestService) *ShowController {
+	return &ShowController{service: s, categoryService: cs, ingest: is}
+}
+
+func (ctrl *ShowController) Create(c *gin.Context) {
+	var input struct {
+		Title       string                `form:"title" binding:"required"`
+		Description string                `form:"description"`
+		Hosts       string                `form:"hosts"`
+		CategoryID  uint                  `form:"category_id"`
+		ArtworkFile *multipart.FileHeader `form:"artwork_file"`
+	}
+
+	if err := c.ShouldBind(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err.Error())
+		return
+	}
+
+	var categoryID *uint
+	if input.CategoryID != 0 {
+		if _, err := ctrl.categoryService.FindByID(input.CategoryID); err != nil {
+			utils.ErrorResponse(c, http.StatusNotFound, "Category ID not found", err.Error())
+			return
+		}
+		categoryID = &input.CategoryID
+	}
+
+	show := showModel.Show{
+		Title:       input.Title,
+		Description: input.Description,
+		Hosts:       input.Hosts,
+		CategoryID:  categoryID,
+	}
+
+	if input.ArtworkFile != nil {
+		asset, err := ctrl.ingest.IngestFile(media.KindImage, "shows", input.ArtworkFile)
+		if err != nil {
+			utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload artwork", err.Error())
+			return
+		}
+		show.Artwork = asset.Path
+		show.ArtworkHash = asset.Hash
+		show.ArtworkVariants = asset.Variants
+		show.Palette = asset.Palette
+	}
+
+	if err := ctrl.service.Create(&show); err != nil {
+		utils.Log.Error("Show creation error: " + err.Error())
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create show", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Show created successfully", show)
+}
+
+func (ctrl *ShowController) FindAll(c *gin.Context) {
+	shows, err := ctrl.service.FindAll()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch shows", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Shows retrieved successfully", shows)
+}
+
+func (ctrl *ShowController) FindByID(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	show, err := ctrl.service.FindByID(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Show not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Show retrieved successfully", show)
+}
+
+func (ctrl *ShowController) Update(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Title       string                `form:"title"`
+		Description string                `form:"description"`
+		Hosts       string                `form:"hosts"`
+		CategoryID  uint                  `form:"category_id"`
+		ArtworkFile *multipart.FileHeader `form:"artwork_file"`
+	}
+
+	if err := c.ShouldBind(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid update data", err.Error())
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if input.Title != "" {
+		updates["title"] = input.Title
+	}
+	if input.Description != "" {
+		updates["description"] = input.Description
+	}
+	if input.Hosts != "" {
+		updates["hosts"] = input.Hosts
+	}
+	if input.CategoryID != 0 {
+		if _, err := ctrl.categoryService.FindByID(input.CategoryID); err != nil {
+			utils.ErrorResponse(c, http.StatusNotFound, "Category ID not found", err.Error())
+			return
+		}
+		updates["category_id"] = input.CategoryID
+	}
+
+	if input.ArtworkFile != nil {
+		asset, err := ctrl.ingest.IngestFile(media.KindImage, "shows", input.ArtworkFile)
+		if err != nil {
+			utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload artwork", err.Error())
+			return
+		}
+		updates["artwork"] = asset.Path
+		updates["artwork_hash"] = asset.Hash
+		updates["artwork_variants"] = asset.Variants
+		updates["palette"] = asset.Palette
+	}
+
+	show, err := ctrl.service.Update(id, updates)
+	if err != nil {
+		utils.Log.Error("Show update error: " + err.Error())
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update show", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Show updated successfully", show)
+}
+
+func (ctrl *ShowController) Delete(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.Delete(id); err != nil {
+		utils.Log.Error("Show deletion error: " + err.Error())
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete show", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Show deleted successfully", nil)
+}
+
+// Episodes lists a show's published episodes in order; ?season= narrows it.
+func (ctrl *ShowController) Episodes(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	season := 0
+	if raw := c.Query("season"); raw != "" {
+		var err error
+		if season, err = strconv.Atoi(raw); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid season", nil)
+			return
+		}
+	}
+
+	episodes, err := ctrl.service.Episodes(id, season)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Show not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Episodes retrieved successfully", episodes)
+}
+
+// Next returns the episode after an audio in its show, or null at the end.
+func (ctrl *ShowController) Next(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	audio, err := ctrl.service.Next(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Next episode retrieved successfully", audio)
+}
+
+// Previous returns the episode before an audio in its show, or null at the start.
+func (ctrl *ShowController) Previous(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	audio, err := ctrl.service.Previous(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Audio not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Previous episode retrieved successfully", audio)
+}
+
+func paramID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
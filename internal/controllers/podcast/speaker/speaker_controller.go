@@ -0,0 +1,231 @@
+package speaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	speakerModel "mqfm-backend/internal/models/podcast/speaker"
+	"mqfm-backend/internal/services/media"
+	speakerService "mqfm-backend/internal/services/podcast/speaker"
+	"mqfm-backend/internal/utils"
+)
+
+type SpeakerController struct {
+	service *speakerService.SpeakerService
+	ingest  *media.IngestService
+}
+
+func NewSpeakerController(s *speakerService.SpeakerService, is *media.IngestService) *SpeakerController {
+	return &SpeakerController{service: s, ingest: is}
+}
+
+// Create takes social_links as a JSON object of network name to URL, e.g.
+// {"youtube":"https://youtube.com/@example"}.
+func (ctrl *SpeakerController) Create(c *gin.Context) {
+	var input struct {
+		Name        string                `form:"name" binding:"required"`
+		Bio         string                `form:"bio"`
+		SocialLinks string                `form:"social_links"`
+		PhotoFile   *multipart.FileHeader `form:"photo_file"`
+	}
+
+	if err := c.ShouldBind(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err.Error())
+		return
+	}
+
+	links, err := parseSocialLinks(input.SocialLinks)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid social links", err.Error())
+		return
+	}
+
+	speaker := speakerModel.Speaker{
+		Name:        input.Name,
+		Bio:         input.Bio,
+		SocialLinks: links,
+	}
+
+	if input.PhotoFile != nil {
+		asset, err := ctrl.ingest.IngestFile(media.KindImage, "speakers", input.PhotoFile)
+		if err != nil {
+			utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload photo", err.Error())
+			return
+		}
+		speaker.Photo = asset.Path
+		speaker.PhotoHash = asset.Hash
+		speaker.PhotoVariants = asset.Variants
+	}
+
+	if err := ctrl.service.Create(&speaker); err != nil {
+		utils.Log.Error("Speaker creation error: " + err.Error())
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create speaker", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Speaker created successfully", speaker)
+}
+
+func (ctrl *SpeakerController) FindAll(c *gin.Context) {
+	speakers, err := ctrl.service.FindAll()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch speakers", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Speakers retrieved successfully", speakers)
+}
+
+func (ctrl *SpeakerController) FindByID(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	speaker, err := ctrl.service.FindByID(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Speaker not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Speaker retrieved successfully", speaker)
+}
+
+func (ctrl *SpeakerController) Search(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Search keyword is required", nil)
+		return
+	}
+
+	speakers, err := ctrl.service.Search(query)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search speakers", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Search results", speakers)
+}
+
+// Update replaces social_links as a whole when given; "{}" clears them.
+func (ctrl *SpeakerController) Update(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name        string                `form:"name"`
+		Bio         string                `form:"bio"`
+		SocialLinks string                `form:"social_links"`
+		PhotoFile   *multipart.FileHeader `form:"photo_file"`
+	}
+
+	if err := c.ShouldBind(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid update data", err.Error())
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if input.Name != "" {
+		updates["name"] = input.Name
+	}
+	if input.Bio != "" {
+		updates["bio"] = input.Bio
+	}
+	if input.SocialLinks != "" {
+		links, err := parseSocialLinks(input.SocialLinks)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid social links", err.Error())
+			return
+		}
+		updates["social_links"] = links
+	}
+
+	if input.PhotoFile != nil {
+		asset, err := ctrl.ingest.IngestFile(media.KindImage, "speakers", input.PhotoFile)
+		if err != nil {
+			utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to upload photo", err.Error())
+			return
+		}
+		updates["photo"] = asset.Path
+		updates["photo_hash"] = asset.Hash
+		updates["photo_variants"] = asset.Variants
+	}
+
+	speaker, err := ctrl.service.Update(id, updates)
+	if err != nil {
+		utils.Log.Error("Speaker update error: " + err.Error())
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update speaker", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Speaker updated successfully", speaker)
+}
+
+func (ctrl *SpeakerController) Delete(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.Delete(id); err != nil {
+		utils.Log.Error("Speaker deletion error: " + err.Error())
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete speaker", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Speaker deleted successfully", nil)
+}
+
+// Episodes lists the public audios a speaker appears on, newest first.
+func (ctrl *SpeakerController) Episodes(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	audios, err := ctrl.service.Episodes(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Speaker not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Episodes retrieved successfully", audios)
+}
+
+// parseSocialLinks decodes the social_links field and checks every value is
+// an http(s) URL.
+func parseSocialLinks(raw string) (speakerModel.SocialLinks, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var links speakerModel.SocialLinks
+	if err := json.Unmarshal([]byte(raw), &links); err != nil {
+		return nil, fmt.Errorf("social_links must be a JSON object of name to URL")
+	}
+	for name, link := range links {
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("social link %q is not a valid URL", name)
+		}
+	}
+	return links, nil
+}
+
+func paramID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
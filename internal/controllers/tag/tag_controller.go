@@ -0,0 +1,147 @@
+package tag
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	tagService "mqfm-backend/internal/services/tag"
+	"mqfm-backend/internal/utils"
+)
+
+type TagController struct {
+	service *tagService.TagService
+}
+
+func NewTagController(s *tagService.TagService) *TagController {
+	return &TagController{service: s}
+}
+
+func (ctrl *TagController) Create(c *gin.Context) {
+	var input struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	tag, err := ctrl.service.Create(input.Name)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to create tag", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Tag created successfully", tag)
+}
+
+func (ctrl *TagController) FindAll(c *gin.Context) {
+	tags, err := ctrl.service.FindAll()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch tags", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tags retrieved successfully", tags)
+}
+
+// Cloud lists tags in use on public audios with how often each is used.
+func (ctrl *TagController) Cloud(c *gin.Context) {
+	cloud, err := ctrl.service.Cloud()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch tag cloud", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tag cloud retrieved successfully", cloud)
+}
+
+func (ctrl *TagController) Update(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid update data", err.Error())
+		return
+	}
+
+	tag, err := ctrl.service.Rename(id, input.Name)
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to update tag", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tag updated successfully", tag)
+}
+
+func (ctrl *TagController) Delete(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.Delete(id); err != nil {
+		utils.Log.Error("Tag deletion error: " + err.Error())
+		utils.ErrorResponse(c, statusFor(err), "Failed to delete tag", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tag deleted successfully", nil)
+}
+
+// Merge folds the tags in source_ids into the tag in the URL.
+func (ctrl *TagController) Merge(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		SourceIDs []uint `json:"source_ids" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	tag, err := ctrl.service.Merge(id, input.SourceIDs)
+	if err != nil {
+		utils.Log.Error("Tag merge error: " + err.Error())
+		utils.ErrorResponse(c, statusFor(err), "Failed to merge tags", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tags merged successfully", tag)
+}
+
+// statusFor maps a service error to a response code.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, tagService.ErrTagNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, tagService.ErrTagExists):
+		return http.StatusConflict
+	case errors.Is(err, tagService.ErrInvalidTagName), errors.Is(err, tagService.ErrMergeIntoSelf):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func paramID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
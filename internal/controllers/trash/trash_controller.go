@@ -0,0 +1,89 @@
+package trash
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	trashService "mqfm-backend/internal/services/trash"
+	"mqfm-backend/internal/utils"
+)
+
+type TrashController struct {
+	service *trashService.TrashService
+}
+
+func NewTrashController(s *trashService.TrashService) *TrashController {
+	return &TrashController{service: s}
+}
+
+// Summary counts the items in the trash per type.
+func (ctrl *TrashController) Summary(c *gin.Context) {
+	summary, err := ctrl.service.Summary()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch trash", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Trash retrieved successfully", summary)
+}
+
+func (ctrl *TrashController) List(c *gin.Context) {
+	items, err := ctrl.service.List(c.Param("type"))
+	if err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to fetch trash", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Trash retrieved successfully", items)
+}
+
+func (ctrl *TrashController) Restore(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.Restore(c.Param("type"), id); err != nil {
+		utils.ErrorResponse(c, statusFor(err), "Failed to restore item", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Item restored successfully", nil)
+}
+
+// Purge deletes a trashed item and its files for good.
+func (ctrl *TrashController) Purge(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.Purge(c.Param("type"), id); err != nil {
+		utils.Log.Error("Trash purge error: " + err.Error())
+		utils.ErrorResponse(c, statusFor(err), "Failed to purge item", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Item purged successfully", nil)
+}
+
+// statusFor maps a service error to a response code.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, trashService.ErrUnknownType), errors.Is(err, trashService.ErrNotInTrash):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func paramID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID format", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,108 @@
+package upload
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"mqfm-backend/internal/services/media"
+	uploadService "mqfm-backend/internal/services/upload"
+	"mqfm-backend/internal/utils"
+)
+
+const tusVersion = "1.0.0"
+
+type TusController struct {
+	service *uploadService.TusService
+}
+
+func NewTusController(s *uploadService.TusService) *TusController {
+	return &TusController{service: s}
+}
+
+// TusHeaders advertises the protocol version and rejects unsupported clients.
+func (ctrl *TusController) TusHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Tus-Resumable", tusVersion)
+		if c.Request.Method != http.MethodOptions && c.GetHeader("Tus-Resumable") != tusVersion {
+			c.Header("Tus-Version", tusVersion)
+			utils.ErrorResponse(c, http.StatusPreconditionFailed, "Unsupported tus version", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func (ctrl *TusController) Options(c *gin.Context) {
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", "creation,expiration,termination")
+	c.Header("Tus-Max-Size", strconv.FormatInt(ctrl.service.MaxSize(), 10))
+	c.Status(http.StatusNoContent)
+}
+
+func (ctrl *TusController) Create(c *gin.Context) {
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Upload-Length header", nil)
+		return
+	}
+
+	upload, err := ctrl.service.Create(utils.GetUserID(c), length, c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to create upload", err.Error())
+		return
+	}
+
+	c.Header("Location", "/api/admin/uploads/"+upload.ID)
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusCreated)
+}
+
+func (ctrl *TusController) Head(c *gin.Context) {
+	upload, err := ctrl.service.Find(c.Param("id"), utils.GetUserID(c))
+	if err != nil {
+		c.Header("Cache-Control", "no-store")
+		c.Status(media.HTTPStatus(err))
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusOK)
+}
+
+func (ctrl *TusController) Patch(c *gin.Context) {
+	if c.ContentType() != "application/offset+octet-stream" {
+		utils.ErrorResponse(c, http.StatusUnsupportedMediaType, "Content-Type must be application/offset+octet-stream", nil)
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Upload-Offset header", nil)
+		return
+	}
+
+	upload, err := ctrl.service.WriteChunk(c.Param("id"), utils.GetUserID(c), offset, c.Request.Body)
+	if err != nil {
+		utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to write chunk", err.Error())
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusNoContent)
+}
+
+func (ctrl *TusController) Delete(c *gin.Context) {
+	if err := ctrl.service.Terminate(c.Param("id"), utils.GetUserID(c)); err != nil {
+		utils.ErrorResponse(c, media.HTTPStatus(err), "Failed to terminate upload", err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
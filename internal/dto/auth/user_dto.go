@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	mediaModel "mqfm-backend/internal/models/media"
+)
 
 // RegisterRequest defines the input for user registration.
 type RegisterRequest struct {
@@ -26,14 +30,15 @@ type UpdateUserRequest struct {
 
 // UserResponse defines the standard output for user data.
 type UserResponse struct {
-	ID             uint      `json:"id"`
-	Username       string    `json:"username"`
-	Email          string    `json:"email"`
-	Role           string    `json:"role"`
-	ProfilePicture string    `json:"profile_picture"`
-	Initials       string    `json:"initials"`
-	AvatarColor    string    `json:"avatar_color"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	Token          string    `json:"token,omitempty"`
+	ID                     uint                     `json:"id"`
+	Username               string                   `json:"username"`
+	Email                  string                   `json:"email"`
+	Role                   string                   `json:"role"`
+	ProfilePicture         string                   `json:"profile_picture"`
+	ProfilePictureVariants mediaModel.ImageVariants `json:"profile_picture_variants"`
+	Initials               string                   `json:"initials"`
+	AvatarColor            string                   `json:"avatar_color"`
+	CreatedAt              time.Time                `json:"created_at"`
+	UpdatedAt              time.Time                `json:"updated_at"`
+	Token                  string                   `json:"token,omitempty"`
 }
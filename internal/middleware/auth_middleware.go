@@ -1,45 +1,60 @@
-package middleware
-
-import (
-	"net/http"
-	"strings"
-
-	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-
-	"mqfm-backend/internal/utils"
-
-)
-
-func JWTMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header required", nil)
-			c.Abort()
-			return
-		}
-
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authorization format", nil)
-			c.Abort()
-			return
-		}
-
-		token, err := utils.ValidateToken(parts[1])
-		if err != nil || !token.Valid {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token", nil)
-			c.Abort()
-			return
-		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if ok && token.Valid {
-			c.Set("user_id", claims["user_id"])
-			c.Set("role", claims["role"])
-		}
-
-		c.Next()
-	}
-}
\ No newline at end of file
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"mqfm-backend/internal/utils"
+
+)
+
+func JWTMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header required", nil)
+			c.Abort()
+			return
+		}
+
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authorization format", nil)
+			c.Abort()
+			return
+		}
+
+		token, err := utils.ValidateToken(parts[1])
+		if err != nil || !token.Valid {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token", nil)
+			c.Abort()
+			return
+		}
+
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if ok && token.Valid {
+			c.Set("user_id", claims["user_id"])
+			c.Set("role", claims["role"])
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole lets the request through only when the token's role is one of
+// roles. It must run after JWTMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(roles, utils.GetRole(c)) {
+			utils.ErrorResponse(c, http.StatusForbidden, "You do not have permission to perform this action", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,23 +1,33 @@
-package admin
-
-import (
-	"time"
-
-	"gorm.io/gorm"
-
-)
-
-type Admin struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Username  string         `gorm:"unique;not null" json:"username"`
-	Email     string         `gorm:"unique;not null" json:"email"`
-	Password  string         `json:"-"`
-	Role      string         `gorm:"default:admin" json:"role"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
-}
-
-func (Admin) TableName() string {
-	return "admins"
-}
\ No newline at end of file
+package admin
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// Admin roles. Producers upload and submit audios for review, reviewers
+// approve or reject submissions, and admins can do everything.
+const (
+	RoleAdmin    = "admin"
+	RoleReviewer = "reviewer"
+	RoleProducer = "producer"
+)
+
+var Roles = []string{RoleAdmin, RoleReviewer, RoleProducer}
+
+type Admin struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Username  string         `gorm:"unique;not null" json:"username"`
+	Email     string         `gorm:"unique;not null" json:"email"`
+	Password  string         `json:"-"`
+	Role      string         `gorm:"default:admin" json:"role"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Admin) TableName() string {
+	return "admins"
+}
@@ -1,24 +1,28 @@
-package user
-
-import (
-	"time"
-
-	"gorm.io/gorm"
-
-)
-
-type User struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Username  string         `gorm:"unique;not null" json:"username"`
-	Email     string         `gorm:"unique;not null" json:"email"`
-	Password  string         `json:"-"`
-	ProfilePicture string    `json:"profile_picture"`
-	Role      string         `gorm:"default:user" json:"role"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
-}
-
-func (User) TableName() string {
-	return "users"
-}
\ No newline at end of file
+package user
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	mediaModel "mqfm-backend/internal/models/media"
+
+)
+
+type User struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Username  string         `gorm:"unique;not null" json:"username"`
+	Email     string         `gorm:"unique;not null" json:"email"`
+	Password  string         `json:"-"`
+	ProfilePicture string    `json:"profile_picture"`
+	ProfilePictureHash string `gorm:"index" json:"profile_picture_hash"`
+	ProfilePictureVariants mediaModel.ImageVariants `gorm:"type:text" json:"profile_picture_variants"`
+	Role      string         `gorm:"default:user" json:"role"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (User) TableName() string {
+	return "users"
+}
@@ -0,0 +1,113 @@
+package catalog
+
+import "time"
+
+// FormatVersion is the version of the export format written by this build.
+// Imports accept archives up to this version.
+const FormatVersion = 1
+
+// ManifestName is the file in an export archive holding the Manifest. Media
+// files sit next to it under media/.
+const ManifestName = "catalog.json"
+
+// Manifest describes an exported catalogue. Records refer to each other by
+// external ID, which stays the same in every environment.
+type Manifest struct {
+	Version    int        `json:"version"`
+	ExportedAt time.Time  `json:"exported_at"`
+	Categories []Category `json:"categories"`
+	Shows      []Show     `json:"shows"`
+	Audios     []Audio    `json:"audios"`
+}
+
+// Media is a file in the archive and the hash of its content. File is empty
+// when the export left media out.
+type Media struct {
+	File string `json:"file,omitempty"`
+	Hash string `json:"hash"`
+}
+
+// Categories are listed parents first.
+type Category struct {
+	ExternalID  string `json:"external_id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	Parent      string `json:"parent,omitempty"`
+	Position    int    `json:"position"`
+	Icon        *Media `json:"icon,omitempty"`
+	Cover       *Media `json:"cover,omitempty"`
+}
+
+type Show struct {
+	ExternalID  string `json:"external_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Hosts       string `json:"hosts"`
+	Category    string `json:"category,omitempty"`
+	Artwork     *Media `json:"artwork,omitempty"`
+}
+
+type Audio struct {
+	ExternalID    string     `json:"external_id"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	Category      string     `json:"category,omitempty"`
+	Show          string     `json:"show,omitempty"`
+	SeasonNumber  int        `json:"season_number"`
+	EpisodeNumber int        `json:"episode_number"`
+	Status        string     `json:"status"`
+	PublishAt     *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt   *time.Time `json:"unpublish_at,omitempty"`
+	PublishedAt   *time.Time `json:"published_at,omitempty"`
+	Duration      int        `json:"duration"`
+	Tags          []string   `json:"tags"`
+	Audio         *Media     `json:"audio,omitempty"`
+	Thumbnail     *Media     `json:"thumbnail,omitempty"`
+}
+
+// Import actions reported for each record.
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+)
+
+// ItemPlan is what an import does, or would do, with one record. Changes
+// lists the fields an update sets, as exported values.
+type ItemPlan struct {
+	ExternalID string                 `json:"external_id"`
+	Label      string                 `json:"label"`
+	Action     string                 `json:"action"`
+	Changes    map[string]FieldChange `json:"changes,omitempty"`
+}
+
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// ImportReport is the diff between an archive and this catalogue. Nothing is
+// written while Errors is not empty.
+type ImportReport struct {
+	DryRun     bool       `json:"dry_run"`
+	Version    int        `json:"version"`
+	Categories []ItemPlan `json:"categories"`
+	Shows      []ItemPlan `json:"shows"`
+	Audios     []ItemPlan `json:"audios"`
+	Errors     []string   `json:"errors"`
+}
+
+// ImportResult is stored on the import job once it finishes.
+type ImportResult struct {
+	Created   int         `json:"created"`
+	Updated   int         `json:"updated"`
+	Unchanged int         `json:"unchanged"`
+	Failed    []ItemError `json:"failed"`
+}
+
+type ItemError struct {
+	Type       string `json:"type"`
+	ExternalID string `json:"external_id"`
+	Error      string `json:"error"`
+}
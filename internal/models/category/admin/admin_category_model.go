@@ -1,21 +1,55 @@
-package admin
-
-import (
-	"time"
-
-	"gorm.io/gorm"
-
-)
-
-type Category struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	Name        string         `gorm:"unique;not null" json:"name"`
-	Description string         `json:"description"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
-}
-
-func (Category) TableName() string {
-	return "categories"
-}
\ No newline at end of file
+package admin
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	mediaModel "mqfm-backend/internal/models/media"
+
+)
+
+// Category groups audios. Categories nest through ParentID and are listed by
+// Position, then name, within their parent.
+type Category struct {
+	ID            uint                     `gorm:"primaryKey" json:"id"`
+	ExternalID    string                   `gorm:"uniqueIndex" json:"external_id"`
+	Name          string                   `gorm:"unique;not null" json:"name"`
+	Slug          string                   `gorm:"index" json:"slug"`
+	Description   string                   `json:"description"`
+	ParentID      *uint                    `gorm:"index" json:"parent_id"`
+	Parent        *Category                `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
+	Position      int                      `gorm:"not null;default:0" json:"position"`
+	Icon          string                   `json:"icon"`
+	IconHash      string                   `gorm:"index" json:"icon_hash"`
+	IconVariants  mediaModel.ImageVariants `gorm:"type:text" json:"icon_variants"`
+	Cover         string                   `json:"cover"`
+	CoverHash     string                   `gorm:"index" json:"cover_hash"`
+	CoverVariants mediaModel.ImageVariants `gorm:"type:text" json:"cover_variants"`
+	CreatedAt     time.Time                `json:"created_at"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt           `gorm:"index" json:"-"`
+}
+
+// BeforeCreate gives the category the external ID that identifies it across
+// environments, unless it is imported with one.
+func (c *Category) BeforeCreate(tx *gorm.DB) error {
+	if c.ExternalID == "" {
+		c.ExternalID = uuid.New().String()
+	}
+	return nil
+}
+
+func (Category) TableName() string {
+	return "categories"
+}
+
+// CategoryNode is a category in the tree with its public audio counts.
+// TotalAudioCount includes every descendant.
+type CategoryNode struct {
+	Category
+	AudioCount      int64          `json:"audio_count"`
+	TotalAudioCount int64          `json:"total_audio_count"`
+	Children        []CategoryNode `json:"children"`
+}
@@ -0,0 +1,31 @@
+package job
+
+import "time"
+
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Job tracks a long-running background task so clients can poll its progress.
+type Job struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Type       string     `gorm:"not null;index" json:"type"`
+	Status     string     `gorm:"not null;index" json:"status"`
+	Payload    string     `gorm:"type:text" json:"payload"`
+	Progress   int        `json:"progress"`
+	Total      int        `json:"total"`
+	Result     string     `gorm:"type:text" json:"result"`
+	Error      string     `json:"error"`
+	CreatedBy  uint       `json:"created_by"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
@@ -0,0 +1,53 @@
+package media
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"mqfm-backend/internal/utils"
+)
+
+// ColorPalette holds hex colours extracted from artwork: the dominant colour
+// plus dark and light shades for backdrops and text. Stored as JSON text.
+type ColorPalette struct {
+	Dominant string `json:"dominant"`
+	Dark     string `json:"dark"`
+	Light    string `json:"light"`
+}
+
+// Ambient returns the dominant colour, or the hash-based colour of fallback
+// when there is no artwork to extract from.
+func (p *ColorPalette) Ambient(fallback string) string {
+	if p != nil && p.Dominant != "" {
+		return p.Dominant
+	}
+	return utils.GenerateAmbientColor(fallback)
+}
+
+func (p ColorPalette) Value() (driver.Value, error) {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (p *ColorPalette) Scan(value interface{}) error {
+	var raw []byte
+	switch val := value.(type) {
+	case nil:
+		return nil
+	case string:
+		raw = []byte(val)
+	case []byte:
+		raw = val
+	default:
+		return fmt.Errorf("cannot scan %T into ColorPalette", value)
+	}
+
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, p)
+}
@@ -0,0 +1,52 @@
+package media
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// ImageVariants maps a variant name (small, medium, large, original) to its
+// storage key. It is stored as a JSON text column and works in map updates.
+type ImageVariants map[string]string
+
+func (v ImageVariants) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return "", nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (v *ImageVariants) Scan(value interface{}) error {
+	var raw []byte
+	switch val := value.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case string:
+		raw = []byte(val)
+	case []byte:
+		raw = val
+	default:
+		return fmt.Errorf("cannot scan %T into ImageVariants", value)
+	}
+
+	if len(raw) == 0 {
+		*v = nil
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// Keys returns every storage key in the map.
+func (v ImageVariants) Keys() []string {
+	keys := make([]string, 0, len(v))
+	for _, key := range v {
+		keys = append(keys, key)
+	}
+	return keys
+}
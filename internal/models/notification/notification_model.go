@@ -0,0 +1,24 @@
+package notification
+
+import "time"
+
+const (
+	TypeReviewSubmitted = "review_submitted"
+	TypeReviewApproved  = "review_approved"
+	TypeReviewRejected  = "review_rejected"
+)
+
+// Notification is an in-app message for an admin account.
+type Notification struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	RecipientID uint       `gorm:"not null;index" json:"recipient_id"`
+	Type        string     `gorm:"not null" json:"type"`
+	Message     string     `json:"message"`
+	AudioID     *uint      `json:"audio_id"`
+	ReadAt      *time.Time `json:"read_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
@@ -5,19 +5,37 @@ import (
 
 	"gorm.io/gorm"
 
+	mediaModel "mqfm-backend/internal/models/media"
 	adminAudioModel "mqfm-backend/internal/models/podcast/audio/admin"
 
 )
 
 type Playlist struct {
-	ID        uint                     `gorm:"primaryKey" json:"id"`
-	UserID    uint                     `gorm:"not null;index" json:"user_id"`
-	Name      string                   `gorm:"not null" json:"name"`
-	ImageURL  string                   `json:"image_url"`
-	Audios    []*adminAudioModel.Audio `gorm:"many2many:playlist_audios;" json:"audios"`
-	CreatedAt time.Time                `json:"created_at"`
-	UpdatedAt time.Time                `json:"updated_at"`
-	DeletedAt gorm.DeletedAt           `gorm:"index" json:"-"`
+	ID            uint                     `gorm:"primaryKey" json:"id"`
+	UserID        uint                     `gorm:"not null;index" json:"user_id"`
+	Name          string                   `gorm:"not null" json:"name"`
+	ImageURL      string                   `json:"image_url"`
+	ImageHash     string                   `gorm:"index" json:"image_hash"`
+	ImageVariants mediaModel.ImageVariants `gorm:"type:text" json:"image_variants"`
+	Palette       *mediaModel.ColorPalette `gorm:"type:text" json:"palette"`
+	AmbientColor  string                   `gorm:"-" json:"ambient_color"`
+	Audios        []*adminAudioModel.Audio `gorm:"many2many:playlist_audios;" json:"audios"`
+	CreatedAt     time.Time                `json:"created_at"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt           `gorm:"index" json:"-"`
+}
+
+// AfterFind sets AmbientColor from the cover palette, or from the name when
+// there is no cover.
+func (p *Playlist) AfterFind(tx *gorm.DB) error {
+	p.AmbientColor = p.Palette.Ambient(p.Name)
+	return nil
+}
+
+// AfterSave keeps AmbientColor in step for records returned after a write.
+func (p *Playlist) AfterSave(tx *gorm.DB) error {
+	p.AmbientColor = p.Palette.Ambient(p.Name)
+	return nil
 }
 
 func (Playlist) TableName() string {
@@ -1,24 +1,78 @@
-package admin
-
-import (
-	"time"
-
-	"gorm.io/gorm"
-
-)
-
-type Audio struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	Title       string         `gorm:"not null" json:"title"`
-	Description string         `json:"description"`
-	AudioURL    string         `json:"audio_url"` 
-	Thumbnail   string         `json:"thumbnail"`
-	CategoryID  uint           `json:"category_id"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
-}
-
-func (Audio) TableName() string {
-	return "audios"
-}
\ No newline at end of file
+package admin
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	categoryModel "mqfm-backend/internal/models/category/admin"
+	mediaModel "mqfm-backend/internal/models/media"
+	speakerModel "mqfm-backend/internal/models/podcast/speaker"
+	tagModel "mqfm-backend/internal/models/tag"
+
+)
+
+type Audio struct {
+	ID                uint                     `gorm:"primaryKey" json:"id"`
+	ExternalID        string                   `gorm:"uniqueIndex" json:"external_id"`
+	Title             string                   `gorm:"not null" json:"title"`
+	Description       string                   `json:"description"`
+	AudioURL          string                   `json:"audio_url"`
+	Thumbnail         string                   `json:"thumbnail"`
+	ThumbnailHash     string                   `gorm:"index" json:"thumbnail_hash"`
+	ThumbnailVariants mediaModel.ImageVariants `gorm:"type:text" json:"thumbnail_variants"`
+	Palette           *mediaModel.ColorPalette `gorm:"type:text" json:"palette"`
+	AmbientColor      string                   `gorm:"-" json:"ambient_color"`
+	Duration          int                      `json:"duration"`
+	FileSize          int64                    `json:"file_size"`
+	AudioHash         string                   `gorm:"index" json:"audio_hash"`
+	CategoryID        *uint                    `gorm:"index" json:"category_id"`
+	Category          *categoryModel.Category  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"-"`
+	ShowID            *uint                    `gorm:"index" json:"show_id"`
+	SeasonNumber      int                      `json:"season_number"`
+	EpisodeNumber     int                      `json:"episode_number"`
+	GUID              *string                  `gorm:"uniqueIndex" json:"guid"`
+	TranscodeStatus   string                   `json:"transcode_status"`
+	WaveformStatus    string                   `gorm:"index" json:"waveform_status"`
+	Status            string                   `gorm:"index;not null;default:published" json:"status"`
+	PublishAt         *time.Time               `gorm:"index" json:"publish_at"`
+	UnpublishAt       *time.Time               `gorm:"index" json:"unpublish_at"`
+	PublishedAt       *time.Time               `json:"published_at"`
+	ReviewStatus      string                   `gorm:"index" json:"review_status"`
+	CreatedBy         uint                     `gorm:"index" json:"created_by"`
+	Renditions        []AudioRendition         `gorm:"foreignKey:AudioID" json:"renditions"`
+	Chapters          []AudioChapter           `gorm:"foreignKey:AudioID" json:"chapters"`
+	Speakers          []speakerModel.Speaker   `gorm:"many2many:audio_speakers;" json:"speakers"`
+	Tags              []tagModel.Tag           `gorm:"many2many:audio_tags;" json:"tags"`
+	TranscriptMatches []TranscriptMatch        `gorm:"-" json:"transcript_matches,omitempty"`
+	CreatedAt         time.Time                `json:"created_at"`
+	UpdatedAt         time.Time                `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt           `gorm:"index" json:"-"`
+}
+
+// BeforeCreate gives the audio the external ID that identifies it across
+// environments, unless it is imported with one.
+func (a *Audio) BeforeCreate(tx *gorm.DB) error {
+	if a.ExternalID == "" {
+		a.ExternalID = uuid.New().String()
+	}
+	return nil
+}
+
+// AfterFind sets AmbientColor from the artwork palette, or from the title when
+// there is no artwork.
+func (a *Audio) AfterFind(tx *gorm.DB) error {
+	a.AmbientColor = a.Palette.Ambient(a.Title)
+	return nil
+}
+
+// AfterSave keeps AmbientColor in step for records returned after a write.
+func (a *Audio) AfterSave(tx *gorm.DB) error {
+	a.AmbientColor = a.Palette.Ambient(a.Title)
+	return nil
+}
+
+func (Audio) TableName() string {
+	return "audios"
+}
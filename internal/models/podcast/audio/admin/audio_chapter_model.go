@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"time"
+
+	mediaModel "mqfm-backend/internal/models/media"
+)
+
+// PodcastChaptersVersion is the Podcasting 2.0 JSON chapters format served.
+const PodcastChaptersVersion = "1.2.0"
+
+// AudioChapter marks where a part of an Audio starts, so listeners can skip
+// through long episodes. Times are milliseconds from the start of the audio;
+// without an end a chapter runs until the next one.
+type AudioChapter struct {
+	ID            uint                     `gorm:"primaryKey" json:"id"`
+	AudioID       uint                     `gorm:"not null;index" json:"audio_id"`
+	StartMs       int                      `gorm:"not null" json:"start_ms"`
+	EndMs         *int                     `json:"end_ms"`
+	Title         string                   `gorm:"not null" json:"title"`
+	Image         string                   `json:"image"`
+	ImageHash     string                   `gorm:"index" json:"image_hash"`
+	ImageVariants mediaModel.ImageVariants `gorm:"type:text" json:"image_variants"`
+	URL           string                   `json:"url"`
+	CreatedAt     time.Time                `json:"created_at"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+}
+
+func (AudioChapter) TableName() string {
+	return "audio_chapters"
+}
+
+// PodcastChapters is an audio's chapters as a Podcasting 2.0 JSON chapters
+// document. Times are in seconds and links are absolute.
+type PodcastChapters struct {
+	Version  string           `json:"version"`
+	Chapters []PodcastChapter `json:"chapters"`
+}
+
+type PodcastChapter struct {
+	StartTime float64  `json:"startTime"`
+	EndTime   *float64 `json:"endTime,omitempty"`
+	Title     string   `json:"title"`
+	Img       string   `json:"img,omitempty"`
+	URL       string   `json:"url,omitempty"`
+}
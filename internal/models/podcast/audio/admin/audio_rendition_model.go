@@ -0,0 +1,27 @@
+package admin
+
+import "time"
+
+// Transcode states recorded on Audio.TranscodeStatus.
+const (
+	TranscodePending    = "pending"
+	TranscodeProcessing = "processing"
+	TranscodeDone       = "done"
+	TranscodeFailed     = "failed"
+)
+
+// AudioRendition is one transcoded copy of an Audio at a fixed bitrate.
+type AudioRendition struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	AudioID   uint      `gorm:"not null;uniqueIndex:idx_audio_quality" json:"audio_id"`
+	Quality   string    `gorm:"not null;uniqueIndex:idx_audio_quality" json:"quality"`
+	Bitrate   int       `json:"bitrate"`
+	URL       string    `json:"url"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (AudioRendition) TableName() string {
+	return "audio_renditions"
+}
@@ -0,0 +1,26 @@
+package admin
+
+import "time"
+
+// Review states recorded on Audio.ReviewStatus. Audios created by admins and
+// reviewers skip review and keep an empty state.
+const (
+	ReviewSubmitted = "submitted"
+	ReviewApproved  = "approved"
+	ReviewRejected  = "rejected"
+)
+
+// AudioReview is one step of an audio's review history.
+type AudioReview struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	AudioID    uint      `gorm:"not null;index" json:"audio_id"`
+	ActorID    uint      `gorm:"not null" json:"actor_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `gorm:"not null" json:"to_status"`
+	Comment    string    `gorm:"type:text" json:"comment"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (AudioReview) TableName() string {
+	return "audio_reviews"
+}
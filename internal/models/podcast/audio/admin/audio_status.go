@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Publishing states. Drafts and archived audios are only visible to admins,
+// scheduled ones until their publish_at passes, and unlisted ones are reachable
+// by ID but left out of lists, search and feeds.
+const (
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+	StatusUnlisted  = "unlisted"
+	StatusArchived  = "archived"
+)
+
+var Statuses = []string{StatusDraft, StatusScheduled, StatusPublished, StatusUnlisted, StatusArchived}
+
+// Listed limits a query to audios the public may browse. Due scheduled audios
+// count as published so visibility doesn't wait for the scheduler tick.
+func Listed(db *gorm.DB) *gorm.DB {
+	return visible(db, StatusPublished)
+}
+
+// Reachable is Listed plus unlisted audios, for lookups by ID.
+func Reachable(db *gorm.DB) *gorm.DB {
+	return visible(db, StatusPublished, StatusUnlisted)
+}
+
+func visible(db *gorm.DB, statuses ...string) *gorm.DB {
+	now := time.Now()
+	return db.
+		Where("(audios.status IN ? OR (audios.status = ? AND audios.publish_at <= ?))", statuses, StatusScheduled, now).
+		Where("(audios.unpublish_at IS NULL OR audios.unpublish_at > ?)", now)
+}
@@ -0,0 +1,53 @@
+package admin
+
+import "time"
+
+// Transcript formats accepted on upload.
+const (
+	TranscriptVTT = "vtt"
+	TranscriptSRT = "srt"
+)
+
+// AudioTranscript is the transcript, or subtitles, of an Audio in one
+// language. It is stored as cues so it can be searched and served in any
+// format.
+type AudioTranscript struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	AudioID   uint            `gorm:"not null;uniqueIndex:idx_transcript_language" json:"audio_id"`
+	Language  string          `gorm:"not null;uniqueIndex:idx_transcript_language" json:"language"`
+	Format    string          `json:"format"`
+	CueCount  int             `json:"cue_count"`
+	CreatedBy uint            `json:"created_by"`
+	Cues      []TranscriptCue `gorm:"foreignKey:TranscriptID" json:"cues,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func (AudioTranscript) TableName() string {
+	return "audio_transcripts"
+}
+
+// TranscriptCue is one timed line of a transcript. Times are milliseconds
+// from the start of the audio.
+type TranscriptCue struct {
+	ID           uint   `gorm:"primaryKey" json:"-"`
+	TranscriptID uint   `gorm:"not null;index" json:"-"`
+	AudioID      uint   `gorm:"not null;index" json:"-"`
+	Position     int    `gorm:"not null" json:"position"`
+	StartMs      int    `gorm:"not null" json:"start_ms"`
+	EndMs        int    `gorm:"not null" json:"end_ms"`
+	Text         string `gorm:"type:text;not null" json:"text"`
+}
+
+func (TranscriptCue) TableName() string {
+	return "transcript_cues"
+}
+
+// TranscriptMatch is a cue that matched a search, so players can jump to
+// where the words are said.
+type TranscriptMatch struct {
+	Language string `json:"language"`
+	StartMs  int    `json:"start_ms"`
+	EndMs    int    `json:"end_ms"`
+	Text     string `json:"text"`
+}
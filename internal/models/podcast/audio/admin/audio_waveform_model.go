@@ -0,0 +1,33 @@
+package admin
+
+import "time"
+
+// Waveform states recorded on Audio.WaveformStatus. Unsupported audios have
+// no MP3 to decode yet, neither as source nor as rendition.
+const (
+	WaveformPending     = "pending"
+	WaveformProcessing  = "processing"
+	WaveformDone        = "done"
+	WaveformFailed      = "failed"
+	WaveformUnsupported = "unsupported"
+)
+
+// AudioWaveform is the peak data of an Audio at one resolution, laid out as
+// in audiowaveform: a signed 8-bit min/max pair for every SamplesPerPixel
+// samples of the mono mix.
+type AudioWaveform struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	AudioID    uint   `gorm:"not null;uniqueIndex:idx_waveform_resolution" json:"audio_id"`
+	Resolution string `gorm:"not null;uniqueIndex:idx_waveform_resolution" json:"resolution"`
+	// Source is the audio file the peaks were computed for.
+	Source          string    `gorm:"not null" json:"-"`
+	SampleRate      int       `json:"sample_rate"`
+	SamplesPerPixel int       `json:"samples_per_pixel"`
+	Length          int       `json:"length"`
+	Data            []byte    `json:"-"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (AudioWaveform) TableName() string {
+	return "audio_waveforms"
+}
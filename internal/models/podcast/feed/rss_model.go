@@ -0,0 +1,104 @@
+package feed
+
+import "encoding/xml"
+
+// RSS is an RSS 2.0 document carrying the iTunes and Podcasting 2.0 namespaces.
+type RSS struct {
+	XMLName   xml.Name `xml:"rss"`
+	Version   string   `xml:"version,attr"`
+	ItunesNS  string   `xml:"xmlns:itunes,attr"`
+	PodcastNS string   `xml:"xmlns:podcast,attr"`
+	AtomNS    string   `xml:"xmlns:atom,attr"`
+	ContentNS string   `xml:"xmlns:content,attr"`
+	Channel   Channel  `xml:"channel"`
+}
+
+type Channel struct {
+	Title          string         `xml:"title"`
+	Link           string         `xml:"link"`
+	Description    string         `xml:"description"`
+	Language       string         `xml:"language"`
+	Copyright      string         `xml:"copyright,omitempty"`
+	LastBuildDate  string         `xml:"lastBuildDate,omitempty"`
+	AtomLink       AtomLink       `xml:"atom:link"`
+	ItunesAuthor   string         `xml:"itunes:author"`
+	ItunesSummary  string         `xml:"itunes:summary,omitempty"`
+	ItunesType     string         `xml:"itunes:type"`
+	ItunesExplicit string         `xml:"itunes:explicit"`
+	ItunesImage    *ItunesImage   `xml:"itunes:image,omitempty"`
+	ItunesOwner    ItunesOwner    `xml:"itunes:owner"`
+	ItunesCategory ItunesCategory `xml:"itunes:category"`
+	Image          *Image         `xml:"image,omitempty"`
+	PodcastGUID    string         `xml:"podcast:guid"`
+	PodcastLocked  PodcastLocked  `xml:"podcast:locked"`
+	Items          []Item         `xml:"item"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type ItunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type ItunesOwner struct {
+	Name  string `xml:"itunes:name"`
+	Email string `xml:"itunes:email"`
+}
+
+type ItunesCategory struct {
+	Text        string          `xml:"text,attr"`
+	Subcategory *ItunesCategory `xml:"itunes:category,omitempty"`
+}
+
+type Image struct {
+	URL   string `xml:"url"`
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
+}
+
+type PodcastLocked struct {
+	Owner string `xml:"owner,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type Item struct {
+	Title          string       `xml:"title"`
+	Description    CDATA        `xml:"description"`
+	Link           string       `xml:"link,omitempty"`
+	GUID           GUID         `xml:"guid"`
+	PubDate        string       `xml:"pubDate"`
+	Enclosure      Enclosure    `xml:"enclosure"`
+	ItunesTitle    string       `xml:"itunes:title"`
+	ItunesDuration int          `xml:"itunes:duration,omitempty"`
+	ItunesExplicit string       `xml:"itunes:explicit"`
+	ItunesType     string       `xml:"itunes:episodeType"`
+	ItunesSeason   int          `xml:"itunes:season,omitempty"`
+	ItunesEpisode  int          `xml:"itunes:episode,omitempty"`
+	ItunesImage    *ItunesImage `xml:"itunes:image,omitempty"`
+	Chapters       *Chapters    `xml:"podcast:chapters,omitempty"`
+}
+
+// Chapters links a Podcasting 2.0 JSON chapters file.
+type Chapters struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type CDATA struct {
+	Text string `xml:",cdata"`
+}
+
+type GUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type Enclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
@@ -0,0 +1,53 @@
+package feed
+
+// SourceRSS is the subset of a third-party podcast feed needed to import episodes.
+type SourceRSS struct {
+	Channel SourceChannel `xml:"channel"`
+}
+
+type SourceChannel struct {
+	Title  string        `xml:"title"`
+	Images []SourceImage `xml:"image"`
+	Items  []SourceItem  `xml:"item"`
+}
+
+// SourceImage matches both <image><url> and <itunes:image href>, which share a local name.
+type SourceImage struct {
+	URL  string `xml:"url"`
+	Href string `xml:"href,attr"`
+}
+
+// Artwork prefers the iTunes image over the plain RSS one.
+func (c SourceChannel) Artwork() string {
+	var fallback string
+	for _, img := range c.Images {
+		if img.Href != "" {
+			return img.Href
+		}
+		if fallback == "" {
+			fallback = img.URL
+		}
+	}
+	return fallback
+}
+
+type SourceItunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type SourceItem struct {
+	Title          string            `xml:"title"`
+	Description    string            `xml:"description"`
+	ItunesSummary  string            `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd summary"`
+	GUID           string            `xml:"guid"`
+	PubDate        string            `xml:"pubDate"`
+	Enclosure      SourceEnclosure   `xml:"enclosure"`
+	ItunesDuration string            `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ItunesImage    SourceItunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+}
+
+type SourceEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
@@ -0,0 +1,57 @@
+package show
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	categoryModel "mqfm-backend/internal/models/category/admin"
+	mediaModel "mqfm-backend/internal/models/media"
+)
+
+// Show is a series of episodes, such as a weekly tafsir programme. Episodes are
+// audios pointing at it with a season and episode number.
+type Show struct {
+	ID              uint                     `gorm:"primaryKey" json:"id"`
+	ExternalID      string                   `gorm:"uniqueIndex" json:"external_id"`
+	Title           string                   `gorm:"not null" json:"title"`
+	Description     string                   `json:"description"`
+	Hosts           string                   `json:"hosts"`
+	Artwork         string                   `json:"artwork"`
+	ArtworkHash     string                   `gorm:"index" json:"artwork_hash"`
+	ArtworkVariants mediaModel.ImageVariants `gorm:"type:text" json:"artwork_variants"`
+	Palette         *mediaModel.ColorPalette `gorm:"type:text" json:"palette"`
+	AmbientColor    string                   `gorm:"-" json:"ambient_color"`
+	CategoryID      *uint                    `gorm:"index" json:"category_id"`
+	Category        *categoryModel.Category  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"-"`
+	CreatedAt       time.Time                `json:"created_at"`
+	UpdatedAt       time.Time                `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt           `gorm:"index" json:"-"`
+}
+
+// BeforeCreate gives the show the external ID that identifies it across
+// environments, unless it is imported with one.
+func (s *Show) BeforeCreate(tx *gorm.DB) error {
+	if s.ExternalID == "" {
+		s.ExternalID = uuid.New().String()
+	}
+	return nil
+}
+
+// AfterFind sets AmbientColor from the artwork palette, or from the title when
+// there is no artwork.
+func (s *Show) AfterFind(tx *gorm.DB) error {
+	s.AmbientColor = s.Palette.Ambient(s.Title)
+	return nil
+}
+
+// AfterSave keeps AmbientColor in step for records returned after a write.
+func (s *Show) AfterSave(tx *gorm.DB) error {
+	s.AmbientColor = s.Palette.Ambient(s.Title)
+	return nil
+}
+
+func (Show) TableName() string {
+	return "shows"
+}
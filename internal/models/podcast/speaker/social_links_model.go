@@ -0,0 +1,43 @@
+package speaker
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// SocialLinks maps a network name (youtube, instagram, website...) to a URL.
+// It is stored as a JSON text column.
+type SocialLinks map[string]string
+
+func (l SocialLinks) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return "", nil
+	}
+	raw, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (l *SocialLinks) Scan(value interface{}) error {
+	var raw []byte
+	switch val := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		raw = []byte(val)
+	case []byte:
+		raw = val
+	default:
+		return fmt.Errorf("cannot scan %T into SocialLinks", value)
+	}
+
+	if len(raw) == 0 {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(raw, l)
+}
@@ -0,0 +1,27 @@
+package speaker
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	mediaModel "mqfm-backend/internal/models/media"
+)
+
+// Speaker is an ustadz or guest heard on audios.
+type Speaker struct {
+	ID            uint                     `gorm:"primaryKey" json:"id"`
+	Name          string                   `gorm:"not null;index" json:"name"`
+	Bio           string                   `gorm:"type:text" json:"bio"`
+	Photo         string                   `json:"photo"`
+	PhotoHash     string                   `gorm:"index" json:"photo_hash"`
+	PhotoVariants mediaModel.ImageVariants `gorm:"type:text" json:"photo_variants"`
+	SocialLinks   SocialLinks              `gorm:"type:text" json:"social_links"`
+	CreatedAt     time.Time                `json:"created_at"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt           `gorm:"index" json:"-"`
+}
+
+func (Speaker) TableName() string {
+	return "speakers"
+}
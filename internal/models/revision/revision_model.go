@@ -0,0 +1,123 @@
+package revision
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Entity types revisions are recorded for.
+const (
+	EntityAudio    = "audio"
+	EntityCategory = "category"
+)
+
+// Revision actions. The initial revision captures a record as it was before
+// its first recorded edit, so there is always a version to revert to.
+const (
+	ActionInitial = "initial"
+	ActionUpdate  = "update"
+	ActionRevert  = "revert"
+)
+
+// Revision is one version of an audio or category. Number counts up from 1
+// per record; Snapshot holds every tracked field as it stood after the edit.
+type Revision struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	EntityType string    `gorm:"not null;uniqueIndex:idx_revision_number" json:"entity_type"`
+	EntityID   uint      `gorm:"not null;uniqueIndex:idx_revision_number" json:"entity_id"`
+	Number     int       `gorm:"not null;uniqueIndex:idx_revision_number" json:"number"`
+	Action     string    `gorm:"not null" json:"action"`
+	ActorID    *uint     `json:"actor_id"`
+	RevertedTo *int      `json:"reverted_to,omitempty"`
+	Changes    Changes   `gorm:"type:text" json:"changes"`
+	Snapshot   Fields    `gorm:"type:text" json:"snapshot"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (Revision) TableName() string {
+	return "revisions"
+}
+
+// Change is the value of one field before and after an edit.
+type Change struct {
+	From json.RawMessage `json:"from"`
+	To   json.RawMessage `json:"to"`
+}
+
+// Changes maps a field name to how it changed. It is stored as a JSON text
+// column.
+type Changes map[string]Change
+
+func (c Changes) Value() (driver.Value, error) {
+	return jsonValue(c)
+}
+
+func (c *Changes) Scan(value interface{}) error {
+	return jsonScan(value, c)
+}
+
+// Fields maps a field name to its JSON encoded value. It is stored as a JSON
+// text column.
+type Fields map[string]json.RawMessage
+
+func (f Fields) Value() (driver.Value, error) {
+	return jsonValue(f)
+}
+
+func (f *Fields) Scan(value interface{}) error {
+	return jsonScan(value, f)
+}
+
+// Decode sets the fields of dst, a model, from their stored values.
+func (f Fields) Decode(dst interface{}) error {
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+// Diff lists the fields whose values differ between f and other.
+func (f Fields) Diff(other Fields) Changes {
+	changes := Changes{}
+	for name, from := range f {
+		if to := other[name]; string(from) != string(to) {
+			changes[name] = Change{From: from, To: to}
+		}
+	}
+	for name, to := range other {
+		if _, ok := f[name]; !ok {
+			changes[name] = Change{To: to}
+		}
+	}
+	return changes
+}
+
+func jsonValue(v interface{}) (driver.Value, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func jsonScan(value interface{}, dst interface{}) error {
+	var raw []byte
+	switch val := value.(type) {
+	case nil:
+		return nil
+	case string:
+		raw = []byte(val)
+	case []byte:
+		raw = val
+	default:
+		return fmt.Errorf("cannot scan %T into %T", value, dst)
+	}
+
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, dst)
+}
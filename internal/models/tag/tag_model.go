@@ -0,0 +1,25 @@
+package tag
+
+import (
+	"time"
+)
+
+// Tag is a free-form label on audios; an audio can carry any number of them.
+// Tags are deleted outright so a removed name can be used again.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"uniqueIndex;not null" json:"name"`
+	Slug      string    `gorm:"uniqueIndex;not null" json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// TagCount is a tag with the number of public audios carrying it.
+type TagCount struct {
+	Tag
+	Count int64 `json:"count"`
+}
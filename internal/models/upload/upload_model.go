@@ -0,0 +1,41 @@
+package upload
+
+import "time"
+
+// Upload is a resumable (tus) upload kept until it is referenced by an Audio.
+// Its bytes are stored as UploadChunks, so any instance can continue it.
+type Upload struct {
+	ID          string     `gorm:"primaryKey;size:36" json:"id"`
+	AdminID     uint       `gorm:"not null;index" json:"admin_id"`
+	Length      int64      `gorm:"not null" json:"length"`
+	Offset      int64      `gorm:"not null;default:0" json:"offset"`
+	Metadata    string     `json:"-"`
+	Filename    string     `json:"filename"`
+	ExpiresAt   time.Time  `gorm:"index" json:"expires_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (Upload) TableName() string {
+	return "uploads"
+}
+
+func (u Upload) IsComplete() bool {
+	return u.Offset >= u.Length
+}
+
+// UploadChunk is the part of an Upload received by one PATCH request, stored
+// under Key starting at Offset.
+type UploadChunk struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UploadID  string    `gorm:"size:36;not null;index" json:"upload_id"`
+	Offset    int64     `gorm:"not null" json:"offset"`
+	Size      int64     `gorm:"not null" json:"size"`
+	Key       string    `gorm:"not null" json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (UploadChunk) TableName() string {
+	return "upload_chunks"
+}
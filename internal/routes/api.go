@@ -1,106 +1,297 @@
-package routes
-
-import (
-	"github.com/gin-gonic/gin"
-
-	adminController "mqfm-backend/internal/controllers/auth/admin"
-	userController "mqfm-backend/internal/controllers/auth/user"
-	categoryAdminController "mqfm-backend/internal/controllers/category/admin"
-	likeUserController "mqfm-backend/internal/controllers/likes/user"
-	lsController "mqfm-backend/internal/controllers/livestream"
-	playlistUserController "mqfm-backend/internal/controllers/playlist/user"
-	audioAdminController "mqfm-backend/internal/controllers/podcast/audio/admin"
-	"mqfm-backend/internal/middleware"
-
-)
-
-func SetupRoutes(
-	r *gin.Engine,
-	aController *adminController.AdminAuthController,
-	uController *userController.UserAuthController,
-	catAdminController *categoryAdminController.AdminCategoryController,
-	audioAdminController *audioAdminController.AdminAudioController,
-	playlistController *playlistUserController.UserPlaylistController,
-	likeController *likeUserController.UserLikeController,
-	lsController *lsController.LiveStreamController,
-) {
-	api := r.Group("/api")
-	{
-		categories := api.Group("/categories")
-		{
-			categories.GET("/", catAdminController.FindAll)
-			categories.GET("/search", catAdminController.Search)
-			categories.GET("/:id", catAdminController.FindByID)
-		}
-
-		audios := api.Group("/audios")
-		{
-			audios.GET("/", audioAdminController.FindAll)
-			audios.GET("/search", audioAdminController.Search)
-			audios.GET("/:id", audioAdminController.FindByID)
-		}
-
-		youtube := api.Group("/youtube")
-		{
-			youtube.GET("/live-status", lsController.GetStatus)
-		}
-
-		adminAuth := api.Group("/admin")
-		{
-			adminAuth.POST("/auth/register", aController.Register)
-			adminAuth.POST("/auth/login", aController.Login)
-
-			protectedAdmin := adminAuth.Group("/")
-			protectedAdmin.Use(middleware.JWTMiddleware())
-			{
-				protectedAdmin.GET("/auth/me", aController.Me)
-				protectedAdmin.PUT("/auth/update/:id", aController.Update)
-				protectedAdmin.POST("/auth/logout", aController.Logout)
-
-				adminCategories := protectedAdmin.Group("/categories")
-				{
-					adminCategories.POST("/", catAdminController.Create)
-					adminCategories.PUT("/:id", catAdminController.Update)
-					adminCategories.DELETE("/:id", catAdminController.Delete)
-				}
-
-				adminAudios := protectedAdmin.Group("/audios")
-				{
-					adminAudios.POST("/", audioAdminController.Create)
-					adminAudios.PUT("/:id", audioAdminController.Update)
-					adminAudios.DELETE("/:id", audioAdminController.Delete)
-				}
-			}
-		}
-
-		userAuth := api.Group("/user")
-		{
-			userAuth.POST("/auth/register", uController.Register)
-			userAuth.POST("/auth/login", uController.Login)
-
-			protectedUser := userAuth.Group("/")
-			protectedUser.Use(middleware.JWTMiddleware())
-			{
-				protectedUser.GET("/auth/me", uController.Me)
-				protectedUser.PUT("/auth/update/:id", uController.Update)
-				protectedUser.POST("/auth/logout", uController.Logout)
-
-				playlists := protectedUser.Group("/playlists")
-				{
-					playlists.GET("/", playlistController.GetMyPlaylists)
-					playlists.GET("/search", playlistController.Search)
-					playlists.GET("/:id", playlistController.GetDetail)
-					playlists.POST("/", playlistController.Create)
-					playlists.POST("/add-audio", playlistController.AddAudio)
-				}
-
-				likes := protectedUser.Group("/likes")
-				{
-					likes.POST("/", likeController.Like)
-					likes.DELETE("/:audio_id", likeController.Unlike)
-					likes.GET("/", likeController.GetLikes)
-				}
-			}
-		}
-	}
-}
\ No newline at end of file
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	adminController "mqfm-backend/internal/controllers/auth/admin"
+	userController "mqfm-backend/internal/controllers/auth/user"
+	avatarController "mqfm-backend/internal/controllers/avatar"
+	catalogController "mqfm-backend/internal/controllers/catalog"
+	categoryAdminController "mqfm-backend/internal/controllers/category/admin"
+	duplicateController "mqfm-backend/internal/controllers/duplicate"
+	jobController "mqfm-backend/internal/controllers/job"
+	likeUserController "mqfm-backend/internal/controllers/likes/user"
+	lsController "mqfm-backend/internal/controllers/livestream"
+	mediaController "mqfm-backend/internal/controllers/media"
+	notificationController "mqfm-backend/internal/controllers/notification"
+	playlistUserController "mqfm-backend/internal/controllers/playlist/user"
+	audioAdminController "mqfm-backend/internal/controllers/podcast/audio/admin"
+	feedController "mqfm-backend/internal/controllers/podcast/feed"
+	importController "mqfm-backend/internal/controllers/podcast/importer"
+	showController "mqfm-backend/internal/controllers/podcast/show"
+	speakerController "mqfm-backend/internal/controllers/podcast/speaker"
+	tagController "mqfm-backend/internal/controllers/tag"
+	trashController "mqfm-backend/internal/controllers/trash"
+	uploadController "mqfm-backend/internal/controllers/upload"
+	"mqfm-backend/internal/middleware"
+	adminModel "mqfm-backend/internal/models/auth/admin"
+
+)
+
+func SetupRoutes(
+	r *gin.Engine,
+	aController *adminController.AdminAuthController,
+	uController *userController.UserAuthController,
+	catAdminController *categoryAdminController.AdminCategoryController,
+	audioAdminController *audioAdminController.AdminAudioController,
+	reviewController *audioAdminController.AudioReviewController,
+	transcriptController *audioAdminController.AudioTranscriptController,
+	chapterController *audioAdminController.AudioChapterController,
+	waveformController *audioAdminController.AudioWaveformController,
+	showController *showController.ShowController,
+	speakerController *speakerController.SpeakerController,
+	tagController *tagController.TagController,
+	feedController *feedController.FeedController,
+	importController *importController.RSSImportController,
+	bulkImportController *importController.BulkImportController,
+	jobController *jobController.JobController,
+	duplicateController *duplicateController.DuplicateController,
+	mediaController *mediaController.MediaController,
+	avatarController *avatarController.AvatarController,
+	notificationController *notificationController.NotificationController,
+	tusController *uploadController.TusController,
+	playlistController *playlistUserController.UserPlaylistController,
+	likeController *likeUserController.UserLikeController,
+	lsController *lsController.LiveStreamController,
+	trashController *trashController.TrashController,
+	catalogController *catalogController.CatalogController,
+) {
+	api := r.Group("/api")
+	{
+		categories := api.Group("/categories")
+		{
+			categories.GET("/", catAdminController.FindAll)
+			categories.GET("/search", catAdminController.Search)
+			categories.GET("/tree", catAdminController.Tree)
+			categories.GET("/by-slug/:slug", catAdminController.FindBySlug)
+			categories.GET("/:id", catAdminController.FindByID)
+		}
+
+		audios := api.Group("/audios")
+		{
+			audios.GET("/", audioAdminController.FindAll)
+			audios.GET("/search", audioAdminController.Search)
+			audios.GET("/:id", audioAdminController.FindByID)
+			audios.GET("/:id/next", showController.Next)
+			audios.GET("/:id/previous", showController.Previous)
+			audios.GET("/:id/transcripts", transcriptController.FindAll)
+			audios.GET("/:id/transcripts/:language", transcriptController.Find)
+			audios.GET("/:id/chapters.json", chapterController.PodcastJSON)
+			audios.GET("/:id/waveform", waveformController.Find)
+		}
+
+		shows := api.Group("/shows")
+		{
+			shows.GET("/", showController.FindAll)
+			shows.GET("/:id", showController.FindByID)
+			shows.GET("/:id/episodes", showController.Episodes)
+		}
+
+		speakers := api.Group("/speakers")
+		{
+			speakers.GET("/", speakerController.FindAll)
+			speakers.GET("/search", speakerController.Search)
+			speakers.GET("/:id", speakerController.FindByID)
+			speakers.GET("/:id/episodes", speakerController.Episodes)
+		}
+
+		tags := api.Group("/tags")
+		{
+			tags.GET("/", tagController.FindAll)
+			tags.GET("/cloud", tagController.Cloud)
+		}
+
+		feeds := api.Group("/feeds")
+		{
+			feeds.GET("/", feedController.Global)
+			feeds.GET("/categories/:id", feedController.Category)
+		}
+
+		youtube := api.Group("/youtube")
+		{
+			youtube.GET("/live-status", lsController.GetStatus)
+		}
+
+		users := api.Group("/users")
+		{
+			users.GET("/:id/avatar", avatarController.Show)
+		}
+
+		adminAuth := api.Group("/admin")
+		{
+			adminAuth.POST("/auth/login", aController.Login)
+
+			protectedAdmin := adminAuth.Group("/")
+			protectedAdmin.Use(middleware.JWTMiddleware(), middleware.RequireRole(adminModel.Roles...))
+			{
+				adminOnly := middleware.RequireRole(adminModel.RoleAdmin)
+				reviewerOnly := middleware.RequireRole(adminModel.RoleAdmin, adminModel.RoleReviewer)
+
+				protectedAdmin.POST("/auth/register", adminOnly, aController.Register)
+				protectedAdmin.GET("/auth/me", aController.Me)
+				protectedAdmin.PUT("/auth/update/:id", aController.Update)
+				protectedAdmin.PUT("/auth/role/:id", adminOnly, aController.SetRole)
+				protectedAdmin.POST("/auth/logout", aController.Logout)
+
+				adminCategories := protectedAdmin.Group("/categories")
+				adminCategories.Use(adminOnly)
+				{
+					adminCategories.POST("/", catAdminController.Create)
+					adminCategories.PUT("/:id", catAdminController.Update)
+					adminCategories.DELETE("/:id", catAdminController.Delete)
+					adminCategories.POST("/reorder", catAdminController.Reorder)
+					adminCategories.POST("/:id/move-audios", catAdminController.MoveAudios)
+					adminCategories.GET("/:id/revisions", catAdminController.Revisions)
+					adminCategories.GET("/:id/revisions/diff", catAdminController.DiffRevisions)
+					adminCategories.POST("/:id/revisions/:number/revert", catAdminController.Revert)
+				}
+
+				adminAudios := protectedAdmin.Group("/audios")
+				{
+					adminAudios.GET("/", audioAdminController.FindAllAdmin)
+					adminAudios.GET("/:id", audioAdminController.Preview)
+					adminAudios.POST("/", audioAdminController.Create)
+					adminAudios.PUT("/:id", audioAdminController.Update)
+					adminAudios.DELETE("/:id", audioAdminController.Delete)
+					adminAudios.GET("/:id/revisions", audioAdminController.Revisions)
+					adminAudios.GET("/:id/revisions/diff", audioAdminController.DiffRevisions)
+					adminAudios.POST("/:id/revisions/:number/revert", audioAdminController.Revert)
+
+					adminAudios.GET("/reviews/pending", reviewerOnly, reviewController.Pending)
+					adminAudios.GET("/:id/reviews", reviewController.History)
+					adminAudios.POST("/:id/submit", reviewController.Submit)
+					adminAudios.POST("/:id/approve", reviewerOnly, reviewController.Approve)
+					adminAudios.POST("/:id/reject", reviewerOnly, reviewController.Reject)
+
+					adminAudios.GET("/:id/transcripts", transcriptController.PreviewAll)
+					adminAudios.GET("/:id/transcripts/:language", transcriptController.Preview)
+					adminAudios.PUT("/:id/transcripts/:language", transcriptController.Upload)
+					adminAudios.DELETE("/:id/transcripts/:language", transcriptController.Delete)
+					adminAudios.GET("/:id/chapters", chapterController.FindAll)
+					adminAudios.POST("/:id/chapters", chapterController.Create)
+					adminAudios.PUT("/:id/chapters/:chapterID", chapterController.Update)
+					adminAudios.DELETE("/:id/chapters/:chapterID", chapterController.Delete)
+					adminAudios.POST("/:id/waveform", waveformController.Regenerate)
+				}
+
+				adminShows := protectedAdmin.Group("/shows")
+				adminShows.Use(adminOnly)
+				{
+					adminShows.POST("/", showController.Create)
+					adminShows.PUT("/:id", showController.Update)
+					adminShows.DELETE("/:id", showController.Delete)
+				}
+
+				adminSpeakers := protectedAdmin.Group("/speakers")
+				adminSpeakers.Use(adminOnly)
+				{
+					adminSpeakers.POST("/", speakerController.Create)
+					adminSpeakers.PUT("/:id", speakerController.Update)
+					adminSpeakers.DELETE("/:id", speakerController.Delete)
+				}
+
+				adminTags := protectedAdmin.Group("/tags")
+				adminTags.Use(adminOnly)
+				{
+					adminTags.POST("/", tagController.Create)
+					adminTags.PUT("/:id", tagController.Update)
+					adminTags.DELETE("/:id", tagController.Delete)
+					adminTags.POST("/:id/merge", tagController.Merge)
+				}
+
+				adminImports := protectedAdmin.Group("/imports")
+				adminImports.Use(adminOnly)
+				{
+					adminImports.POST("/rss", importController.Start)
+					adminImports.POST("/bulk", bulkImportController.Start)
+					adminImports.POST("/bulk/:id/resume", bulkImportController.Resume)
+				}
+
+				adminUploads := protectedAdmin.Group("/uploads")
+				adminUploads.Use(tusController.TusHeaders())
+				{
+					adminUploads.OPTIONS("/", tusController.Options)
+					adminUploads.POST("/", tusController.Create)
+					adminUploads.HEAD("/:id", tusController.Head)
+					adminUploads.PATCH("/:id", tusController.Patch)
+					adminUploads.DELETE("/:id", tusController.Delete)
+				}
+
+				adminJobs := protectedAdmin.Group("/jobs")
+				{
+					adminJobs.GET("/", jobController.FindAll)
+					adminJobs.GET("/:id", jobController.FindByID)
+				}
+
+				adminDuplicates := protectedAdmin.Group("/duplicates")
+				adminDuplicates.Use(adminOnly)
+				{
+					adminDuplicates.GET("/audios", duplicateController.Audios)
+					adminDuplicates.POST("/audios/merge", duplicateController.MergeAudios)
+					adminDuplicates.GET("/images", duplicateController.Images)
+					adminDuplicates.POST("/images/merge", duplicateController.MergeImage)
+					adminDuplicates.POST("/backfill", duplicateController.Backfill)
+				}
+
+				adminMedia := protectedAdmin.Group("/media")
+				adminMedia.Use(adminOnly)
+				{
+					adminMedia.POST("/variants/backfill", mediaController.BackfillVariants)
+				}
+
+				adminTrash := protectedAdmin.Group("/trash")
+				adminTrash.Use(adminOnly)
+				{
+					adminTrash.GET("/", trashController.Summary)
+					adminTrash.GET("/:type", trashController.List)
+					adminTrash.POST("/:type/:id/restore", trashController.Restore)
+					adminTrash.DELETE("/:type/:id", trashController.Purge)
+				}
+
+				adminCatalog := protectedAdmin.Group("/catalog")
+				adminCatalog.Use(adminOnly)
+				{
+					adminCatalog.GET("/export", catalogController.Export)
+					adminCatalog.POST("/import", catalogController.Import)
+				}
+
+				adminNotifications := protectedAdmin.Group("/notifications")
+				{
+					adminNotifications.GET("/", notificationController.FindAll)
+					adminNotifications.POST("/read", notificationController.MarkAllRead)
+					adminNotifications.POST("/:id/read", notificationController.MarkRead)
+				}
+			}
+		}
+
+		userAuth := api.Group("/user")
+		{
+			userAuth.POST("/auth/register", uController.Register)
+			userAuth.POST("/auth/login", uController.Login)
+
+			protectedUser := userAuth.Group("/")
+			protectedUser.Use(middleware.JWTMiddleware())
+			{
+				protectedUser.GET("/auth/me", uController.Me)
+				protectedUser.PUT("/auth/update/:id", uController.Update)
+				protectedUser.POST("/auth/logout", uController.Logout)
+
+				playlists := protectedUser.Group("/playlists")
+				{
+					playlists.GET("/", playlistController.GetMyPlaylists)
+					playlists.GET("/search", playlistController.Search)
+					playlists.GET("/:id", playlistController.GetDetail)
+					playlists.POST("/", playlistController.Create)
+					playlists.POST("/add-audio", playlistController.AddAudio)
+				}
+
+				likes := protectedUser.Group("/likes")
+				{
+					likes.POST("/", likeController.Like)
+					likes.DELETE("/:audio_id", likeController.Unlike)
+					likes.GET("/", likeController.GetLikes)
+				}
+			}
+		}
+	}
+}
@@ -1,82 +1,135 @@
-package admin
-
-import (
-	"errors"
-
-	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
-
-	adminModel "mqfm-backend/internal/models/auth/admin"
-	"mqfm-backend/internal/utils"
-
-)
-
-type AdminAuthService struct {
-	db *gorm.DB
-}
-
-func NewAdminAuthService(db *gorm.DB) *AdminAuthService {
-	return &AdminAuthService{db: db}
-}
-
-func (s *AdminAuthService) Register(admin *adminModel.Admin) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(admin.Password), bcrypt.DefaultCost)
-	if err != nil {
-		utils.Log.Error("Failed to hash admin password")
-		return err
-	}
-	admin.Password = string(hashedPassword)
-	admin.Role = "admin"
-	return s.db.Create(admin).Error
-}
-
-func (s *AdminAuthService) Login(email, password string) (string, *adminModel.Admin, error) {
-	var admin adminModel.Admin
-	if err := s.db.Where("email = ?", email).First(&admin).Error; err != nil {
-		utils.Log.Warn("Admin login attempt failed: email not found")
-		return "", nil, errors.New("invalid admin credentials")
-	}
-
-	if err := bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(password)); err != nil {
-		utils.Log.Warn("Admin login attempt failed: incorrect password")
-		return "", nil, errors.New("invalid admin credentials")
-	}
-
-	token, err := utils.GenerateToken(admin.ID, "admin")
-	if err != nil {
-		utils.Log.Error("Failed to generate admin JWT token: " + err.Error())
-		return "", nil, err
-	}
-
-	return token, &admin, nil
-}
-
-func (s *AdminAuthService) UpdateAdmin(id uint, updates map[string]interface{}) (*adminModel.Admin, error) {
-	if pwd, ok := updates["password"].(string); ok && pwd != "" {
-		hashed, err := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.DefaultCost)
-		if err != nil {
-			utils.Log.Error("Failed to hash new admin password")
-			return nil, err
-		}
-		updates["password"] = string(hashed)
-	}
-
-	if err := s.db.Model(&adminModel.Admin{}).Where("id = ?", id).Updates(updates).Error; err != nil {
-		return nil, err
-	}
-
-	var updatedAdmin adminModel.Admin
-	if err := s.db.First(&updatedAdmin, id).Error; err != nil {
-		return nil, err
-	}
-
-	return &updatedAdmin, nil
-}
-
-func (s *AdminAuthService) GetAdminByID(id uint) (*adminModel.Admin, error) {
-	var admin adminModel.Admin
-	if err := s.db.First(&admin, id).Error; err != nil {
-		return nil, errors.New("admin not found")
-	}
-	return &admin, nil
-}
\ No newline at end of file
+package admin
+
+import (
+	"errors"
+	"slices"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	adminModel "mqfm-backend/internal/models/auth/admin"
+	"mqfm-backend/internal/utils"
+
+)
+
+type AdminAuthService struct {
+	db *gorm.DB
+}
+
+func NewAdminAuthService(db *gorm.DB) *AdminAuthService {
+	return &AdminAuthService{db: db}
+}
+
+// Register creates an account for a new team member. Accounts start as
+// producers; an admin promotes them with SetRole.
+func (s *AdminAuthService) Register(admin *adminModel.Admin) error {
+	return s.create(admin, adminModel.RoleProducer)
+}
+
+// SeedAdmin creates the first admin account when there is none yet, since
+// only admins can register accounts. It runs once from main at startup.
+func (s *AdminAuthService) SeedAdmin(username, email, password string) error {
+	var count int64
+	if err := s.db.Model(&adminModel.Admin{}).Where("role = ?", adminModel.RoleAdmin).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if email == "" || password == "" {
+		return errors.New("ADMIN_EMAIL and ADMIN_PASSWORD are required to create the first admin")
+	}
+
+	admin := adminModel.Admin{Username: username, Email: email, Password: password}
+	if err := s.create(&admin, adminModel.RoleAdmin); err != nil {
+		return err
+	}
+	utils.Log.Info("First admin account created: " + email)
+	return nil
+}
+
+func (s *AdminAuthService) create(admin *adminModel.Admin, role string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(admin.Password), bcrypt.DefaultCost)
+	if err != nil {
+		utils.Log.Error("Failed to hash admin password")
+		return err
+	}
+	admin.Password = string(hashedPassword)
+	admin.Role = role
+	return s.db.Create(admin).Error
+}
+
+func (s *AdminAuthService) Login(email, password string) (string, *adminModel.Admin, error) {
+	var admin adminModel.Admin
+	if err := s.db.Where("email = ?", email).First(&admin).Error; err != nil {
+		utils.Log.Warn("Admin login attempt failed: email not found")
+		return "", nil, errors.New("invalid admin credentials")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(password)); err != nil {
+		utils.Log.Warn("Admin login attempt failed: incorrect password")
+		return "", nil, errors.New("invalid admin credentials")
+	}
+
+	role := admin.Role
+	if role == "" {
+		role = adminModel.RoleAdmin
+	}
+	token, err := utils.GenerateToken(admin.ID, role)
+	if err != nil {
+		utils.Log.Error("Failed to generate admin JWT token: " + err.Error())
+		return "", nil, err
+	}
+
+	return token, &admin, nil
+}
+
+func (s *AdminAuthService) UpdateAdmin(id uint, updates map[string]interface{}) (*adminModel.Admin, error) {
+	// Roles only change through SetRole.
+	delete(updates, "role")
+	delete(updates, "id")
+
+	if pwd, ok := updates["password"].(string); ok && pwd != "" {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.DefaultCost)
+		if err != nil {
+			utils.Log.Error("Failed to hash new admin password")
+			return nil, err
+		}
+		updates["password"] = string(hashed)
+	}
+
+	if err := s.db.Model(&adminModel.Admin{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	var updatedAdmin adminModel.Admin
+	if err := s.db.First(&updatedAdmin, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &updatedAdmin, nil
+}
+
+func (s *AdminAuthService) GetAdminByID(id uint) (*adminModel.Admin, error) {
+	var admin adminModel.Admin
+	if err := s.db.First(&admin, id).Error; err != nil {
+		return nil, errors.New("admin not found")
+	}
+	return &admin, nil
+}
+
+// SetRole changes an admin account's role. It takes effect on the next login.
+func (s *AdminAuthService) SetRole(id uint, role string) (*adminModel.Admin, error) {
+	if !slices.Contains(adminModel.Roles, role) {
+		return nil, errors.New("unknown role")
+	}
+
+	result := s.db.Model(&adminModel.Admin{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("admin not found")
+	}
+	return s.GetAdminByID(id)
+}
@@ -0,0 +1,188 @@
+package avatar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	userModel "mqfm-backend/internal/models/auth/user"
+	"mqfm-backend/internal/services/media"
+	"mqfm-backend/internal/storage"
+	"mqfm-backend/internal/utils"
+)
+
+const (
+	FormatSVG = "svg"
+	FormatPNG = "png"
+
+	DefaultSize = 128
+
+	photoQuality = 85
+	// renderVersion is part of every ETag so a change in how avatars are drawn
+	// invalidates cached copies.
+	renderVersion = "1"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+// Sizes are the edges avatars are served at. They include the stored variant
+// sizes, which are sent as they are, and stop at the largest one since photos
+// are never upscaled.
+var Sizes = []int{32, 64, 96, 128, 256, 300, 512, 1000}
+
+// SnapSize returns the smallest of Sizes that covers size, so arbitrary
+// sizes can't each cost a render.
+func SnapSize(size int) int {
+	for _, s := range Sizes {
+		if s >= size {
+			return s
+		}
+	}
+	return Sizes[len(Sizes)-1]
+}
+
+// Avatar is a rendered avatar, or a redirect for pictures that have no
+// variants yet. NotModified avatars matched the client's ETag and have no
+// body.
+type Avatar struct {
+	Body         []byte
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+	RedirectURL  string
+	NotModified  bool
+}
+
+type AvatarService struct {
+	db    *gorm.DB
+	store storage.Storage
+}
+
+func NewAvatarService(db *gorm.DB, store storage.Storage) *AvatarService {
+	return &AvatarService{db: db, store: store}
+}
+
+// Render returns the user's profile picture scaled to size, or generated
+// initials on their ambient colour when they have none. Photos are always JPEG;
+// format only applies to generated avatars. size should be one of Sizes. The
+// ETag comes from the user row alone, so when ifNoneMatch holds it nothing is
+// rendered.
+func (s *AvatarService) Render(userID uint, size int, format, ifNoneMatch string) (*Avatar, error) {
+	var user userModel.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if user.ProfilePicture != "" {
+		return s.photo(&user, size, ifNoneMatch)
+	}
+
+	avatar := &Avatar{
+		ETag:         etag(renderVersion, user.Username, strconv.Itoa(size), format),
+		LastModified: user.UpdatedAt,
+	}
+	if utils.ETagMatches(ifNoneMatch, avatar.ETag) {
+		avatar.NotModified = true
+		return avatar, nil
+	}
+	var err error
+	if format == FormatPNG {
+		avatar.ContentType = "image/png"
+		avatar.Body, err = initialsPNG(user.Username, size)
+	} else {
+		avatar.ContentType = "image/svg+xml"
+		avatar.Body = initialsSVG(user.Username, size)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return avatar, nil
+}
+
+// photo scales the smallest variant that covers size, or sends it as it is
+// when it has that size. Pictures uploaded before variants existed are
+// redirected to as they are.
+func (s *AvatarService) photo(user *userModel.User, size int, ifNoneMatch string) (*Avatar, error) {
+	key, variantSize := "", 0
+	for _, v := range media.VariantSizes {
+		key, variantSize = user.ProfilePictureVariants[v.Name], v.Size
+		if v.Size >= size && key != "" {
+			break
+		}
+	}
+	if key == "" {
+		return s.redirect(user), nil
+	}
+
+	source := user.ProfilePictureHash
+	if source == "" {
+		source = key
+	}
+	avatar := &Avatar{
+		ContentType:  "image/jpeg",
+		ETag:         etag(renderVersion, source, strconv.Itoa(size)),
+		LastModified: user.UpdatedAt,
+	}
+	if utils.ETagMatches(ifNoneMatch, avatar.ETag) {
+		avatar.NotModified = true
+		return avatar, nil
+	}
+
+	r, err := s.store.Get(key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return s.redirect(user), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	if variantSize == size {
+		avatar.Body, err = io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return avatar, nil
+	}
+
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	img = media.Resize(img, size)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: photoQuality}); err != nil {
+		return nil, err
+	}
+	avatar.Body = buf.Bytes()
+	return avatar, nil
+}
+
+func (s *AvatarService) redirect(user *userModel.User) *Avatar {
+	key := media.NormalizeKey(user.ProfilePicture)
+	if key == "" {
+		return &Avatar{RedirectURL: user.ProfilePicture}
+	}
+	return &Avatar{RedirectURL: s.store.URL(key)}
+}
+
+func etag(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+}
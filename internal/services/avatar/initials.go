@@ -0,0 +1,97 @@
+package avatar
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"mqfm-backend/internal/utils"
+)
+
+// textScale is the font size relative to the avatar edge.
+const textScale = 0.42
+
+var (
+	boldFont     *opentype.Font
+	boldFontErr  error
+	boldFontOnce sync.Once
+)
+
+// initialsSVG draws the same initials and colour the user responses carry, so
+// clients can drop their own drawing code.
+func initialsSVG(username string, size int) []byte {
+	return []byte(fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+
+			`<rect width="100%%" height="100%%" fill="%s"/>`+
+			`<text x="50%%" y="50%%" dy=".35em" fill="#ffffff" font-family="Helvetica, Arial, sans-serif" font-size="%d" font-weight="bold" text-anchor="middle">%s</text>`+
+			`</svg>`,
+		size, size, size, size,
+		utils.GenerateAmbientColor(username),
+		int(float64(size)*textScale),
+		html.EscapeString(utils.GetInitials(username)),
+	))
+}
+
+func initialsPNG(username string, size int) ([]byte, error) {
+	bg, err := parseHex(utils.GenerateAmbientColor(username))
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	if initials := utils.GetInitials(username); initials != "" {
+		face, err := boldFace(float64(size) * textScale)
+		if err != nil {
+			return nil, err
+		}
+		defer face.Close()
+
+		d := &font.Drawer{Dst: img, Src: image.NewUniform(color.White), Face: face}
+		width := d.MeasureString(initials)
+		capHeight := face.Metrics().CapHeight
+		d.Dot = fixed.Point26_6{
+			X: (fixed.I(size) - width) / 2,
+			Y: (fixed.I(size) + capHeight) / 2,
+		}
+		d.DrawString(initials)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func boldFace(size float64) (font.Face, error) {
+	boldFontOnce.Do(func() {
+		boldFont, boldFontErr = opentype.Parse(gobold.TTF)
+	})
+	if boldFontErr != nil {
+		return nil, boldFontErr
+	}
+	return opentype.NewFace(boldFont, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+func parseHex(hex string) (color.RGBA, error) {
+	if len(hex) != 7 || hex[0] != '#' {
+		return color.RGBA{}, fmt.Errorf("invalid colour %q", hex)
+	}
+	v, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid colour %q", hex)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
@@ -0,0 +1,927 @@
+package catalog
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	catalogModel "mqfm-backend/internal/models/catalog"
+	categoryModel "mqfm-backend/internal/models/category/admin"
+	mediaModel "mqfm-backend/internal/models/media"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	showModel "mqfm-backend/internal/models/podcast/show"
+	"mqfm-backend/internal/services/media"
+	audioService "mqfm-backend/internal/services/podcast/audio/admin"
+	"mqfm-backend/internal/utils"
+)
+
+const JobTypeCatalogImport = "catalog_import"
+
+var (
+	ErrArchiveTooLarge    = errors.New("archive exceeds the maximum size")
+	ErrNoManifest         = errors.New("archive has no " + catalogModel.ManifestName)
+	ErrUnsupportedVersion = errors.New("archive uses an unsupported format version")
+	ErrArchiveGone        = errors.New("the archive for this import is no longer available")
+)
+
+// PlanError is an archive refused because some of its records can't be
+// imported. Report lists them in its Errors.
+type PlanError struct {
+	Report *catalogModel.ImportReport
+}
+
+func (e *PlanError) Error() string {
+	return "archive has records that can't be imported"
+}
+
+// CatalogImportPayload is stored on the import job.
+type CatalogImportPayload struct {
+	Archive  string `json:"archive"`
+	Filename string `json:"filename"`
+}
+
+// Import compares the archive with this catalogue. A dry run only returns the
+// report. Otherwise the archive is imported by a background job whose ID is
+// returned, unless the report has errors, in which case nothing is imported
+// and a *PlanError is returned.
+func (s *CatalogService) Import(adminID uint, file *multipart.FileHeader, dryRun bool) (*catalogModel.ImportReport, uint, error) {
+	if file.Size > s.maxSize {
+		return nil, 0, ErrArchiveTooLarge
+	}
+
+	staged, _, err := media.StageArchive(file, "catalog-")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	p, err := s.openPlan(staged)
+	if err != nil {
+		os.Remove(staged)
+		return nil, 0, err
+	}
+	p.close()
+	p.report.DryRun = dryRun
+
+	if dryRun {
+		os.Remove(staged)
+		return p.report, 0, nil
+	}
+	if len(p.report.Errors) > 0 {
+		os.Remove(staged)
+		return p.report, 0, &PlanError{Report: p.report}
+	}
+
+	payload := CatalogImportPayload{Archive: filepath.Base(staged), Filename: file.Filename}
+	job, err := s.jobs.Create(JobTypeCatalogImport, adminID, payload)
+	if err != nil {
+		os.Remove(staged)
+		return nil, 0, err
+	}
+
+	go s.run(job.ID, adminID, payload)
+	return p.report, job.ID, nil
+}
+
+// ResumeUnfinished runs again the imports cut short by a restart. The plan is
+// made afresh, so records imported before the restart match by external ID
+// and are left alone. Imports whose archive is gone are marked failed. It runs
+// once from main at startup.
+func (s *CatalogService) ResumeUnfinished() error {
+	jobs, err := s.jobs.FindUnfinished(JobTypeCatalogImport)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		var payload CatalogImportPayload
+		err := json.Unmarshal([]byte(job.Payload), &payload)
+		if err == nil {
+			if _, statErr := os.Stat(media.ArchivePath(payload.Archive)); statErr != nil {
+				err = ErrArchiveGone
+			}
+		}
+		if err != nil {
+			utils.Log.Error("[Catalog] Failed to resume import", zap.Error(err), zap.Uint("job_id", job.ID))
+			s.jobs.Fail(job.ID, err, catalogModel.ImportResult{Failed: []catalogModel.ItemError{}})
+			continue
+		}
+
+		go s.run(job.ID, job.CreatedBy, payload)
+	}
+	return nil
+}
+
+func (s *CatalogService) run(jobID, adminID uint, payload CatalogImportPayload) {
+	s.jobs.Start(jobID)
+	utils.Log.Info("[Catalog] Import started",
+		zap.Uint("job_id", jobID),
+		zap.String("filename", payload.Filename),
+	)
+
+	staged := media.ArchivePath(payload.Archive)
+	defer os.Remove(staged)
+
+	result := catalogModel.ImportResult{Failed: []catalogModel.ItemError{}}
+
+	// The catalogue may have changed since the archive was checked, so the
+	// plan is made again against its current state.
+	p, err := s.openPlan(staged)
+	if err != nil {
+		utils.Log.Error("[Catalog] Failed to open archive", zap.Error(err), zap.Uint("job_id", jobID))
+		s.jobs.Fail(jobID, err, result)
+		return
+	}
+	defer p.close()
+	if len(p.report.Errors) > 0 {
+		s.jobs.Fail(jobID, errors.New(strings.Join(p.report.Errors, "; ")), result)
+		return
+	}
+
+	total := len(p.categories) + len(p.shows) + len(p.audios)
+	done := 0
+	step := func(kind string, e *entry, err error) {
+		switch {
+		case err != nil:
+			utils.Log.Warn("[Catalog] Record failed",
+				zap.Error(err),
+				zap.Uint("job_id", jobID),
+				zap.String("type", kind),
+				zap.String("external_id", e.ExternalID),
+			)
+			result.Failed = append(result.Failed, catalogModel.ItemError{Type: kind, ExternalID: e.ExternalID, Error: err.Error()})
+		case e.Action == catalogModel.ActionCreate:
+			result.Created++
+		case e.Action == catalogModel.ActionUpdate:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+		done++
+		s.jobs.SetProgress(jobID, done, total)
+	}
+
+	failedCategories := make(map[int]bool)
+	for i := range p.categories {
+		err := s.importCategory(p, i, adminID)
+		failedCategories[i] = err != nil
+		step("category", &p.categories[i], err)
+	}
+	// Parents are set once every category exists, so the archive order
+	// doesn't matter.
+	for i := range p.categories {
+		if failedCategories[i] {
+			continue
+		}
+		if err := s.linkParent(p, i, adminID); err != nil {
+			e := &p.categories[i]
+			result.Failed = append(result.Failed, catalogModel.ItemError{Type: "category", ExternalID: e.ExternalID, Error: err.Error()})
+		}
+	}
+	for i := range p.shows {
+		step("show", &p.shows[i], s.importShow(p, i))
+	}
+	for i := range p.audios {
+		step("audio", &p.audios[i], s.importAudio(p, i, adminID))
+	}
+
+	s.jobs.Complete(jobID, result)
+	utils.Log.Info("[Catalog] Import finished",
+		zap.Uint("job_id", jobID),
+		zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated),
+		zap.Int("unchanged", result.Unchanged),
+		zap.Int("failed", len(result.Failed)),
+	)
+}
+
+// entry is the plan for one archive record. ID is the local record it
+// updates, or the one created for it once imported.
+type entry struct {
+	catalogModel.ItemPlan
+	ID      uint
+	Trashed bool
+}
+
+// plan matches an archive against the local catalogue.
+type plan struct {
+	archive    *zip.ReadCloser
+	manifest   catalogModel.Manifest
+	files      map[string]*zip.File
+	report     *catalogModel.ImportReport
+	categories []entry
+	shows      []entry
+	audios     []entry
+	// categoryIDs and showIDs map external IDs to local IDs.
+	categoryIDs map[string]uint
+	showIDs     map[string]uint
+}
+
+func (p *plan) close() {
+	p.archive.Close()
+}
+
+func (p *plan) errorf(format string, args ...interface{}) {
+	p.report.Errors = append(p.report.Errors, fmt.Sprintf(format, args...))
+}
+
+func (s *CatalogService) openPlan(staged string) (*plan, error) {
+	archive, err := zip.OpenReader(staged)
+	if err != nil {
+		return nil, err
+	}
+	p := &plan{
+		archive:     archive,
+		files:       make(map[string]*zip.File),
+		categoryIDs: make(map[string]uint),
+		showIDs:     make(map[string]uint),
+	}
+	for _, f := range archive.File {
+		p.files[f.Name] = f
+	}
+	if err := p.readManifest(); err != nil {
+		archive.Close()
+		return nil, err
+	}
+	if err := s.match(p); err != nil {
+		archive.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *plan) readManifest() error {
+	f, ok := p.files[catalogModel.ManifestName]
+	if !ok {
+		return ErrNoManifest
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := json.NewDecoder(r).Decode(&p.manifest); err != nil {
+		return fmt.Errorf("%s: %w", catalogModel.ManifestName, err)
+	}
+	if p.manifest.Version < 1 || p.manifest.Version > catalogModel.FormatVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, p.manifest.Version)
+	}
+	return nil
+}
+
+// match pairs every archive record with the local record of the same
+// external ID, trashed ones included, and works out what importing it
+// changes.
+func (s *CatalogService) match(p *plan) error {
+	local, err := s.snapshot(true)
+	if err != nil {
+		return err
+	}
+	m := &p.manifest
+	p.report = &catalogModel.ImportReport{
+		Version:    m.Version,
+		Categories: []catalogModel.ItemPlan{},
+		Shows:      []catalogModel.ItemPlan{},
+		Audios:     []catalogModel.ItemPlan{},
+		Errors:     []string{},
+	}
+
+	categories := make(map[string]*categoryModel.Category)
+	byName := make(map[string]*categoryModel.Category)
+	for i := range local.categories {
+		c := &local.categories[i]
+		categories[c.ExternalID] = c
+		p.categoryIDs[c.ExternalID] = c.ID
+		if !c.DeletedAt.Valid {
+			byName[strings.ToLower(c.Name)] = c
+		}
+	}
+	shows := make(map[string]*showModel.Show)
+	for i := range local.shows {
+		shows[local.shows[i].ExternalID] = &local.shows[i]
+		p.showIDs[local.shows[i].ExternalID] = local.shows[i].ID
+	}
+	audios := make(map[string]*audioModel.Audio)
+	for i := range local.audios {
+		audios[local.audios[i].ExternalID] = &local.audios[i]
+	}
+
+	incomingCategories := uniqueIDs(p, "category", len(m.Categories), func(i int) string { return m.Categories[i].ExternalID })
+	uniqueIDs(p, "show", len(m.Shows), func(i int) string { return m.Shows[i].ExternalID })
+	uniqueIDs(p, "audio", len(m.Audios), func(i int) string { return m.Audios[i].ExternalID })
+
+	// A category the other environment created on its own is adopted by name
+	// rather than duplicated. Adoption comes first so references to it from
+	// other records compare equal.
+	adopted := make(map[string]bool)
+	for _, c := range m.Categories {
+		if _, ok := categories[c.ExternalID]; ok {
+			continue
+		}
+		match, ok := byName[strings.ToLower(strings.TrimSpace(c.Name))]
+		if !ok || incomingCategories[match.ExternalID] || adopted[match.ExternalID] {
+			continue
+		}
+		adopted[match.ExternalID] = true
+		delete(p.categoryIDs, match.ExternalID)
+		local.categoryExtID[match.ID] = c.ExternalID
+		categories[c.ExternalID] = match
+		p.categoryIDs[c.ExternalID] = match.ID
+	}
+
+	hasCategory := func(ext string) bool {
+		_, ok := p.categoryIDs[ext]
+		return ok || incomingCategories[ext]
+	}
+
+	for _, c := range m.Categories {
+		label := fmt.Sprintf("category %q (%s)", c.Name, c.ExternalID)
+		if strings.TrimSpace(c.Name) == "" {
+			p.errorf("%s: name is required", label)
+		}
+		if c.Parent == c.ExternalID && c.Parent != "" {
+			p.errorf("%s: a category cannot be its own parent", label)
+		} else if c.Parent != "" && !hasCategory(c.Parent) {
+			p.errorf("%s: parent %q is neither in the archive nor in this catalogue", label, c.Parent)
+		}
+
+		e := entry{ItemPlan: catalogModel.ItemPlan{ExternalID: c.ExternalID, Label: c.Name}}
+		var current *catalogModel.Category
+		if existing, ok := categories[c.ExternalID]; ok {
+			exported := local.category(*existing)
+			current = &exported
+			e.ID, e.Trashed = existing.ID, existing.DeletedAt.Valid
+			e.Changes = diff(exported, c)
+			if existing.ExternalID != c.ExternalID {
+				e.Changes["external_id"] = catalogModel.FieldChange{From: existing.ExternalID, To: c.ExternalID}
+			}
+		}
+		checkMedia(p, label, "icon", c.Icon, currentMedia(current, func(c *catalogModel.Category) *catalogModel.Media { return c.Icon }))
+		checkMedia(p, label, "cover", c.Cover, currentMedia(current, func(c *catalogModel.Category) *catalogModel.Media { return c.Cover }))
+		p.categories = append(p.categories, e.settle())
+	}
+
+	for _, sh := range m.Shows {
+		label := fmt.Sprintf("show %q (%s)", sh.Title, sh.ExternalID)
+		if strings.TrimSpace(sh.Title) == "" {
+			p.errorf("%s: title is required", label)
+		}
+		if sh.Category != "" && !hasCategory(sh.Category) {
+			p.errorf("%s: category %q is neither in the archive nor in this catalogue", label, sh.Category)
+		}
+
+		e := entry{ItemPlan: catalogModel.ItemPlan{ExternalID: sh.ExternalID, Label: sh.Title}}
+		var current *catalogModel.Show
+		if existing, ok := shows[sh.ExternalID]; ok {
+			exported := local.show(*existing)
+			current = &exported
+			e.ID, e.Trashed = existing.ID, existing.DeletedAt.Valid
+			e.Changes = diff(exported, sh)
+		}
+		checkMedia(p, label, "artwork", sh.Artwork, currentMedia(current, func(sh *catalogModel.Show) *catalogModel.Media { return sh.Artwork }))
+		p.shows = append(p.shows, e.settle())
+	}
+
+	incomingShows := make(map[string]bool, len(m.Shows))
+	for _, sh := range m.Shows {
+		incomingShows[sh.ExternalID] = true
+	}
+	for i := range m.Audios {
+		a := &m.Audios[i]
+		normalizeAudio(a)
+		label := fmt.Sprintf("audio %q (%s)", a.Title, a.ExternalID)
+		if strings.TrimSpace(a.Title) == "" {
+			p.errorf("%s: title is required", label)
+		}
+		if a.Category != "" && !hasCategory(a.Category) {
+			p.errorf("%s: category %q is neither in the archive nor in this catalogue", label, a.Category)
+		}
+		if _, ok := p.showIDs[a.Show]; a.Show != "" && !ok && !incomingShows[a.Show] {
+			p.errorf("%s: show %q is neither in the archive nor in this catalogue", label, a.Show)
+		}
+		if !slices.Contains(audioModel.Statuses, a.Status) {
+			p.errorf("%s: unknown status %q", label, a.Status)
+		}
+
+		e := entry{ItemPlan: catalogModel.ItemPlan{ExternalID: a.ExternalID, Label: a.Title}}
+		var current *catalogModel.Audio
+		if existing, ok := audios[a.ExternalID]; ok {
+			exported := local.audio(*existing)
+			current = &exported
+			e.ID, e.Trashed = existing.ID, existing.DeletedAt.Valid
+			e.Changes = diff(exported, *a)
+		}
+		checkMedia(p, label, "audio", a.Audio, currentMedia(current, func(a *catalogModel.Audio) *catalogModel.Media { return a.Audio }))
+		checkMedia(p, label, "thumbnail", a.Thumbnail, currentMedia(current, func(a *catalogModel.Audio) *catalogModel.Media { return a.Thumbnail }))
+		p.audios = append(p.audios, e.settle())
+	}
+
+	for _, e := range p.categories {
+		p.report.Categories = append(p.report.Categories, e.ItemPlan)
+	}
+	for _, e := range p.shows {
+		p.report.Shows = append(p.report.Shows, e.ItemPlan)
+	}
+	for _, e := range p.audios {
+		p.report.Audios = append(p.report.Audios, e.ItemPlan)
+	}
+	return nil
+}
+
+// settle sets the entry's action from what was matched.
+func (e entry) settle() entry {
+	switch {
+	case e.ID == 0:
+		e.Action = catalogModel.ActionCreate
+	case e.Trashed:
+		e.Changes["deleted"] = catalogModel.FieldChange{From: true, To: false}
+		e.Action = catalogModel.ActionUpdate
+	case len(e.Changes) > 0:
+		e.Action = catalogModel.ActionUpdate
+	default:
+		e.Action = catalogModel.ActionUnchanged
+	}
+	return e
+}
+
+func (e *entry) changed(field string) bool {
+	if e.Action == catalogModel.ActionCreate {
+		return true
+	}
+	_, ok := e.Changes[field]
+	return ok
+}
+
+// uniqueIDs reports missing and repeated external IDs and returns the set of
+// those seen.
+func uniqueIDs(p *plan, kind string, n int, id func(int) string) map[string]bool {
+	seen := make(map[string]bool, n)
+	for i := 0; i < n; i++ {
+		ext := id(i)
+		switch {
+		case strings.TrimSpace(ext) == "":
+			p.errorf("%s #%d: external_id is required", kind, i+1)
+		case seen[ext]:
+			p.errorf("%s %s: external_id appears more than once", kind, ext)
+		}
+		seen[ext] = true
+	}
+	return seen
+}
+
+func currentMedia[T any](current *T, field func(*T) *catalogModel.Media) *catalogModel.Media {
+	if current == nil {
+		return nil
+	}
+	return field(current)
+}
+
+// checkMedia makes sure an incoming file can be imported: it must be in the
+// archive unless this catalogue already has the same content.
+func checkMedia(p *plan, label, field string, incoming, current *catalogModel.Media) {
+	if incoming == nil {
+		return
+	}
+	if incoming.File != "" {
+		if _, ok := p.files[incoming.File]; !ok {
+			p.errorf("%s: %s file %s is missing from the archive", label, field, incoming.File)
+		}
+		return
+	}
+	if current == nil || current.Hash != incoming.Hash {
+		p.errorf("%s: %s was exported without media and differs from this catalogue", label, field)
+	}
+}
+
+// diff compares two exported records field by field. Media compare by hash.
+func diff(from, to interface{}) map[string]catalogModel.FieldChange {
+	a, b := exportedFields(from), exportedFields(to)
+	changes := make(map[string]catalogModel.FieldChange)
+	for key, value := range b {
+		if !reflect.DeepEqual(a[key], value) {
+			changes[key] = catalogModel.FieldChange{From: a[key], To: value}
+		}
+	}
+	for key, value := range a {
+		if _, ok := b[key]; !ok {
+			changes[key] = catalogModel.FieldChange{From: value, To: nil}
+		}
+	}
+	return changes
+}
+
+func exportedFields(record interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	raw, _ := json.Marshal(record)
+	json.Unmarshal(raw, &fields)
+	delete(fields, "external_id")
+	for key, value := range fields {
+		if ref, ok := value.(map[string]interface{}); ok {
+			fields[key] = ref["hash"]
+		}
+	}
+	return fields
+}
+
+// normalizeAudio puts an incoming audio in the form the export writes, so
+// equal records compare equal.
+func normalizeAudio(a *catalogModel.Audio) {
+	a.Tags = sortedTags(a.Tags)
+	a.PublishAt, a.UnpublishAt, a.PublishedAt = utc(a.PublishAt), utc(a.UnpublishAt), utc(a.PublishedAt)
+	if a.Status == "" {
+		a.Status = audioModel.StatusPublished
+	}
+}
+
+func (s *CatalogService) importCategory(p *plan, i int, adminID uint) error {
+	e := &p.categories[i]
+	c := p.manifest.Categories[i]
+
+	if e.Action == catalogModel.ActionCreate {
+		category := categoryModel.Category{
+			ExternalID:  c.ExternalID,
+			Name:        strings.TrimSpace(c.Name),
+			Slug:        c.Slug,
+			Description: c.Description,
+			Position:    c.Position,
+		}
+		icon, cover, err := s.ingestPair(p, c.Icon, c.Cover, "categories")
+		if err != nil {
+			return err
+		}
+		if icon != nil {
+			category.Icon, category.IconHash, category.IconVariants = icon.Path, icon.Hash, icon.Variants
+		}
+		if cover != nil {
+			category.Cover, category.CoverHash, category.CoverVariants = cover.Path, cover.Hash, cover.Variants
+		}
+		if err := s.categories.Create(&category); err != nil {
+			return err
+		}
+		e.ID = category.ID
+		p.categoryIDs[c.ExternalID] = category.ID
+		return nil
+	}
+	if e.Action == catalogModel.ActionUnchanged {
+		return nil
+	}
+
+	updates := map[string]interface{}{}
+	if e.changed("external_id") {
+		updates["external_id"] = c.ExternalID
+	}
+	if e.changed("name") {
+		updates["name"] = strings.TrimSpace(c.Name)
+	}
+	if e.changed("slug") {
+		updates["slug"] = c.Slug
+	}
+	if e.changed("description") {
+		updates["description"] = c.Description
+	}
+	if e.changed("position") {
+		updates["position"] = c.Position
+	}
+	var icon, cover *catalogModel.Media
+	if e.changed("icon") {
+		icon = c.Icon
+	}
+	if e.changed("cover") {
+		cover = c.Cover
+	}
+	iconAsset, coverAsset, err := s.ingestPair(p, icon, cover, "categories")
+	if err != nil {
+		return err
+	}
+	if e.changed("icon") {
+		setImage(updates, "icon", iconAsset, false)
+	}
+	if e.changed("cover") {
+		setImage(updates, "cover", coverAsset, false)
+	}
+
+	if err := s.restore(e, &categoryModel.Category{}); err != nil {
+		s.cleanup.Release(assetKeys(iconAsset, coverAsset)...)
+		return err
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	_, err = s.categories.Update(e.ID, adminID, updates)
+	return err
+}
+
+func (s *CatalogService) linkParent(p *plan, i int, adminID uint) error {
+	e := &p.categories[i]
+	c := p.manifest.Categories[i]
+	if e.ID == 0 || !e.changed("parent") || (e.Action == catalogModel.ActionCreate && c.Parent == "") {
+		return nil
+	}
+	parentID, err := resolve(p.categoryIDs, "category", c.Parent)
+	if err != nil {
+		return err
+	}
+	_, err = s.categories.Update(e.ID, adminID, map[string]interface{}{"parent_id": parentID})
+	return err
+}
+
+func (s *CatalogService) importShow(p *plan, i int) error {
+	e := &p.shows[i]
+	sh := p.manifest.Shows[i]
+	if e.Action == catalogModel.ActionUnchanged {
+		return nil
+	}
+
+	categoryID, err := resolve(p.categoryIDs, "category", sh.Category)
+	if err != nil {
+		return err
+	}
+	var artwork *media.Asset
+	if e.changed("artwork") && sh.Artwork != nil {
+		if artwork, err = s.ingestMedia(p, sh.Artwork, media.KindImage, "shows"); err != nil {
+			return fmt.Errorf("artwork: %w", err)
+		}
+	}
+
+	if e.Action == catalogModel.ActionCreate {
+		show := showModel.Show{
+			ExternalID:  sh.ExternalID,
+			Title:       strings.TrimSpace(sh.Title),
+			Description: sh.Description,
+			Hosts:       sh.Hosts,
+			CategoryID:  categoryID,
+		}
+		if artwork != nil {
+			show.Artwork, show.ArtworkHash = artwork.Path, artwork.Hash
+			show.ArtworkVariants, show.Palette = artwork.Variants, artwork.Palette
+		}
+		if err := s.shows.Create(&show); err != nil {
+			return err
+		}
+		e.ID = show.ID
+		p.showIDs[sh.ExternalID] = show.ID
+		return nil
+	}
+
+	updates := map[string]interface{}{}
+	if e.changed("title") {
+		updates["title"] = strings.TrimSpace(sh.Title)
+	}
+	if e.changed("description") {
+		updates["description"] = sh.Description
+	}
+	if e.changed("hosts") {
+		updates["hosts"] = sh.Hosts
+	}
+	if e.changed("category") {
+		updates["category_id"] = categoryID
+	}
+	if e.changed("artwork") {
+		setImage(updates, "artwork", artwork, true)
+	}
+
+	if err := s.restore(e, &showModel.Show{}); err != nil {
+		s.cleanup.Release(assetKeys(artwork)...)
+		return err
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	_, err = s.shows.Update(e.ID, updates)
+	return err
+}
+
+func (s *CatalogService) importAudio(p *plan, i int, adminID uint) error {
+	e := &p.audios[i]
+	a := p.manifest.Audios[i]
+	if e.Action == catalogModel.ActionUnchanged {
+		return nil
+	}
+
+	categoryID, err := resolve(p.categoryIDs, "category", a.Category)
+	if err != nil {
+		return err
+	}
+	showID, err := resolve(p.showIDs, "show", a.Show)
+	if err != nil {
+		return err
+	}
+	var tags []string
+	if e.changed("tags") {
+		tags = a.Tags
+	}
+	tagged, err := s.tags.FindOrCreate(tags)
+	if err != nil {
+		return err
+	}
+
+	var file, thumbnail *media.Asset
+	if e.changed("audio") && a.Audio != nil {
+		if file, err = s.ingestMedia(p, a.Audio, media.KindAudio, ""); err != nil {
+			return fmt.Errorf("audio: %w", err)
+		}
+	}
+	if e.changed("thumbnail") && a.Thumbnail != nil {
+		if thumbnail, err = s.ingestMedia(p, a.Thumbnail, media.KindImage, "thumbnails"); err != nil {
+			s.cleanup.Release(assetKeys(file)...)
+			return fmt.Errorf("thumbnail: %w", err)
+		}
+	}
+
+	if e.Action == catalogModel.ActionCreate {
+		audio := audioModel.Audio{
+			ExternalID:    a.ExternalID,
+			Title:         strings.TrimSpace(a.Title),
+			Description:   a.Description,
+			CategoryID:    categoryID,
+			ShowID:        showID,
+			SeasonNumber:  a.SeasonNumber,
+			EpisodeNumber: a.EpisodeNumber,
+			Status:        a.Status,
+			PublishAt:     a.PublishAt,
+			UnpublishAt:   a.UnpublishAt,
+			PublishedAt:   a.PublishedAt,
+			Duration:      a.Duration,
+			CreatedBy:     adminID,
+			Tags:          tagged,
+		}
+		if file != nil {
+			audio.AudioURL, audio.AudioHash, audio.FileSize = file.Path, file.Hash, file.Size
+			if file.Duration > 0 {
+				audio.Duration = file.Duration
+			}
+		}
+		if thumbnail != nil {
+			audio.Thumbnail, audio.ThumbnailHash = thumbnail.Path, thumbnail.Hash
+			audio.ThumbnailVariants, audio.Palette = thumbnail.Variants, thumbnail.Palette
+		}
+		if err := s.audios.Create(&audio, audioService.Schedule{}); err != nil {
+			return err
+		}
+		e.ID = audio.ID
+		if file != nil {
+			s.transcoder.Enqueue(audio.ID)
+		}
+		return nil
+	}
+
+	updates := map[string]interface{}{}
+	for field, value := range map[string]interface{}{
+		"title":          strings.TrimSpace(a.Title),
+		"description":    a.Description,
+		"season_number":  a.SeasonNumber,
+		"episode_number": a.EpisodeNumber,
+		"status":         a.Status,
+		"publish_at":     a.PublishAt,
+		"unpublish_at":   a.UnpublishAt,
+		"published_at":   a.PublishedAt,
+		"duration":       a.Duration,
+	} {
+		if e.changed(field) {
+			updates[field] = value
+		}
+	}
+	if e.changed("category") {
+		updates["category_id"] = categoryID
+	}
+	if e.changed("show") {
+		updates["show_id"] = showID
+	}
+	if e.changed("audio") {
+		updates["audio_url"], updates["audio_hash"], updates["file_size"] = "", "", int64(0)
+		if file != nil {
+			updates["audio_url"], updates["audio_hash"], updates["file_size"] = file.Path, file.Hash, file.Size
+			if file.Duration > 0 {
+				updates["duration"] = file.Duration
+			}
+		}
+	}
+	if e.changed("thumbnail") {
+		setImage(updates, "thumbnail", thumbnail, true)
+	}
+
+	if err := s.restore(e, &audioModel.Audio{}); err != nil {
+		s.cleanup.Release(assetKeys(file, thumbnail)...)
+		return err
+	}
+	if len(updates) > 0 {
+		if _, err := s.audios.Update(e.ID, adminID, updates, audioService.Schedule{}); err != nil {
+			return err
+		}
+	}
+	if e.changed("tags") {
+		if err := s.audios.SetTags(e.ID, tagged); err != nil {
+			return err
+		}
+	}
+	if file != nil {
+		s.transcoder.Enqueue(e.ID)
+	}
+	return nil
+}
+
+// restore takes a trashed record out of the trash before it is updated.
+func (s *CatalogService) restore(e *entry, model interface{}) error {
+	if !e.Trashed {
+		return nil
+	}
+	if err := s.db.Unscoped().Model(model).Where("id = ?", e.ID).Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	e.Trashed = false
+	return nil
+}
+
+// ingestPair stores two optional images, releasing the first if the second
+// fails.
+func (s *CatalogService) ingestPair(p *plan, first, second *catalogModel.Media, subdir string) (*media.Asset, *media.Asset, error) {
+	var a, b *media.Asset
+	var err error
+	if first != nil {
+		if a, err = s.ingestMedia(p, first, media.KindImage, subdir); err != nil {
+			return nil, nil, err
+		}
+	}
+	if second != nil {
+		if b, err = s.ingestMedia(p, second, media.KindImage, subdir); err != nil {
+			s.cleanup.Release(assetKeys(a)...)
+			return nil, nil, err
+		}
+	}
+	return a, b, nil
+}
+
+// ingestMedia stores a file from the archive under uploads/<subdir>, the
+// folder of the field it is imported into; paths inside the archive are
+// never used. An empty subdir uses the kind's default folder.
+func (s *CatalogService) ingestMedia(p *plan, ref *catalogModel.Media, kind media.Kind, subdir string) (*media.Asset, error) {
+	f, ok := p.files[ref.File]
+	if !ok {
+		return nil, fmt.Errorf("%s is missing from the archive", ref.File)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return s.ingest.Ingest(kind, subdir, r)
+}
+
+// setImage sets the columns of an image field, clearing them when asset is
+// nil.
+func setImage(updates map[string]interface{}, column string, asset *media.Asset, palette bool) {
+	if asset == nil {
+		updates[column], updates[column+"_hash"] = "", ""
+		updates[column+"_variants"] = mediaModel.ImageVariants{}
+		if palette {
+			updates["palette"] = (*mediaModel.ColorPalette)(nil)
+		}
+		return
+	}
+	updates[column], updates[column+"_hash"] = asset.Path, asset.Hash
+	updates[column+"_variants"] = asset.Variants
+	if palette {
+		updates["palette"] = asset.Palette
+	}
+}
+
+func assetKeys(assets ...*media.Asset) []string {
+	var keys []string
+	for _, asset := range assets {
+		if asset != nil {
+			keys = append(append(keys, asset.Path), asset.Variants.Keys()...)
+		}
+	}
+	return keys
+}
+
+func resolve(ids map[string]uint, kind, ext string) (*uint, error) {
+	if ext == "" {
+		return nil, nil
+	}
+	id, ok := ids[ext]
+	if !ok {
+		return nil, fmt.Errorf("%s %s was not imported", kind, ext)
+	}
+	return &id, nil
+}
+
+func sortedTags(tags []string) []string {
+	sorted := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if t = strings.TrimSpace(t); t != "" {
+			sorted = append(sorted, t)
+		}
+	}
+	sort.Strings(sorted)
+	return sorted
+}
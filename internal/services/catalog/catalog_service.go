@@ -0,0 +1,328 @@
+package catalog
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	catalogModel "mqfm-backend/internal/models/catalog"
+	categoryModel "mqfm-backend/internal/models/category/admin"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	showModel "mqfm-backend/internal/models/podcast/show"
+	categoryService "mqfm-backend/internal/services/category/admin"
+	jobService "mqfm-backend/internal/services/job"
+	"mqfm-backend/internal/services/media"
+	audioService "mqfm-backend/internal/services/podcast/audio/admin"
+	showService "mqfm-backend/internal/services/podcast/show"
+	"mqfm-backend/internal/services/podcast/transcode"
+	tagService "mqfm-backend/internal/services/tag"
+	"mqfm-backend/internal/storage"
+	"mqfm-backend/internal/utils"
+)
+
+// CatalogService moves categories, shows and audios between environments as
+// a ZIP archive of a catalog.json manifest and the media files it uses.
+type CatalogService struct {
+	db         *gorm.DB
+	store      storage.Storage
+	ingest     *media.IngestService
+	cleanup    *media.CleanupService
+	jobs       *jobService.JobService
+	categories *categoryService.AdminCategoryService
+	shows      *showService.ShowService
+	audios     *audioService.AdminAudioService
+	tags       *tagService.TagService
+	transcoder *transcode.TranscodeService
+	maxSize    int64
+}
+
+func NewCatalogService(
+	db *gorm.DB,
+	store storage.Storage,
+	ingest *media.IngestService,
+	cleanup *media.CleanupService,
+	jobs *jobService.JobService,
+	categories *categoryService.AdminCategoryService,
+	shows *showService.ShowService,
+	audios *audioService.AdminAudioService,
+	tags *tagService.TagService,
+	transcoder *transcode.TranscodeService,
+	maxSize int64,
+) *CatalogService {
+	return &CatalogService{
+		db:         db,
+		store:      store,
+		ingest:     ingest,
+		cleanup:    cleanup,
+		jobs:       jobs,
+		categories: categories,
+		shows:      shows,
+		audios:     audios,
+		tags:       tags,
+		transcoder: transcoder,
+		maxSize:    maxSize,
+	}
+}
+
+// BackfillExternalIDs gives records created before external IDs existed one
+// of their own.
+func (s *CatalogService) BackfillExternalIDs() error {
+	for _, table := range []string{"categories", "shows", "audios"} {
+		var ids []uint
+		if err := s.db.Table(table).Where("external_id IS NULL OR external_id = ''").Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := s.db.Table(table).Where("id = ?", id).Update("external_id", uuid.New().String()).Error; err != nil {
+				return err
+			}
+		}
+		if len(ids) > 0 {
+			utils.Log.Info("[Catalog] External IDs backfilled", zap.String("table", table), zap.Int("count", len(ids)))
+		}
+	}
+	return nil
+}
+
+// Export writes the live catalogue to w as a ZIP archive. Without withMedia
+// only the manifest is written; an import then keeps the media it already
+// has and refuses records whose media it lacks.
+func (s *CatalogService) Export(w io.Writer, withMedia bool) error {
+	snap, err := s.snapshot(false)
+	if err != nil {
+		return err
+	}
+
+	manifest := catalogModel.Manifest{
+		Version:    catalogModel.FormatVersion,
+		ExportedAt: time.Now().UTC(),
+		Categories: []catalogModel.Category{},
+		Shows:      []catalogModel.Show{},
+		Audios:     []catalogModel.Audio{},
+	}
+	for _, c := range parentsFirst(snap.categories) {
+		manifest.Categories = append(manifest.Categories, snap.category(c))
+	}
+	for _, sh := range snap.shows {
+		manifest.Shows = append(manifest.Shows, snap.show(sh))
+	}
+	for _, a := range snap.audios {
+		manifest.Audios = append(manifest.Audios, snap.audio(a))
+	}
+
+	archive := zip.NewWriter(w)
+
+	// Media goes first so files missing from storage can be dropped from the
+	// manifest before it is written.
+	written := make(map[string]bool)
+	for _, m := range manifestMedia(&manifest) {
+		if !withMedia {
+			m.File = ""
+			continue
+		}
+		if written[m.File] {
+			continue
+		}
+		if err := s.writeMedia(archive, m.File); err != nil {
+			utils.Log.Warn("[Catalog] Media left out of export", zap.Error(err), zap.String("file", m.File))
+			m.File = ""
+			continue
+		}
+		written[m.File] = true
+	}
+
+	out, err := archive.Create(catalogModel.ManifestName)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func (s *CatalogService) writeMedia(archive *zip.Writer, file string) error {
+	src, err := s.store.Get(strings.TrimPrefix(file, "media/"))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// Media is already compressed, so it is stored as is.
+	dst, err := archive.CreateHeader(&zip.FileHeader{Name: file, Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// snapshot holds the catalogue as loaded from the database along with the
+// external IDs records use to refer to each other.
+type snapshot struct {
+	categories    []categoryModel.Category
+	shows         []showModel.Show
+	audios        []audioModel.Audio
+	categoryExtID map[uint]string
+	showExtID     map[uint]string
+}
+
+// snapshot loads the live catalogue, plus the trashed records when
+// withTrashed is set.
+func (s *CatalogService) snapshot(withTrashed bool) (*snapshot, error) {
+	query := s.db
+	if withTrashed {
+		query = s.db.Unscoped().Session(&gorm.Session{})
+	}
+	snap := &snapshot{categoryExtID: map[uint]string{}, showExtID: map[uint]string{}}
+	if err := query.Order("id").Find(&snap.categories).Error; err != nil {
+		return nil, err
+	}
+	if err := query.Order("id").Find(&snap.shows).Error; err != nil {
+		return nil, err
+	}
+	if err := query.Preload("Tags").Order("id").Find(&snap.audios).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range snap.categories {
+		snap.categoryExtID[c.ID] = c.ExternalID
+	}
+	for _, sh := range snap.shows {
+		snap.showExtID[sh.ID] = sh.ExternalID
+	}
+	return snap, nil
+}
+
+func (snap *snapshot) ref(ids map[uint]string, id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return ids[*id]
+}
+
+func (snap *snapshot) category(c categoryModel.Category) catalogModel.Category {
+	return catalogModel.Category{
+		ExternalID:  c.ExternalID,
+		Name:        c.Name,
+		Slug:        c.Slug,
+		Description: c.Description,
+		Parent:      snap.ref(snap.categoryExtID, c.ParentID),
+		Position:    c.Position,
+		Icon:        mediaRef(c.Icon, c.IconHash),
+		Cover:       mediaRef(c.Cover, c.CoverHash),
+	}
+}
+
+func (snap *snapshot) show(sh showModel.Show) catalogModel.Show {
+	return catalogModel.Show{
+		ExternalID:  sh.ExternalID,
+		Title:       sh.Title,
+		Description: sh.Description,
+		Hosts:       sh.Hosts,
+		Category:    snap.ref(snap.categoryExtID, sh.CategoryID),
+		Artwork:     mediaRef(sh.Artwork, sh.ArtworkHash),
+	}
+}
+
+func (snap *snapshot) audio(a audioModel.Audio) catalogModel.Audio {
+	var tags []string
+	for _, t := range a.Tags {
+		tags = append(tags, t.Name)
+	}
+	return catalogModel.Audio{
+		ExternalID:    a.ExternalID,
+		Title:         a.Title,
+		Description:   a.Description,
+		Category:      snap.ref(snap.categoryExtID, a.CategoryID),
+		Show:          snap.ref(snap.showExtID, a.ShowID),
+		SeasonNumber:  a.SeasonNumber,
+		EpisodeNumber: a.EpisodeNumber,
+		Status:        a.Status,
+		PublishAt:     utc(a.PublishAt),
+		UnpublishAt:   utc(a.UnpublishAt),
+		PublishedAt:   utc(a.PublishedAt),
+		Duration:      a.Duration,
+		Tags:          sortedTags(tags),
+		Audio:         mediaRef(a.AudioURL, a.AudioHash),
+		Thumbnail:     mediaRef(a.Thumbnail, a.ThumbnailHash),
+	}
+}
+
+// mediaRef describes a stored file. Files stored before hashes were recorded
+// fall back to their name, which is the hash for content-addressed keys.
+func mediaRef(key, hash string) *catalogModel.Media {
+	key = media.NormalizeKey(key)
+	if key == "" {
+		return nil
+	}
+	if hash == "" {
+		hash = strings.TrimSuffix(path.Base(key), path.Ext(key))
+	}
+	return &catalogModel.Media{File: "media/" + key, Hash: hash}
+}
+
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// manifestMedia returns every media reference in the manifest.
+func manifestMedia(m *catalogModel.Manifest) []*catalogModel.Media {
+	var refs []*catalogModel.Media
+	add := func(ref *catalogModel.Media) {
+		if ref != nil {
+			refs = append(refs, ref)
+		}
+	}
+	for i := range m.Categories {
+		add(m.Categories[i].Icon)
+		add(m.Categories[i].Cover)
+	}
+	for i := range m.Shows {
+		add(m.Shows[i].Artwork)
+	}
+	for i := range m.Audios {
+		add(m.Audios[i].Audio)
+		add(m.Audios[i].Thumbnail)
+	}
+	return refs
+}
+
+// parentsFirst orders categories so each comes after its parent.
+func parentsFirst(categories []categoryModel.Category) []categoryModel.Category {
+	children := make(map[uint][]categoryModel.Category)
+	known := make(map[uint]bool, len(categories))
+	for _, c := range categories {
+		known[c.ID] = true
+	}
+	var roots []categoryModel.Category
+	for _, c := range categories {
+		if c.ParentID == nil || !known[*c.ParentID] {
+			roots = append(roots, c)
+			continue
+		}
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	ordered := make([]categoryModel.Category, 0, len(categories))
+	queue := roots
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		ordered = append(ordered, c)
+		queue = append(queue, children[c.ID]...)
+	}
+	return ordered
+}
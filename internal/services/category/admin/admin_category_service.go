@@ -1,75 +1,472 @@
-package admin
-
-import (
-	"errors"
-
-	"gorm.io/gorm"
-
-	categoryModel "mqfm-backend/internal/models/category/admin"
-
-)
-
-type AdminCategoryService struct {
-	db *gorm.DB
-}
-
-func NewAdminCategoryService(db *gorm.DB) *AdminCategoryService {
-	return &AdminCategoryService{db: db}
-}
-
-func (s *AdminCategoryService) Create(category *categoryModel.Category) error {
-	return s.db.Create(category).Error
-}
-
-func (s *AdminCategoryService) FindAll() ([]categoryModel.Category, error) {
-	var categories []categoryModel.Category
-	if err := s.db.Find(&categories).Error; err != nil {
-		return nil, err
-	}
-	return categories, nil
-}
-
-func (s *AdminCategoryService) FindByID(id uint) (*categoryModel.Category, error) {
-	var category categoryModel.Category
-	if err := s.db.First(&category, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("category not found")
-		}
-		return nil, err
-	}
-	return &category, nil
-}
-
-func (s *AdminCategoryService) Update(id uint, updates map[string]interface{}) (*categoryModel.Category, error) {
-	if err := s.db.Model(&categoryModel.Category{}).Where("id = ?", id).Updates(updates).Error; err != nil {
-		return nil, err
-	}
-
-	var updatedCategory categoryModel.Category
-	if err := s.db.First(&updatedCategory, id).Error; err != nil {
-		return nil, err
-	}
-
-	return &updatedCategory, nil
-}
-
-func (s *AdminCategoryService) Delete(id uint) error {
-	var category categoryModel.Category
-	if err := s.db.First(&category, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("category not found")
-		}
-		return err
-	}
-
-	return s.db.Delete(&category).Error
-}
-
-func (s *AdminCategoryService) Search(query string) ([]categoryModel.Category, error) {
-	var categories []categoryModel.Category
-	searchQuery := "%" + query + "%"
-	if err := s.db.Where("name LIKE ? OR description LIKE ?", searchQuery, searchQuery).Find(&categories).Error; err != nil {
-		return nil, err
-	}
-	return categories, nil
-}
\ No newline at end of file
+package admin
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	categoryModel "mqfm-backend/internal/models/category/admin"
+	mediaModel "mqfm-backend/internal/models/media"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	showModel "mqfm-backend/internal/models/podcast/show"
+	revisionModel "mqfm-backend/internal/models/revision"
+	"mqfm-backend/internal/services/media"
+	revisionService "mqfm-backend/internal/services/revision"
+	"mqfm-backend/internal/utils"
+
+)
+
+// categoryOrder lists siblings as they are meant to be displayed.
+const categoryOrder = "position, name"
+
+var (
+	// ErrInvalidParent is returned when a category would become its own
+	// ancestor or its parent does not exist.
+	ErrInvalidParent = errors.New("invalid parent category")
+	// ErrCategoryInUse is returned when deleting a category that audios or
+	// shows still belong to without naming a category to move them to.
+	ErrCategoryInUse = errors.New("category still has audios or shows; choose a category to reassign them to")
+	// ErrInvalidTarget is returned when audios would be moved to a category
+	// that doesn't exist or to the one they are leaving.
+	ErrInvalidTarget = errors.New("invalid target category")
+)
+
+type AdminCategoryService struct {
+	db        *gorm.DB
+	cleanup   *media.CleanupService
+	revisions *revisionService.RevisionService
+}
+
+func NewAdminCategoryService(db *gorm.DB, cleanup *media.CleanupService, revisions *revisionService.RevisionService) *AdminCategoryService {
+	return &AdminCategoryService{db: db, cleanup: cleanup, revisions: revisions}
+}
+
+// Create derives the slug from the name unless one is given.
+func (s *AdminCategoryService) Create(category *categoryModel.Category) error {
+	err := s.checkParent(0, category.ParentID)
+	if err == nil {
+		category.Slug, err = s.uniqueSlug(category.Slug, category.Name, 0)
+	}
+	if err == nil {
+		err = s.db.Create(category).Error
+	}
+	if err != nil {
+		s.cleanup.Release(imageKeys(*category)...)
+		return err
+	}
+	return nil
+}
+
+func (s *AdminCategoryService) FindAll() ([]categoryModel.Category, error) {
+	var categories []categoryModel.Category
+	if err := s.db.Order(categoryOrder).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (s *AdminCategoryService) FindByID(id uint) (*categoryModel.Category, error) {
+	var category categoryModel.Category
+	if err := s.db.First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("category not found")
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (s *AdminCategoryService) FindBySlug(slug string) (*categoryModel.Category, error) {
+	var category categoryModel.Category
+	if err := s.db.Where("slug = ?", slug).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("category not found")
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Tree returns the categories nested under their parents, each with the
+// number of public audios filed directly under it and under its subtree.
+func (s *AdminCategoryService) Tree() ([]categoryModel.CategoryNode, error) {
+	categories, err := s.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		CategoryID uint
+		Count      int64
+	}
+	if err := s.db.Model(&audioModel.Audio{}).Scopes(audioModel.Listed).
+		Select("category_id, COUNT(*) AS count").
+		Where("category_id IS NOT NULL").
+		Group("category_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	direct := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		direct[c.CategoryID] = c.Count
+	}
+
+	known := make(map[uint]bool, len(categories))
+	for _, c := range categories {
+		known[c.ID] = true
+	}
+	children := make(map[uint][]categoryModel.Category)
+	var roots []categoryModel.Category
+	for _, c := range categories {
+		// A parent that no longer exists leaves its children at the top.
+		if c.ParentID == nil || !known[*c.ParentID] {
+			roots = append(roots, c)
+			continue
+		}
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	var build func(c categoryModel.Category) categoryModel.CategoryNode
+	build = func(c categoryModel.Category) categoryModel.CategoryNode {
+		node := categoryModel.CategoryNode{
+			Category:        c,
+			AudioCount:      direct[c.ID],
+			TotalAudioCount: direct[c.ID],
+			Children:        []categoryModel.CategoryNode{},
+		}
+		for _, child := range children[c.ID] {
+			childNode := build(child)
+			node.TotalAudioCount += childNode.TotalAudioCount
+			node.Children = append(node.Children, childNode)
+		}
+		return node
+	}
+
+	tree := make([]categoryModel.CategoryNode, 0, len(roots))
+	for _, c := range roots {
+		tree = append(tree, build(c))
+	}
+	return tree, nil
+}
+
+// Update accepts parent_id as a *uint, nil moving the category to the top
+// level, and a slug that is made unique before saving.
+// Update applies updates and records the edit in the category's revision
+// history under actorID.
+func (s *AdminCategoryService) Update(id, actorID uint, updates map[string]interface{}) (*categoryModel.Category, error) {
+	return s.update(id, actorID, updates, nil)
+}
+
+func (s *AdminCategoryService) update(id, actorID uint, updates map[string]interface{}, revertedTo *int) (*categoryModel.Category, error) {
+	current, err := s.FindByID(id)
+	if err == nil {
+		if parentID, ok := updates["parent_id"].(*uint); ok {
+			err = s.checkParent(id, parentID)
+		}
+	}
+	if err == nil {
+		if slug, ok := updates["slug"].(string); ok {
+			updates["slug"], err = s.uniqueSlug(slug, current.Name, id)
+		}
+	}
+	if err == nil {
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&categoryModel.Category{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+				return err
+			}
+			var saved categoryModel.Category
+			if err := tx.First(&saved, id).Error; err != nil {
+				return err
+			}
+			return s.revisions.Record(tx, revisionModel.EntityCategory, id, actorID, revisionFields(*current), revisionFields(saved), revertedTo)
+		})
+	}
+	if err != nil {
+		s.cleanup.Release(updateImageKeys(updates)...)
+		return nil, err
+	}
+
+	var replaced []string
+	if _, ok := updates["icon"]; ok {
+		replaced = append(replaced, current.Icon)
+		replaced = append(replaced, current.IconVariants.Keys()...)
+	}
+	if _, ok := updates["cover"]; ok {
+		replaced = append(replaced, current.Cover)
+		replaced = append(replaced, current.CoverVariants.Keys()...)
+	}
+	s.cleanup.Release(replaced...)
+
+	return s.FindByID(id)
+}
+
+// revisionFields are the category fields kept in its revision history.
+// Images are left out since replaced ones are deleted, and so is the position,
+// which Reorder manages across siblings.
+func revisionFields(c categoryModel.Category) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        c.Name,
+		"slug":        c.Slug,
+		"description": c.Description,
+		"parent_id":   c.ParentID,
+	}
+}
+
+// Revisions returns the category's revision history, newest first.
+func (s *AdminCategoryService) Revisions(id uint) ([]revisionModel.Revision, error) {
+	if _, err := s.FindByID(id); err != nil {
+		return nil, err
+	}
+	return s.revisions.History(revisionModel.EntityCategory, id)
+}
+
+// DiffRevisions lists the fields that differ between two revisions.
+func (s *AdminCategoryService) DiffRevisions(id uint, from, to int) (revisionModel.Changes, error) {
+	return s.revisions.Diff(revisionModel.EntityCategory, id, from, to)
+}
+
+// Revert sets the category's tracked fields back to how they were in
+// revision number. The revert is recorded as a new revision.
+func (s *AdminCategoryService) Revert(id uint, number int, actorID uint) (*categoryModel.Category, error) {
+	revision, err := s.revisions.Find(revisionModel.EntityCategory, id, number)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := revision.Snapshot.Decode(target); err != nil {
+		return nil, err
+	}
+
+	return s.update(id, actorID, revisionFields(*target), &number)
+}
+
+// Reorder sets the position of each category to its index in ids.
+func (s *AdminCategoryService) Reorder(ids []uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			result := tx.Model(&categoryModel.Category{}).Where("id = ?", id).Update("position", i+1)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("category %d not found", id)
+			}
+		}
+		return nil
+	})
+}
+
+// Delete moves the category to the trash. Its subcategories move up to its
+// parent. When
+// audios or shows still belong to it, reassignTo must name the category they
+// move to, or the delete is refused with ErrCategoryInUse.
+func (s *AdminCategoryService) Delete(id uint, reassignTo *uint) error {
+	var category categoryModel.Category
+	if err := s.db.First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("category not found")
+		}
+		return err
+	}
+
+	if reassignTo == nil {
+		inUse, err := s.inUse(id)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return ErrCategoryInUse
+		}
+	} else if err := s.checkTarget(id, *reassignTo); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Trashed audios move too, so nothing is left pointing at the
+		// deleted row.
+		for _, model := range []interface{}{&audioModel.Audio{}, &showModel.Show{}} {
+			if err := tx.Unscoped().Model(model).Where("category_id = ?", id).Update("category_id", reassignTo).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&categoryModel.Category{}).Where("parent_id = ?", id).Update("parent_id", category.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&category).Error
+	})
+}
+
+// MoveAudios moves audios from one category to another in a single
+// transaction: the listed audioIDs, or every audio in the category when none
+// are given. It returns how many were moved.
+func (s *AdminCategoryService) MoveAudios(fromID, toID uint, audioIDs []uint) (int64, error) {
+	if _, err := s.FindByID(fromID); err != nil {
+		return 0, err
+	}
+	if err := s.checkTarget(fromID, toID); err != nil {
+		return 0, err
+	}
+
+	var moved int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&audioModel.Audio{}).Where("category_id = ?", fromID)
+		if len(audioIDs) > 0 {
+			query = query.Where("id IN ?", audioIDs)
+		}
+		result := query.Update("category_id", toID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(audioIDs) > 0 && result.RowsAffected != int64(len(audioIDs)) {
+			return fmt.Errorf("%w: only %d of %d audios are in category %d", ErrInvalidTarget, result.RowsAffected, len(audioIDs), fromID)
+		}
+		moved = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if moved > 0 {
+		utils.Log.Info("[Category] Audios moved",
+			zap.Uint("from", fromID),
+			zap.Uint("to", toID),
+			zap.Int64("count", moved),
+		)
+	}
+	return moved, nil
+}
+
+// inUse reports whether any audio or show still belongs to the category.
+func (s *AdminCategoryService) inUse(id uint) (bool, error) {
+	for _, model := range []interface{}{&audioModel.Audio{}, &showModel.Show{}} {
+		var count int64
+		if err := s.db.Model(model).Where("category_id = ?", id).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkTarget makes sure audios can move from fromID to toID.
+func (s *AdminCategoryService) checkTarget(fromID, toID uint) error {
+	if toID == fromID {
+		return fmt.Errorf("%w: audios are already in this category", ErrInvalidTarget)
+	}
+	if _, err := s.FindByID(toID); err != nil {
+		return fmt.Errorf("%w: category %d not found", ErrInvalidTarget, toID)
+	}
+	return nil
+}
+
+// Discard removes freshly stored files that did not end up on any category.
+func (s *AdminCategoryService) Discard(keys ...string) {
+	s.cleanup.Release(keys...)
+}
+
+func (s *AdminCategoryService) Search(query string) ([]categoryModel.Category, error) {
+	var categories []categoryModel.Category
+	searchQuery := "%" + query + "%"
+	if err := s.db.Where("name LIKE ? OR description LIKE ?", searchQuery, searchQuery).Order(categoryOrder).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// BackfillSlugs gives categories created before slugs existed one derived from
+// their name.
+func (s *AdminCategoryService) BackfillSlugs() error {
+	var categories []categoryModel.Category
+	if err := s.db.Where("slug IS NULL OR slug = ''").Find(&categories).Error; err != nil {
+		return err
+	}
+
+	for _, c := range categories {
+		slug, err := s.uniqueSlug("", c.Name, c.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.db.Model(&categoryModel.Category{}).Where("id = ?", c.ID).Update("slug", slug).Error; err != nil {
+			return err
+		}
+	}
+	if len(categories) > 0 {
+		utils.Log.Info("[Category] Slugs backfilled", zap.Int("count", len(categories)))
+	}
+	return nil
+}
+
+// uniqueSlug slugifies slug, or name when slug is empty, and appends -2, -3...
+// until no other category uses it. The column is not a unique index so that
+// rows from before slugs existed could be migrated.
+func (s *AdminCategoryService) uniqueSlug(slug, name string, exceptID uint) (string, error) {
+	base := utils.Slugify(slug)
+	if base == "" {
+		base = utils.Slugify(name)
+	}
+	if base == "" {
+		base = "category"
+	}
+
+	candidate := base
+	for n := 2; ; n++ {
+		var count int64
+		if err := s.db.Unscoped().Model(&categoryModel.Category{}).
+			Where("slug = ? AND id <> ?", candidate, exceptID).
+			Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// checkParent makes sure parentID exists and is not id or one of its
+// descendants. A nil parentID is always valid.
+func (s *AdminCategoryService) checkParent(id uint, parentID *uint) error {
+	for next := parentID; next != nil; {
+		if id != 0 && *next == id {
+			return fmt.Errorf("%w: a category cannot be placed under itself", ErrInvalidParent)
+		}
+		var parent categoryModel.Category
+		if err := s.db.Select("id", "parent_id").First(&parent, *next).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: category %d not found", ErrInvalidParent, *next)
+			}
+			return err
+		}
+		next = parent.ParentID
+	}
+	return nil
+}
+
+func imageKeys(c categoryModel.Category) []string {
+	keys := append(c.IconVariants.Keys(), c.Icon, c.Cover)
+	return append(keys, c.CoverVariants.Keys()...)
+}
+
+// updateImageKeys returns the storage keys carried by an update map.
+func updateImageKeys(updates map[string]interface{}) []string {
+	var keys []string
+	for _, column := range []string{"icon", "cover"} {
+		if key, ok := updates[column].(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	for _, column := range []string{"icon_variants", "cover_variants"} {
+		if variants, ok := updates[column].(mediaModel.ImageVariants); ok {
+			keys = append(keys, variants.Keys()...)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,450 @@
+package duplicate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	mediaModel "mqfm-backend/internal/models/media"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	jobService "mqfm-backend/internal/services/job"
+	"mqfm-backend/internal/services/media"
+	"mqfm-backend/internal/storage"
+	"mqfm-backend/internal/utils"
+)
+
+const JobTypeHashBackfill = "hash_backfill"
+
+// hashColumn pairs a file column with the column holding its content hash,
+// and for images the JSON column holding its resized variants.
+type hashColumn struct {
+	Table          string
+	Column         string
+	HashColumn     string
+	VariantsColumn string
+	// NoTrash marks tables whose rows are deleted outright, without a
+	// deleted_at column.
+	NoTrash bool
+}
+
+// rows selects the column's table, leaving out trashed rows.
+func (c hashColumn) rows(db *gorm.DB) *gorm.DB {
+	if c.NoTrash {
+		return db.Table(c.Table)
+	}
+	return db.Table(c.Table).Where("deleted_at IS NULL")
+}
+
+var (
+	audioColumn  = hashColumn{Table: "audios", Column: "audio_url", HashColumn: "audio_hash"}
+	imageColumns = []hashColumn{
+		{Table: "audios", Column: "thumbnail", HashColumn: "thumbnail_hash", VariantsColumn: "thumbnail_variants"},
+		{Table: "audio_chapters", Column: "image", HashColumn: "image_hash", VariantsColumn: "image_variants", NoTrash: true},
+		{Table: "categories", Column: "icon", HashColumn: "icon_hash", VariantsColumn: "icon_variants"},
+		{Table: "categories", Column: "cover", HashColumn: "cover_hash", VariantsColumn: "cover_variants"},
+		{Table: "playlists", Column: "image_url", HashColumn: "image_hash", VariantsColumn: "image_variants"},
+		{Table: "shows", Column: "artwork", HashColumn: "artwork_hash", VariantsColumn: "artwork_variants"},
+		{Table: "speakers", Column: "photo", HashColumn: "photo_hash", VariantsColumn: "photo_variants"},
+		{Table: "users", Column: "profile_picture", HashColumn: "profile_picture_hash", VariantsColumn: "profile_picture_variants"},
+	}
+)
+
+// AudioGroup is a set of audios whose source files have identical content.
+type AudioGroup struct {
+	Hash   string             `json:"hash"`
+	Audios []audioModel.Audio `json:"audios"`
+	// WastedBytes is the space used by stored copies beyond the first.
+	WastedBytes int64 `json:"wasted_bytes"`
+}
+
+// ImageGroup is an image stored under more than one key.
+type ImageGroup struct {
+	Hash       string   `json:"hash"`
+	Keys       []string `json:"keys"`
+	References int      `json:"references"`
+}
+
+// BackfillResult summarises a hash backfill run.
+type BackfillResult struct {
+	Hashed  int      `json:"hashed"`
+	Missing []string `json:"missing"`
+	Failed  int      `json:"failed"`
+}
+
+type DuplicateService struct {
+	db      *gorm.DB
+	store   storage.Storage
+	cleanup *media.CleanupService
+	jobs    *jobService.JobService
+}
+
+func NewDuplicateService(db *gorm.DB, store storage.Storage, cleanup *media.CleanupService, jobs *jobService.JobService) *DuplicateService {
+	return &DuplicateService{db: db, store: store, cleanup: cleanup, jobs: jobs}
+}
+
+// AudioGroups lists every content hash shared by more than one audio.
+func (s *DuplicateService) AudioGroups() ([]AudioGroup, error) {
+	var hashes []string
+	if err := s.db.Model(&audioModel.Audio{}).
+		Where("audio_hash <> ''").
+		Group("audio_hash").
+		Having("COUNT(*) > 1").
+		Order("audio_hash").
+		Pluck("audio_hash", &hashes).Error; err != nil {
+		return nil, err
+	}
+
+	groups := make([]AudioGroup, 0, len(hashes))
+	for _, hash := range hashes {
+		var audios []audioModel.Audio
+		if err := s.db.Where("audio_hash = ?", hash).Order("id").Find(&audios).Error; err != nil {
+			return nil, err
+		}
+
+		group := AudioGroup{Hash: hash, Audios: audios}
+		seen := make(map[string]bool)
+		for _, a := range audios {
+			key := media.NormalizeKey(a.AudioURL)
+			if seen[key] {
+				continue
+			}
+			if len(seen) > 0 {
+				group.WastedBytes += a.FileSize
+			}
+			seen[key] = true
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// ImageGroups lists images whose content is stored under several keys, across
+// thumbnails, chapter images, category icons and covers, playlist covers, show
+// artwork, speaker photos and profile pictures.
+func (s *DuplicateService) ImageGroups() ([]ImageGroup, error) {
+	keys := make(map[string]map[string]bool)
+	refs := make(map[string]int)
+	for _, col := range imageColumns {
+		var rows []struct {
+			Hash   string
+			RefKey string
+		}
+		if err := col.rows(s.db).
+			Select(col.HashColumn + " AS hash, " + col.Column + " AS ref_key").
+			Where(col.HashColumn + " <> ''").
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			if keys[row.Hash] == nil {
+				keys[row.Hash] = make(map[string]bool)
+			}
+			keys[row.Hash][media.NormalizeKey(row.RefKey)] = true
+			refs[row.Hash]++
+		}
+	}
+
+	groups := []ImageGroup{}
+	for hash, set := range keys {
+		if len(set) < 2 {
+			continue
+		}
+		group := ImageGroup{Hash: hash, References: refs[hash]}
+		for key := range set {
+			group.Keys = append(group.Keys, key)
+		}
+		sort.Strings(group.Keys)
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Hash < groups[j].Hash })
+	return groups, nil
+}
+
+// MergeAudios folds duplicates into keepID: playlist entries, likes and
+// transcripts move to the kept audio, the others are deleted and their files
+// released.
+func (s *DuplicateService) MergeAudios(keepID uint, mergeIDs []uint) (*audioModel.Audio, error) {
+	var keep audioModel.Audio
+	if err := s.db.First(&keep, keepID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("audio to keep not found")
+		}
+		return nil, err
+	}
+	if keep.AudioHash == "" {
+		return nil, errors.New("audio to keep has no content hash; run the hash backfill first")
+	}
+
+	var merged []audioModel.Audio
+	if err := s.db.Where("id IN ? AND id <> ?", mergeIDs, keepID).Find(&merged).Error; err != nil {
+		return nil, err
+	}
+	if len(merged) == 0 {
+		return nil, errors.New("no audios to merge")
+	}
+	for _, a := range merged {
+		if a.AudioHash != keep.AudioHash {
+			return nil, fmt.Errorf("audio %d does not have the same content", a.ID)
+		}
+	}
+
+	var released []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, a := range merged {
+			// Move playlist entries unless the playlist already has the kept audio.
+			if err := tx.Exec(`INSERT INTO playlist_audios (playlist_id, audio_id)
+				SELECT playlist_id, ? FROM playlist_audios
+				WHERE audio_id = ? AND playlist_id NOT IN (SELECT playlist_id FROM playlist_audios WHERE audio_id = ?)`,
+				keep.ID, a.ID, keep.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM playlist_audios WHERE audio_id = ?", a.ID).Error; err != nil {
+				return err
+			}
+
+			// Same for likes; a user who liked both keeps a single like.
+			if err := tx.Exec(`UPDATE likes SET audio_id = ?
+				WHERE audio_id = ? AND user_id NOT IN (SELECT user_id FROM likes WHERE audio_id = ?)`,
+				keep.ID, a.ID, keep.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM likes WHERE audio_id = ?", a.ID).Error; err != nil {
+				return err
+			}
+
+			// Transcripts move in the languages the kept audio has none in.
+			if err := tx.Exec(`UPDATE transcript_cues SET audio_id = ?
+				WHERE transcript_id IN (SELECT id FROM audio_transcripts
+					WHERE audio_id = ? AND language NOT IN (SELECT language FROM audio_transcripts WHERE audio_id = ?))`,
+				keep.ID, a.ID, keep.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`UPDATE audio_transcripts SET audio_id = ?
+				WHERE audio_id = ? AND language NOT IN (SELECT language FROM audio_transcripts WHERE audio_id = ?)`,
+				keep.ID, a.ID, keep.ID).Error; err != nil {
+				return err
+			}
+
+			// Chapters move when the kept audio has none; the content is the same.
+			if err := tx.Exec(`UPDATE audio_chapters SET audio_id = ?
+				WHERE audio_id = ? AND NOT EXISTS (SELECT 1 FROM audio_chapters WHERE audio_id = ?)`,
+				keep.ID, a.ID, keep.ID).Error; err != nil {
+				return err
+			}
+
+			var renditions []audioModel.AudioRendition
+			if err := tx.Where("audio_id = ?", a.ID).Find(&renditions).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("audio_id = ?", a.ID).Delete(&audioModel.AudioRendition{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&audioModel.Audio{}, a.ID).Error; err != nil {
+				return err
+			}
+
+			released = append(released, a.AudioURL, a.Thumbnail)
+			released = append(released, a.ThumbnailVariants.Keys()...)
+			for _, r := range renditions {
+				released = append(released, r.URL)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.cleanup.Release(released...)
+
+	utils.Log.Info("[Duplicate] Audios merged",
+		zap.Uint("keep_id", keep.ID),
+		zap.Int("merged", len(merged)),
+	)
+
+	if err := s.db.Preload("Renditions").First(&keep, keep.ID).Error; err != nil {
+		return nil, err
+	}
+	return &keep, nil
+}
+
+// MergeImage points every reference to an image hash at a single key, along
+// with the variants made for it, and releases the other copies.
+func (s *DuplicateService) MergeImage(hash string) (*ImageGroup, error) {
+	groups, err := s.ImageGroups()
+	if err != nil {
+		return nil, err
+	}
+	var group *ImageGroup
+	for i := range groups {
+		if groups[i].Hash == hash {
+			group = &groups[i]
+			break
+		}
+	}
+	if group == nil {
+		return nil, errors.New("no duplicate image group for this hash")
+	}
+
+	// Prefer a content-addressed key so future uploads land on the same object.
+	canonical := group.Keys[0]
+	for _, key := range group.Keys {
+		if strings.Contains(key, hash) {
+			canonical = key
+			break
+		}
+	}
+
+	var released []string
+	for _, key := range group.Keys {
+		if key != canonical {
+			released = append(released, key)
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Variants name their original, so rows keep the copy alive until
+		// they share the variants of the canonical one.
+		var variants mediaModel.ImageVariants
+		for _, col := range imageColumns {
+			var rows []struct {
+				RefKey   string
+				Variants mediaModel.ImageVariants
+			}
+			if err := col.rows(tx).
+				Select(col.Column+" AS ref_key, "+col.VariantsColumn+" AS variants").
+				Where(col.HashColumn+" = ?", hash).
+				Scan(&rows).Error; err != nil {
+				return err
+			}
+			for _, row := range rows {
+				if media.NormalizeKey(row.RefKey) == canonical && len(row.Variants) > len(variants) {
+					variants = row.Variants
+				}
+				released = append(released, row.Variants.Keys()...)
+			}
+		}
+
+		for _, col := range imageColumns {
+			if err := col.rows(tx).
+				Where(col.HashColumn+" = ?", hash).
+				Updates(map[string]interface{}{col.Column: canonical, col.VariantsColumn: variants}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.cleanup.Release(released...)
+
+	utils.Log.Info("[Duplicate] Image copies merged",
+		zap.String("hash", hash),
+		zap.String("key", canonical),
+		zap.Int("released", len(released)),
+	)
+	return &ImageGroup{Hash: hash, Keys: []string{canonical}, References: group.References}, nil
+}
+
+// StartBackfill queues a job that hashes files stored before hashes were recorded.
+func (s *DuplicateService) StartBackfill(adminID uint) (uint, error) {
+	job, err := s.jobs.Create(JobTypeHashBackfill, adminID, struct{}{})
+	if err != nil {
+		return 0, err
+	}
+
+	go s.backfill(job.ID)
+	return job.ID, nil
+}
+
+func (s *DuplicateService) backfill(jobID uint) {
+	s.jobs.Start(jobID)
+	result := BackfillResult{Missing: []string{}}
+
+	type pending struct {
+		col    hashColumn
+		ID     uint
+		RefKey string
+	}
+	var work []pending
+	for _, col := range append([]hashColumn{audioColumn}, imageColumns...) {
+		var rows []struct {
+			ID     uint
+			RefKey string
+		}
+		if err := col.rows(s.db).
+			Select("id, " + col.Column + " AS ref_key").
+			Where("(" + col.HashColumn + " = '' OR " + col.HashColumn + " IS NULL) AND " + col.Column + " <> ''").
+			Scan(&rows).Error; err != nil {
+			s.jobs.Fail(jobID, err, result)
+			return
+		}
+		for _, row := range rows {
+			work = append(work, pending{col: col, ID: row.ID, RefKey: row.RefKey})
+		}
+	}
+	s.jobs.SetProgress(jobID, 0, len(work))
+
+	hashes := make(map[string]string)
+	for i, item := range work {
+		key := media.NormalizeKey(item.RefKey)
+		if key == "" {
+			s.jobs.SetProgress(jobID, i+1, len(work))
+			continue
+		}
+
+		hash, ok := hashes[key]
+		if !ok {
+			var err error
+			hash, err = s.hashObject(key)
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				result.Missing = append(result.Missing, key)
+			case err != nil:
+				utils.Log.Warn("[Duplicate] Failed to hash file", zap.Error(err), zap.String("key", key))
+				result.Failed++
+			}
+			hashes[key] = hash
+		}
+
+		if hash != "" {
+			if err := s.db.Table(item.col.Table).Where("id = ?", item.ID).
+				Update(item.col.HashColumn, hash).Error; err != nil {
+				result.Failed++
+			} else {
+				result.Hashed++
+			}
+		}
+		s.jobs.SetProgress(jobID, i+1, len(work))
+	}
+
+	s.jobs.Complete(jobID, result)
+	utils.Log.Info("[Duplicate] Hash backfill finished",
+		zap.Uint("job_id", jobID),
+		zap.Int("hashed", result.Hashed),
+		zap.Int("missing", len(result.Missing)),
+		zap.Int("failed", result.Failed),
+	)
+}
+
+func (s *DuplicateService) hashObject(key string) (string, error) {
+	r, err := s.store.Get(key)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
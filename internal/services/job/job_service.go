@@ -0,0 +1,122 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	jobModel "mqfm-backend/internal/models/job"
+	"mqfm-backend/internal/utils"
+)
+
+type JobService struct {
+	db *gorm.DB
+}
+
+func NewJobService(db *gorm.DB) *JobService {
+	return &JobService{db: db}
+}
+
+// Create records a queued job with its JSON-encoded payload.
+func (s *JobService) Create(jobType string, createdBy uint, payload interface{}) (*jobModel.Job, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job := jobModel.Job{
+		Type:      jobType,
+		Status:    jobModel.StatusQueued,
+		Payload:   string(raw),
+		CreatedBy: createdBy,
+	}
+	if err := s.db.Create(&job).Error; err != nil {
+		utils.Log.Error("[Job] Failed to create job", zap.Error(err), zap.String("type", jobType))
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Start marks a job as running. A job picked up again after a failure or a
+// restart loses its previous error and finish time.
+func (s *JobService) Start(id uint) {
+	now := time.Now()
+	s.update(id, map[string]interface{}{
+		"status":      jobModel.StatusRunning,
+		"started_at":  &now,
+		"error":       "",
+		"finished_at": nil,
+	})
+}
+
+func (s *JobService) SetProgress(id uint, progress, total int) {
+	s.update(id, map[string]interface{}{
+		"progress": progress,
+		"total":    total,
+	})
+}
+
+func (s *JobService) Complete(id uint, result interface{}) {
+	raw, _ := json.Marshal(result)
+	now := time.Now()
+	s.update(id, map[string]interface{}{
+		"status":      jobModel.StatusCompleted,
+		"result":      string(raw),
+		"finished_at": &now,
+	})
+}
+
+func (s *JobService) Fail(id uint, jobErr error, result interface{}) {
+	raw, _ := json.Marshal(result)
+	now := time.Now()
+	s.update(id, map[string]interface{}{
+		"status":      jobModel.StatusFailed,
+		"error":       jobErr.Error(),
+		"result":      string(raw),
+		"finished_at": &now,
+	})
+}
+
+func (s *JobService) FindByID(id uint) (*jobModel.Job, error) {
+	var job jobModel.Job
+	if err := s.db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("job not found")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *JobService) FindAll(jobType string) ([]jobModel.Job, error) {
+	var jobs []jobModel.Job
+	query := s.db.Order("created_at DESC")
+	if jobType != "" {
+		query = query.Where("type = ?", jobType)
+	}
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// FindUnfinished returns jobs of jobType that are queued or were running,
+// such as those cut short by a restart.
+func (s *JobService) FindUnfinished(jobType string) ([]jobModel.Job, error) {
+	var jobs []jobModel.Job
+	if err := s.db.Where("type = ? AND status IN ?", jobType, []string{jobModel.StatusQueued, jobModel.StatusRunning}).
+		Order("created_at").
+		Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (s *JobService) update(id uint, updates map[string]interface{}) {
+	if err := s.db.Model(&jobModel.Job{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		utils.Log.Error("[Job] Failed to update job", zap.Error(err), zap.Uint("job_id", id))
+	}
+}
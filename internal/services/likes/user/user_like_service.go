@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 
 	likeModel "mqfm-backend/internal/models/likes/user"
+	adminAudioModel "mqfm-backend/internal/models/podcast/audio/admin"
 
 )
 
@@ -19,6 +20,11 @@ func NewUserLikeService(db *gorm.DB) *UserLikeService {
 
 func (s *UserLikeService) LikeAudio(userID uint, audioID uint) error {
 	var count int64
+	s.db.Model(&adminAudioModel.Audio{}).Scopes(adminAudioModel.Reachable).Where("id = ?", audioID).Count(&count)
+	if count == 0 {
+		return errors.New("audio not found")
+	}
+
 	s.db.Model(&likeModel.Like{}).Where("user_id = ? AND audio_id = ?", userID, audioID).Count(&count)
 	if count > 0 {
 		return errors.New("audio already liked")
@@ -44,6 +50,11 @@ func (s *UserLikeService) UnlikeAudio(userID uint, audioID uint) error {
 
 func (s *UserLikeService) GetLikedAudios(userID uint) ([]likeModel.Like, error) {
 	var likes []likeModel.Like
-	err := s.db.Where("user_id = ?", userID).Preload("Audio").Find(&likes).Error
+	// Likes of audios that were unpublished since stay stored but are hidden.
+	err := s.db.Joins("JOIN audios ON audios.id = likes.audio_id AND audios.deleted_at IS NULL").
+		Scopes(adminAudioModel.Reachable).
+		Where("likes.user_id = ?", userID).
+		Preload("Audio").
+		Find(&likes).Error
 	return likes, err
 }
\ No newline at end of file
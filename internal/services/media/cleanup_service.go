@@ -0,0 +1,111 @@
+package media
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"mqfm-backend/internal/storage"
+	"mqfm-backend/internal/utils"
+)
+
+// referenceColumn is a database column that stores storage keys.
+type referenceColumn struct {
+	Table  string
+	Column string
+	// Variants marks a JSON ImageVariants column holding several keys.
+	Variants bool
+}
+
+// referenceColumns lists every place a stored file can be referenced from.
+// Keys are content-addressed, so the same file may back several rows and must
+// only be removed once none of them point to it. Rows in the trash still count
+// so they can be restored; their files go when the row is purged.
+var referenceColumns = []referenceColumn{
+	{Table: "audios", Column: "audio_url"},
+	{Table: "audios", Column: "thumbnail"},
+	{Table: "audios", Column: "thumbnail_variants", Variants: true},
+	{Table: "audio_renditions", Column: "url"},
+	{Table: "audio_chapters", Column: "image"},
+	{Table: "audio_chapters", Column: "image_variants", Variants: true},
+	{Table: "categories", Column: "icon"},
+	{Table: "categories", Column: "icon_variants", Variants: true},
+	{Table: "categories", Column: "cover"},
+	{Table: "categories", Column: "cover_variants", Variants: true},
+	{Table: "playlists", Column: "image_url"},
+	{Table: "playlists", Column: "image_variants", Variants: true},
+	{Table: "shows", Column: "artwork"},
+	{Table: "shows", Column: "artwork_variants", Variants: true},
+	{Table: "speakers", Column: "photo"},
+	{Table: "speakers", Column: "photo_variants", Variants: true},
+	{Table: "users", Column: "profile_picture"},
+	{Table: "users", Column: "profile_picture_variants", Variants: true},
+}
+
+type CleanupService struct {
+	db    *gorm.DB
+	store storage.Storage
+}
+
+func NewCleanupService(db *gorm.DB, store storage.Storage) *CleanupService {
+	return &CleanupService{db: db, store: store}
+}
+
+// Release deletes each key that is no longer referenced by any row. Call it
+// after the transaction that dropped the reference has committed; failures are
+// logged and left for reconciliation.
+func (s *CleanupService) Release(keys ...string) {
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		key = NormalizeKey(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		referenced, err := s.IsReferenced(key)
+		if err != nil {
+			utils.Log.Error("[Storage] Failed to check file references", zap.Error(err), zap.String("key", key))
+			continue
+		}
+		if referenced {
+			continue
+		}
+
+		if err := s.store.Delete(key); err != nil {
+			utils.Log.Error("[Storage] Failed to delete released file", zap.Error(err), zap.String("key", key))
+			continue
+		}
+		utils.Log.Info("[Storage] Released file deleted", zap.String("key", key))
+	}
+}
+
+// IsReferenced reports whether any row, live or trashed, still points to key.
+func (s *CleanupService) IsReferenced(key string) (bool, error) {
+	for _, ref := range referenceColumns {
+		query := s.db.Table(ref.Table).Where(ref.Column+" IN ?", []string{key, "/" + key})
+		if ref.Variants {
+			query = s.db.Table(ref.Table).Where(ref.Column+" LIKE ?", `%"`+key+`"%`)
+		}
+
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NormalizeKey maps a stored column value to a storage key. External URLs and
+// empty values return "" because they are not ours to manage.
+func NormalizeKey(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		return ""
+	}
+	return strings.TrimLeft(value, "/")
+}
@@ -0,0 +1,279 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	mediaModel "mqfm-backend/internal/models/media"
+)
+
+// maxImagePixels guards against decompression bombs: a small file can declare
+// enormous dimensions and exhaust memory when decoded.
+const maxImagePixels = 50_000_000
+
+// ImageVariant is one square rendition generated for every uploaded image.
+type ImageVariant struct {
+	Name string
+	Size int
+}
+
+// VariantSizes are the renditions clients pick from: list artwork, cards and
+// full-screen players. Only JPEG is produced since there is no pure-Go WebP encoder.
+var VariantSizes = []ImageVariant{
+	{Name: "small", Size: 96},
+	{Name: "medium", Size: 300},
+	{Name: "large", Size: 1000},
+}
+
+const variantQuality = 85
+
+// VariantOriginal is the variants map entry that points at the uploaded file.
+const VariantOriginal = "original"
+
+// processImage decodes the image at path, extracts its palette and stores a
+// square JPEG for each VariantSizes entry under uploads/<subdir>/variants. Keys
+// derive from the source hash, so identical uploads share their variants too.
+func (s *IngestService) processImage(path, subdir, hash, originalKey string) (mediaModel.ImageVariants, *mediaModel.ColorPalette, error) {
+	img, err := decodeImage(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	square := cropSquare(img)
+	palette := extractPalette(square)
+	variants := mediaModel.ImageVariants{VariantOriginal: originalKey}
+	for _, v := range VariantSizes {
+		key := fmt.Sprintf("uploads/%s/variants/%s_%d.jpg", subdir, hash, v.Size)
+		variants[v.Name] = key
+
+		if _, err := s.store.Stat(key); err == nil {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, Resize(square, v.Size), &jpeg.Options{Quality: variantQuality}); err != nil {
+			return nil, nil, err
+		}
+		if err := s.store.Put(key, &buf, int64(buf.Len()), "image/jpeg"); err != nil {
+			return nil, nil, err
+		}
+	}
+	return variants, palette, nil
+}
+
+// ProcessStored runs image processing for a file that is already in the store,
+// for uploads made before the pipeline existed. The returned asset carries the
+// hash, variants and palette.
+func (s *IngestService) ProcessStored(key string) (*Asset, error) {
+	src, err := s.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp("", "variants-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), src)
+	tmp.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	variants, palette, err := s.processImage(tmp.Name(), path.Base(path.Dir(key)), hash, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Asset{
+		Path:     key,
+		Size:     size,
+		Hash:     hash,
+		Existing: true,
+		Variants: variants,
+		Palette:  palette,
+	}, nil
+}
+
+func decodeImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(bufio.NewReader(f))
+	if err != nil {
+		return nil, &IngestError{Status: http.StatusBadRequest, Message: "image could not be decoded"}
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, &IngestError{Status: http.StatusBadRequest, Message: "image dimensions are too large"}
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, format, err := image.Decode(bufio.NewReader(f))
+	if err != nil {
+		return nil, &IngestError{Status: http.StatusBadRequest, Message: "image could not be decoded"}
+	}
+
+	if format == "jpeg" {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		img = orient(img, exifOrientation(f))
+	}
+	return img, nil
+}
+
+// cropSquare returns the centred square region of img.
+func cropSquare(img image.Image) image.Image {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+	rect := image.Rect(x, y, x+side, y+side)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), img, rect.Min, draw.Src)
+	return dst
+}
+
+// Resize scales a square image to size, never upscaling, onto a white
+// background so transparent PNGs don't turn black as JPEG.
+func Resize(square image.Image, size int) image.Image {
+	if side := square.Bounds().Dx(); side < size {
+		size = side
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), square, square.Bounds(), draw.Over, nil)
+	return dst
+}
+
+// orient applies an EXIF orientation (1-8) so the pixels are upright.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
+
+// exifOrientation reads the orientation tag from a JPEG's APP1 segment,
+// returning 1 (upright) when there is none.
+func exifOrientation(r io.Reader) int {
+	br := bufio.NewReader(r)
+	var marker [2]byte
+	if _, err := io.ReadFull(br, marker[:]); err != nil || marker != [2]byte{0xFF, 0xD8} {
+		return 1
+	}
+
+	for {
+		if _, err := io.ReadFull(br, marker[:]); err != nil || marker[0] != 0xFF {
+			return 1
+		}
+		// Start of scan: image data follows, no more metadata.
+		if marker[1] == 0xDA {
+			return 1
+		}
+
+		var length uint16
+		if err := binary.Read(br, binary.BigEndian, &length); err != nil || length < 2 {
+			return 1
+		}
+		segment := make([]byte, length-2)
+		if _, err := io.ReadFull(br, segment); err != nil {
+			return 1
+		}
+
+		if marker[1] == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+	}
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < entries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 1
+}
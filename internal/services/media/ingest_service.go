@@ -0,0 +1,242 @@
+package media
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+
+	"github.com/gabriel-vasile/mimetype"
+	"go.uber.org/zap"
+
+	"mqfm-backend/internal/config"
+	mediaModel "mqfm-backend/internal/models/media"
+	"mqfm-backend/internal/storage"
+	"mqfm-backend/internal/utils"
+)
+
+type Kind string
+
+const (
+	KindAudio Kind = "audio"
+	KindImage Kind = "image"
+)
+
+// IngestError is a client-side upload problem that maps to a 4xx response.
+type IngestError struct {
+	Status  int
+	Message string
+}
+
+func (e *IngestError) Error() string {
+	return e.Message
+}
+
+// HTTPStatus returns the status code for an ingestion error, 500 for anything unexpected.
+func HTTPStatus(err error) int {
+	var ingestErr *IngestError
+	if errors.As(err, &ingestErr) {
+		return ingestErr.Status
+	}
+	return http.StatusInternalServerError
+}
+
+// Asset describes a stored upload.
+type Asset struct {
+	Path string `json:"path"`
+	MIME string `json:"mime"`
+	Size int64  `json:"size"`
+	Hash string `json:"hash"`
+	// Duration is the playing time in seconds for MP3 audio, zero otherwise.
+	Duration int `json:"duration"`
+	// Existing is set when identical content was already stored and reused.
+	Existing bool `json:"existing"`
+	// Variants maps variant names to keys for images, including the original.
+	Variants mediaModel.ImageVariants `json:"variants,omitempty"`
+	// Palette holds the colours extracted from an image.
+	Palette *mediaModel.ColorPalette `json:"palette,omitempty"`
+}
+
+type kindRule struct {
+	dir      string
+	maxBytes int64
+	// allowed maps a detected MIME type to the extension used on disk.
+	allowed map[string]string
+}
+
+type IngestService struct {
+	store storage.Storage
+	rules map[Kind]kindRule
+}
+
+func NewIngestService(store storage.Storage, cfg config.UploadConfig) *IngestService {
+	return &IngestService{
+		store: store,
+		rules: map[Kind]kindRule{
+			KindAudio: {
+				dir:      "audios",
+				maxBytes: cfg.MaxAudioBytes,
+				allowed: map[string]string{
+					"audio/mpeg":  ".mp3",
+					"audio/mp4":   ".m4a",
+					"audio/x-m4a": ".m4a",
+					"audio/aac":   ".aac",
+					"audio/ogg":   ".ogg",
+					"audio/wav":   ".wav",
+					"audio/flac":  ".flac",
+				},
+			},
+			KindImage: {
+				dir:      "thumbnails",
+				maxBytes: cfg.MaxImageBytes,
+				allowed: map[string]string{
+					"image/jpeg": ".jpg",
+					"image/png":  ".png",
+					"image/webp": ".webp",
+				},
+			},
+		},
+	}
+}
+
+// IngestFile validates a multipart upload and stores it under uploads/<subdir>.
+// An empty subdir uses the kind's default directory.
+func (s *IngestService) IngestFile(kind Kind, subdir string, file *multipart.FileHeader) (*Asset, error) {
+	rule, ok := s.rules[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown media kind %q", kind)
+	}
+	if file.Size > rule.maxBytes {
+		return nil, tooLarge(kind, rule.maxBytes)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	return s.Ingest(kind, subdir, src)
+}
+
+// Ingest spools r to a temp file while hashing it, sniffs the content and
+// stores it under a key derived from its SHA-256.
+func (s *IngestService) Ingest(kind Kind, subdir string, r io.Reader) (*Asset, error) {
+	rule, ok := s.rules[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown media kind %q", kind)
+	}
+	if subdir == "" {
+		subdir = rule.dir
+	}
+
+	tmp, err := os.CreateTemp("", "ingest-*")
+	if err != nil {
+		return nil, err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), io.LimitReader(r, rule.maxBytes+1))
+	tmp.Close()
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, &IngestError{Status: http.StatusBadRequest, Message: "uploaded file is empty"}
+	}
+	if size > rule.maxBytes {
+		return nil, tooLarge(kind, rule.maxBytes)
+	}
+
+	detected, err := mimetype.DetectFile(tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	mimeType, ext, ok := rule.match(detected)
+	if !ok {
+		return nil, &IngestError{
+			Status:  http.StatusUnsupportedMediaType,
+			Message: fmt.Sprintf("%s uploads do not accept %s content", kind, detected.String()),
+		}
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	key := "uploads/" + subdir + "/" + hash + ext
+
+	// Images are decoded before anything is stored, which also rejects files
+	// that only look like images.
+	var variants mediaModel.ImageVariants
+	var palette *mediaModel.ColorPalette
+	if kind == KindImage {
+		variants, palette, err = s.processImage(tmpPath, subdir, hash, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Identical content maps to the same key, so an existing object can be reused.
+	existing := true
+	if _, err := s.store.Stat(key); errors.Is(err, storage.ErrNotFound) {
+		existing = false
+		f, err := os.Open(tmpPath)
+		if err != nil {
+			return nil, err
+		}
+		err = s.store.Put(key, f, size, mimeType)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	var duration int
+	if kind == KindAudio {
+		duration, _ = utils.MP3Duration(tmpPath)
+	}
+
+	utils.Log.Info("[Ingest] Stored upload",
+		zap.String("kind", string(kind)),
+		zap.String("mime", mimeType),
+		zap.Int64("size", size),
+		zap.String("key", key),
+		zap.Bool("existing", existing),
+	)
+
+	return &Asset{
+		Path:     key,
+		MIME:     mimeType,
+		Size:     size,
+		Hash:     hash,
+		Duration: duration,
+		Existing: existing,
+		Variants: variants,
+		Palette:  palette,
+	}, nil
+}
+
+// match walks the detected type and its parents looking for an allowed MIME.
+func (r kindRule) match(detected *mimetype.MIME) (string, string, bool) {
+	for m := detected; m != nil; m = m.Parent() {
+		for allowed, ext := range r.allowed {
+			if m.Is(allowed) {
+				return allowed, ext, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+func tooLarge(kind Kind, max int64) error {
+	return &IngestError{
+		Status:  http.StatusRequestEntityTooLarge,
+		Message: fmt.Sprintf("%s file exceeds the %d MB limit", kind, max>>20),
+	}
+}
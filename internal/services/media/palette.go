@@ -0,0 +1,148 @@
+package media
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"golang.org/x/image/draw"
+
+	mediaModel "mqfm-backend/internal/models/media"
+)
+
+const (
+	// paletteSample is the edge length the artwork is reduced to before counting.
+	paletteSample  = 64
+	darkLightness  = 0.35
+	lightLightness = 0.7
+)
+
+type colorBucket struct {
+	count   int
+	r, g, b int
+}
+
+func (b colorBucket) average() (float64, float64, float64) {
+	n := float64(b.count)
+	return float64(b.r) / n, float64(b.g) / n, float64(b.b) / n
+}
+
+// extractPalette finds the most common colour in square and the most common dark
+// and light colours. When the artwork has no dark or light area, the dominant
+// colour is shaded instead.
+func extractPalette(square image.Image) *mediaModel.ColorPalette {
+	// Scale with draw.Src so transparent areas stay transparent and are skipped.
+	small := image.NewNRGBA(image.Rect(0, 0, paletteSample, paletteSample))
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), square, square.Bounds(), draw.Src, nil)
+	bounds := small.Bounds()
+
+	// 4 bits per channel keeps similar shades in the same bucket.
+	buckets := make(map[int]*colorBucket)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := small.At(x, y).RGBA()
+			if a < 0x8000 {
+				continue
+			}
+			r8, g8, b8 := int(r>>8), int(g>>8), int(b>>8)
+			key := (r8>>4)<<8 | (g8>>4)<<4 | b8>>4
+			bucket := buckets[key]
+			if bucket == nil {
+				bucket = &colorBucket{}
+				buckets[key] = bucket
+			}
+			bucket.count++
+			bucket.r += r8
+			bucket.g += g8
+			bucket.b += b8
+		}
+	}
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	var dominant, dark, light *colorBucket
+	for _, bucket := range buckets {
+		if dominant == nil || bucket.count > dominant.count {
+			dominant = bucket
+		}
+		_, _, l := rgbToHSL(bucket.average())
+		if l < darkLightness && (dark == nil || bucket.count > dark.count) {
+			dark = bucket
+		}
+		if l > lightLightness && (light == nil || bucket.count > light.count) {
+			light = bucket
+		}
+	}
+
+	dr, dg, db := dominant.average()
+	h, s, _ := rgbToHSL(dr, dg, db)
+	palette := &mediaModel.ColorPalette{Dominant: hexColor(dr, dg, db)}
+	if dark != nil {
+		palette.Dark = hexColor(dark.average())
+	} else {
+		palette.Dark = hexColor(hslToRGB(h, s, 0.18))
+	}
+	if light != nil {
+		palette.Light = hexColor(light.average())
+	} else {
+		palette.Light = hexColor(hslToRGB(h, s, 0.88))
+	}
+	return palette
+}
+
+func hexColor(r, g, b float64) string {
+	return fmt.Sprintf("#%02x%02x%02x", uint8(math.Round(r)), uint8(math.Round(g)), uint8(math.Round(b)))
+}
+
+// rgbToHSL takes 0-255 channels and returns hue in degrees, saturation and
+// lightness in 0-1.
+func rgbToHSL(r, g, b float64) (float64, float64, float64) {
+	r, g, b = r/255, g/255, b/255
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	l := (max + min) / 2
+	if max == min {
+		return 0, 0, l
+	}
+
+	d := max - min
+	s := d / (1 - math.Abs(2*l-1))
+	var h float64
+	switch max {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, l
+}
+
+func hslToRGB(h, s, l float64) (float64, float64, float64) {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return (r + m) * 255, (g + m) * 255, (b + m) * 255
+}
@@ -0,0 +1,153 @@
+package media
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	mediaModel "mqfm-backend/internal/models/media"
+	"mqfm-backend/internal/storage"
+	"mqfm-backend/internal/utils"
+)
+
+// uploadsPrefix is the part of the store managed by the application.
+const uploadsPrefix = "uploads/"
+
+// DanglingReference is a row pointing to a file that no longer exists.
+type DanglingReference struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	ID     uint   `json:"id"`
+	Key    string `json:"key"`
+}
+
+// ReconcileReport is the outcome of comparing the store against the database.
+type ReconcileReport struct {
+	Scanned  int                  `json:"scanned"`
+	Orphans  []storage.ObjectInfo `json:"orphans"`
+	Dangling []DanglingReference  `json:"dangling"`
+	// Filled in when fix is requested.
+	DeletedOrphans    int `json:"deleted_orphans"`
+	ClearedReferences int `json:"cleared_references"`
+}
+
+// Reconcile lists files no row refers to and rows whose file is missing. Files
+// modified within grace are skipped, since they may belong to an upload whose
+// row has not been committed yet. With fix set, orphans are deleted and
+// dangling references are cleared.
+func (s *CleanupService) Reconcile(fix bool, grace time.Duration) (*ReconcileReport, error) {
+	report := &ReconcileReport{
+		Orphans:  []storage.ObjectInfo{},
+		Dangling: []DanglingReference{},
+	}
+
+	referenced := make(map[string]bool)
+	exists := make(map[string]bool)
+	for _, ref := range referenceColumns {
+		var rows []struct {
+			ID     uint
+			RefKey string
+		}
+		query := s.db.Table(ref.Table).
+			Select("id, " + ref.Column + " AS ref_key").
+			Where(ref.Column + " <> ''")
+		if err := query.Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			values := []string{row.RefKey}
+			if ref.Variants {
+				var variants mediaModel.ImageVariants
+				if err := variants.Scan(row.RefKey); err != nil {
+					continue
+				}
+				values = variants.Keys()
+			}
+
+			for _, value := range values {
+				key := NormalizeKey(value)
+				if key == "" {
+					continue
+				}
+				referenced[key] = true
+
+				found, checked := exists[key]
+				if !checked {
+					_, err := s.store.Stat(key)
+					if err != nil && !errors.Is(err, storage.ErrNotFound) {
+						return nil, err
+					}
+					found = err == nil
+					exists[key] = found
+				}
+				if !found {
+					report.Dangling = append(report.Dangling, DanglingReference{
+						Table:  ref.Table,
+						Column: ref.Column,
+						ID:     row.ID,
+						Key:    key,
+					})
+					// One missing variant is enough to clear the whole map.
+					break
+				}
+			}
+		}
+	}
+
+	objects, err := s.store.List(uploadsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := time.Now().Add(-grace)
+	for _, obj := range objects {
+		if strings.HasPrefix(path.Base(obj.Key), ".") {
+			continue
+		}
+		report.Scanned++
+		if referenced[obj.Key] || obj.LastModified.After(cutoff) {
+			continue
+		}
+		report.Orphans = append(report.Orphans, obj)
+	}
+
+	if !fix {
+		return report, nil
+	}
+
+	for _, obj := range report.Orphans {
+		if err := s.store.Delete(obj.Key); err != nil {
+			utils.Log.Error("[Reconcile] Failed to delete orphan", zap.Error(err), zap.String("key", obj.Key))
+			continue
+		}
+		report.DeletedOrphans++
+	}
+
+	for _, ref := range report.Dangling {
+		var err error
+		if ref.Table == "audio_renditions" {
+			// A rendition without its file is useless, so drop the row.
+			err = s.db.Exec("DELETE FROM audio_renditions WHERE id = ?", ref.ID).Error
+		} else {
+			err = s.db.Table(ref.Table).Where("id = ?", ref.ID).Update(ref.Column, "").Error
+		}
+		if err != nil {
+			utils.Log.Error("[Reconcile] Failed to clear dangling reference",
+				zap.Error(err),
+				zap.String("table", ref.Table),
+				zap.Uint("id", ref.ID),
+			)
+			continue
+		}
+		report.ClearedReferences++
+	}
+
+	utils.Log.Info("[Reconcile] Fix applied",
+		zap.Int("deleted_orphans", report.DeletedOrphans),
+		zap.Int("cleared_references", report.ClearedReferences),
+	)
+	return report, nil
+}
@@ -0,0 +1,51 @@
+package media
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+
+	"mqfm-backend/internal/config"
+)
+
+// StageArchive copies an uploaded import archive to the staging folder, where
+// it stays until its import finishes, and returns its path and content hash.
+// The file is named prefix followed by a random ID.
+func StageArchive(file *multipart.FileHeader, prefix string) (string, string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", "", err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(config.StagingDir("imports"), 0755); err != nil {
+		return "", "", err
+	}
+	dst := ArchivePath(prefix + uuid.New().String() + ".zip")
+	out, err := os.Create(dst)
+	if err != nil {
+		return "", "", err
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, hasher), src); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return "", "", err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return "", "", err
+	}
+	return dst, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// ArchivePath returns the location of the staged archive with the given name.
+func ArchivePath(name string) string {
+	return filepath.Join(config.StagingDir("imports"), filepath.Base(name))
+}
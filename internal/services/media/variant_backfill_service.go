@@ -0,0 +1,145 @@
+package media
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	jobService "mqfm-backend/internal/services/job"
+	"mqfm-backend/internal/utils"
+)
+
+const JobTypeVariantBackfill = "image_variants_backfill"
+
+// imageColumn is an image path column with the columns derived from it.
+// PaletteColumn is empty where no palette is kept.
+type imageColumn struct {
+	Table          string
+	Column         string
+	HashColumn     string
+	VariantsColumn string
+	PaletteColumn  string
+}
+
+var imageColumns = []imageColumn{
+	{Table: "audios", Column: "thumbnail", HashColumn: "thumbnail_hash", VariantsColumn: "thumbnail_variants", PaletteColumn: "palette"},
+	{Table: "audio_chapters", Column: "image", HashColumn: "image_hash", VariantsColumn: "image_variants"},
+	{Table: "categories", Column: "icon", HashColumn: "icon_hash", VariantsColumn: "icon_variants"},
+	{Table: "categories", Column: "cover", HashColumn: "cover_hash", VariantsColumn: "cover_variants"},
+	{Table: "playlists", Column: "image_url", HashColumn: "image_hash", VariantsColumn: "image_variants", PaletteColumn: "palette"},
+	{Table: "shows", Column: "artwork", HashColumn: "artwork_hash", VariantsColumn: "artwork_variants", PaletteColumn: "palette"},
+	{Table: "speakers", Column: "photo", HashColumn: "photo_hash", VariantsColumn: "photo_variants"},
+	{Table: "users", Column: "profile_picture", HashColumn: "profile_picture_hash", VariantsColumn: "profile_picture_variants"},
+}
+
+// VariantBackfillResult summarises a variants backfill run.
+type VariantBackfillResult struct {
+	Processed int                    `json:"processed"`
+	Failed    []VariantBackfillError `json:"failed"`
+}
+
+type VariantBackfillError struct {
+	Table string `json:"table"`
+	ID    uint   `json:"id"`
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// VariantBackfillService generates variants and palettes for images stored
+// before the processing pipeline existed.
+type VariantBackfillService struct {
+	db     *gorm.DB
+	ingest *IngestService
+	jobs   *jobService.JobService
+}
+
+func NewVariantBackfillService(db *gorm.DB, ingest *IngestService, jobs *jobService.JobService) *VariantBackfillService {
+	return &VariantBackfillService{db: db, ingest: ingest, jobs: jobs}
+}
+
+// Start queues the backfill job and runs it in the background.
+func (s *VariantBackfillService) Start(adminID uint) (uint, error) {
+	job, err := s.jobs.Create(JobTypeVariantBackfill, adminID, struct{}{})
+	if err != nil {
+		return 0, err
+	}
+
+	go s.run(job.ID)
+	return job.ID, nil
+}
+
+func (s *VariantBackfillService) run(jobID uint) {
+	s.jobs.Start(jobID)
+	result := VariantBackfillResult{Failed: []VariantBackfillError{}}
+
+	type pending struct {
+		col    imageColumn
+		ID     uint
+		RefKey string
+		Hash   string
+	}
+	var work []pending
+	for _, col := range imageColumns {
+		var rows []struct {
+			ID     uint
+			RefKey string
+			Hash   string
+		}
+		missing := col.VariantsColumn + " IS NULL OR " + col.VariantsColumn + " = ''"
+		if col.PaletteColumn != "" {
+			missing += " OR " + col.PaletteColumn + " IS NULL OR " + col.PaletteColumn + " = ''"
+		}
+		if err := s.db.Table(col.Table).
+			Select("id, " + col.Column + " AS ref_key, " + col.HashColumn + " AS hash").
+			Where(col.Column + " <> '' AND deleted_at IS NULL").
+			Where("(" + missing + ")").
+			Scan(&rows).Error; err != nil {
+			s.jobs.Fail(jobID, err, result)
+			return
+		}
+		for _, row := range rows {
+			work = append(work, pending{col: col, ID: row.ID, RefKey: row.RefKey, Hash: row.Hash})
+		}
+	}
+	s.jobs.SetProgress(jobID, 0, len(work))
+
+	for i, item := range work {
+		key := NormalizeKey(item.RefKey)
+		if key != "" {
+			asset, err := s.ingest.ProcessStored(key)
+			if err == nil {
+				updates := map[string]interface{}{item.col.VariantsColumn: asset.Variants}
+				if item.col.PaletteColumn != "" && asset.Palette != nil {
+					updates[item.col.PaletteColumn] = *asset.Palette
+				}
+				if item.Hash == "" {
+					updates[item.col.HashColumn] = asset.Hash
+				}
+				err = s.db.Table(item.col.Table).Where("id = ?", item.ID).Updates(updates).Error
+			}
+
+			if err != nil {
+				utils.Log.Warn("[Variants] Backfill item failed",
+					zap.Error(err),
+					zap.String("table", item.col.Table),
+					zap.Uint("id", item.ID),
+				)
+				result.Failed = append(result.Failed, VariantBackfillError{
+					Table: item.col.Table,
+					ID:    item.ID,
+					Key:   key,
+					Error: err.Error(),
+				})
+			} else {
+				result.Processed++
+			}
+		}
+		s.jobs.SetProgress(jobID, i+1, len(work))
+	}
+
+	s.jobs.Complete(jobID, result)
+	utils.Log.Info("[Variants] Backfill finished",
+		zap.Uint("job_id", jobID),
+		zap.Int("processed", result.Processed),
+		zap.Int("failed", len(result.Failed)),
+	)
+}
@@ -0,0 +1,85 @@
+package notification
+
+import (
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	adminModel "mqfm-backend/internal/models/auth/admin"
+	notificationModel "mqfm-backend/internal/models/notification"
+	"mqfm-backend/internal/utils"
+)
+
+type NotificationService struct {
+	db *gorm.DB
+}
+
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db}
+}
+
+// Notify records a message for each recipient. Failures are logged rather
+// than returned so they never undo the action being reported.
+func (s *NotificationService) Notify(kind, message string, audioID *uint, recipients ...uint) {
+	for _, id := range recipients {
+		n := notificationModel.Notification{
+			RecipientID: id,
+			Type:        kind,
+			Message:     message,
+			AudioID:     audioID,
+		}
+		if err := s.db.Create(&n).Error; err != nil {
+			utils.Log.Error("[Notification] Failed to create notification",
+				zap.Error(err),
+				zap.String("type", kind),
+				zap.Uint("recipient_id", id),
+			)
+		}
+	}
+}
+
+// NotifyRoles sends a message to every admin account with one of roles,
+// except the one who caused it.
+func (s *NotificationService) NotifyRoles(kind, message string, audioID *uint, exceptID uint, roles ...string) {
+	var ids []uint
+	if err := s.db.Model(&adminModel.Admin{}).
+		Where("role IN ? AND id <> ?", roles, exceptID).
+		Pluck("id", &ids).Error; err != nil {
+		utils.Log.Error("[Notification] Failed to find recipients", zap.Error(err), zap.String("type", kind))
+		return
+	}
+	s.Notify(kind, message, audioID, ids...)
+}
+
+func (s *NotificationService) FindAll(recipientID uint, unreadOnly bool) ([]notificationModel.Notification, error) {
+	var notifications []notificationModel.Notification
+	query := s.db.Where("recipient_id = ?", recipientID).Order("id DESC")
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if err := query.Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (s *NotificationService) MarkRead(id, recipientID uint) error {
+	result := s.db.Model(&notificationModel.Notification{}).
+		Where("id = ? AND recipient_id = ? AND read_at IS NULL", id, recipientID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("notification not found")
+	}
+	return nil
+}
+
+func (s *NotificationService) MarkAllRead(recipientID uint) error {
+	return s.db.Model(&notificationModel.Notification{}).
+		Where("recipient_id = ? AND read_at IS NULL", recipientID).
+		Update("read_at", time.Now()).Error
+}
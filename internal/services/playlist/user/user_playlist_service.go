@@ -8,15 +8,17 @@ import (
 
 	adminAudioModel "mqfm-backend/internal/models/podcast/audio/admin"
 	playlistModel "mqfm-backend/internal/models/playlist/user"
+	"mqfm-backend/internal/services/media"
 	"mqfm-backend/internal/utils"
 )
 
 type UserPlaylistService struct {
-	db *gorm.DB
+	db      *gorm.DB
+	cleanup *media.CleanupService
 }
 
-func NewUserPlaylistService(db *gorm.DB) *UserPlaylistService {
-	return &UserPlaylistService{db: db}
+func NewUserPlaylistService(db *gorm.DB, cleanup *media.CleanupService) *UserPlaylistService {
+	return &UserPlaylistService{db: db, cleanup: cleanup}
 }
 
 func (s *UserPlaylistService) Create(playlist *playlistModel.Playlist) error {
@@ -26,6 +28,7 @@ func (s *UserPlaylistService) Create(playlist *playlistModel.Playlist) error {
 			zap.Uint("user_id", playlist.UserID),
 			zap.String("name", playlist.Name),
 		)
+		s.cleanup.Release(append(playlist.ImageVariants.Keys(), playlist.ImageURL)...)
 		return err
 	}
 	
@@ -38,7 +41,7 @@ func (s *UserPlaylistService) Create(playlist *playlistModel.Playlist) error {
 
 func (s *UserPlaylistService) GetByUserID(userID uint) ([]playlistModel.Playlist, error) {
 	var playlists []playlistModel.Playlist
-	err := s.db.Where("user_id = ?", userID).Preload("Audios").Find(&playlists).Error
+	err := s.db.Where("user_id = ?", userID).Preload("Audios", adminAudioModel.Reachable).Find(&playlists).Error
 	if err != nil {
 		utils.Log.Error("[Playlist] Failed to fetch playlists",
 			zap.Error(err),
@@ -50,7 +53,7 @@ func (s *UserPlaylistService) GetByUserID(userID uint) ([]playlistModel.Playlist
 
 func (s *UserPlaylistService) Search(userID uint, query string) ([]playlistModel.Playlist, error) {
 	var playlists []playlistModel.Playlist
-	err := s.db.Where("user_id = ? AND name LIKE ?", userID, "%"+query+"%").Preload("Audios").Find(&playlists).Error
+	err := s.db.Where("user_id = ? AND name LIKE ?", userID, "%"+query+"%").Preload("Audios", adminAudioModel.Reachable).Find(&playlists).Error
 	if err != nil {
 		utils.Log.Error("[Playlist] Failed to search playlists",
 			zap.Error(err),
@@ -63,7 +66,7 @@ func (s *UserPlaylistService) Search(userID uint, query string) ([]playlistModel
 
 func (s *UserPlaylistService) GetByID(id uint, userID uint) (*playlistModel.Playlist, error) {
 	var playlist playlistModel.Playlist
-	err := s.db.Where("id = ? AND user_id = ?", id, userID).Preload("Audios").First(&playlist).Error
+	err := s.db.Where("id = ? AND user_id = ?", id, userID).Preload("Audios", adminAudioModel.Reachable).First(&playlist).Error
 	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			utils.Log.Error("[Playlist] Failed to get playlist detail",
@@ -98,7 +101,7 @@ func (s *UserPlaylistService) AddAudioToPlaylist(userID uint, playlistID uint, a
 	}
 
 	var audio adminAudioModel.Audio
-	if err := s.db.First(&audio, audioID).Error; err != nil {
+	if err := s.db.Scopes(adminAudioModel.Reachable).First(&audio, audioID).Error; err != nil {
 		return errors.New("audio not found")
 	}
 
@@ -121,14 +124,14 @@ func (s *UserPlaylistService) AddAudioToPlaylist(userID uint, playlistID uint, a
 }
 
 func (s *UserPlaylistService) CreateAndAddAudio(playlist *playlistModel.Playlist, audioID uint) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(playlist).Error; err != nil {
 			utils.Log.Error("[Playlist] Transaction: Failed to create playlist", zap.Error(err))
 			return err
 		}
 
 		var audio adminAudioModel.Audio
-		if err := tx.First(&audio, audioID).Error; err != nil {
+		if err := tx.Scopes(adminAudioModel.Reachable).First(&audio, audioID).Error; err != nil {
 			return errors.New("audio not found")
 		}
 
@@ -145,4 +148,8 @@ func (s *UserPlaylistService) CreateAndAddAudio(playlist *playlistModel.Playlist
 
 		return nil
 	})
+	if err != nil {
+		s.cleanup.Release(append(playlist.ImageVariants.Keys(), playlist.ImageURL)...)
+	}
+	return err
 }
\ No newline at end of file
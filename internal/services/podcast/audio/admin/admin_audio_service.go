@@ -1,76 +1,252 @@
-package admin
-
-import (
-	"errors"
-
-	"gorm.io/gorm"
-
-	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
-
-)
-
-type AdminAudioService struct {
-	db *gorm.DB
-}
-
-func NewAdminAudioService(db *gorm.DB) *AdminAudioService {
-	return &AdminAudioService{db: db}
-}
-
-func (s *AdminAudioService) Create(audio *audioModel.Audio) error {
-	return s.db.Create(audio).Error
-}
-
-func (s *AdminAudioService) FindAll() ([]audioModel.Audio, error) {
-	var audios []audioModel.Audio
-	if err := s.db.Find(&audios).Error; err != nil {
-		return nil, err
-	}
-	return audios, nil
-}
-
-func (s *AdminAudioService) FindByID(id uint) (*audioModel.Audio, error) {
-	var audio audioModel.Audio
-	if err := s.db.First(&audio, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("audio not found")
-		}
-		return nil, err
-	}
-	return &audio, nil
-}
-
-func (s *AdminAudioService) Update(id uint, updates map[string]interface{}) (*audioModel.Audio, error) {
-	if err := s.db.Model(&audioModel.Audio{}).Where("id = ?", id).Updates(updates).Error; err != nil {
-		return nil, err
-	}
-
-	var updatedAudio audioModel.Audio
-	if err := s.db.First(&updatedAudio, id).Error; err != nil {
-		return nil, err
-	}
-
-	return &updatedAudio, nil
-}
-
-func (s *AdminAudioService) Delete(id uint) error {
-	var audio audioModel.Audio
-	if err := s.db.First(&audio, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("audio not found")
-		}
-		return err
-	}
-
-	return s.db.Delete(&audio).Error
-}
-
-func (s *AdminAudioService) Search(query string) ([]audioModel.Audio, error) {
-	var audios []audioModel.Audio
-	// Mencari berdasarkan Title yang mengandung kata kunci (query)
-	// Menggunakan query LIKE %...%
-	if err := s.db.Where("title LIKE ?", "%"+query+"%").Find(&audios).Error; err != nil {
-		return nil, err
-	}
-	return audios, nil
-}
\ No newline at end of file
+package admin
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	mediaModel "mqfm-backend/internal/models/media"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	speakerModel "mqfm-backend/internal/models/podcast/speaker"
+	revisionModel "mqfm-backend/internal/models/revision"
+	tagModel "mqfm-backend/internal/models/tag"
+	"mqfm-backend/internal/services/media"
+	revisionService "mqfm-backend/internal/services/revision"
+
+)
+
+type AdminAudioService struct {
+	db        *gorm.DB
+	cleanup   *media.CleanupService
+	revisions *revisionService.RevisionService
+}
+
+func NewAdminAudioService(db *gorm.DB, cleanup *media.CleanupService, revisions *revisionService.RevisionService) *AdminAudioService {
+	return &AdminAudioService{db: db, cleanup: cleanup, revisions: revisions}
+}
+
+func (s *AdminAudioService) Create(audio *audioModel.Audio, sched Schedule) error {
+	err := sched.apply(audio, time.Now())
+	if err == nil {
+		err = s.numberEpisode(audio)
+	}
+	if err == nil {
+		err = s.db.Create(audio).Error
+	}
+	if err != nil {
+		// The files were stored before the row; don't leave them behind.
+		s.cleanup.Release(append(audio.ThumbnailVariants.Keys(), audio.AudioURL, audio.Thumbnail)...)
+		return err
+	}
+	return nil
+}
+
+// FindAll returns the audios the public may browse, limited to those carrying
+// every tag slug in tags when any are given.
+func (s *AdminAudioService) FindAll(tags []string) ([]audioModel.Audio, error) {
+	var audios []audioModel.Audio
+	if err := s.db.Scopes(audioModel.Listed, s.taggedWith(tags)).Preload("Renditions").Preload("Speakers").Preload("Tags").Find(&audios).Error; err != nil {
+		return nil, err
+	}
+	return audios, nil
+}
+
+// FindByID returns a published or unlisted audio.
+func (s *AdminAudioService) FindByID(id uint) (*audioModel.Audio, error) {
+	return s.findByID(s.db.Scopes(audioModel.Reachable), id)
+}
+
+// FindAllAdmin returns audios in any state, optionally limited to one status.
+func (s *AdminAudioService) FindAllAdmin(status string) ([]audioModel.Audio, error) {
+	var audios []audioModel.Audio
+	query := s.db.Preload("Renditions").Preload("Speakers").Preload("Tags").Order("id DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&audios).Error; err != nil {
+		return nil, err
+	}
+	return audios, nil
+}
+
+// Preview returns an audio whatever its state, so admins can check drafts.
+func (s *AdminAudioService) Preview(id uint) (*audioModel.Audio, error) {
+	return s.findByID(s.db, id)
+}
+
+func (s *AdminAudioService) findByID(query *gorm.DB, id uint) (*audioModel.Audio, error) {
+	var audio audioModel.Audio
+	if err := query.Preload("Renditions").Preload("Chapters", chapterOrder).Preload("Speakers").Preload("Tags").First(&audio, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("audio not found")
+		}
+		return nil, err
+	}
+	return &audio, nil
+}
+
+// FindByAudioHash returns audios whose source file has the given content hash.
+func (s *AdminAudioService) FindByAudioHash(hash string, excludeID uint) ([]audioModel.Audio, error) {
+	var audios []audioModel.Audio
+	if hash == "" {
+		return audios, nil
+	}
+	if err := s.db.Where("audio_hash = ? AND id <> ?", hash, excludeID).Order("id").Find(&audios).Error; err != nil {
+		return nil, err
+	}
+	return audios, nil
+}
+
+// Discard removes freshly stored files that did not end up on any audio.
+func (s *AdminAudioService) Discard(keys ...string) {
+	s.cleanup.Release(keys...)
+}
+
+// Update applies updates and records the edit in the audio's revision
+// history under actorID.
+func (s *AdminAudioService) Update(id, actorID uint, updates map[string]interface{}, sched Schedule) (*audioModel.Audio, error) {
+	return s.update(id, actorID, updates, sched, nil)
+}
+
+func (s *AdminAudioService) update(id, actorID uint, updates map[string]interface{}, sched Schedule, revertedTo *int) (*audioModel.Audio, error) {
+	var current audioModel.Audio
+	if err := s.db.First(&current, id).Error; err != nil {
+		s.cleanup.Release(fileKeys(updates)...)
+		return nil, err
+	}
+
+	if !sched.empty() {
+		next := current
+		if err := sched.apply(&next, time.Now()); err != nil {
+			s.cleanup.Release(fileKeys(updates)...)
+			return nil, err
+		}
+		updates["status"] = next.Status
+		updates["publish_at"] = next.PublishAt
+		updates["unpublish_at"] = next.UnpublishAt
+		updates["published_at"] = next.PublishedAt
+	}
+	if err := s.applyEpisode(current, updates); err != nil {
+		s.cleanup.Release(fileKeys(updates)...)
+		return nil, err
+	}
+	if len(updates) == 0 {
+		return s.Preview(id)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&audioModel.Audio{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+		var saved audioModel.Audio
+		if err := tx.First(&saved, id).Error; err != nil {
+			return err
+		}
+		return s.revisions.Record(tx, revisionModel.EntityAudio, id, actorID, revisionFields(current), revisionFields(saved), revertedTo)
+	})
+	if err != nil {
+		s.cleanup.Release(fileKeys(updates)...)
+		return nil, err
+	}
+
+	// Replaced files are only removed once the new reference is committed.
+	var replaced []string
+	if _, ok := updates["audio_url"]; ok {
+		replaced = append(replaced, current.AudioURL)
+	}
+	if _, ok := updates["thumbnail"]; ok {
+		replaced = append(replaced, current.Thumbnail)
+		replaced = append(replaced, current.ThumbnailVariants.Keys()...)
+	}
+	s.cleanup.Release(replaced...)
+
+	var updatedAudio audioModel.Audio
+	if err := s.db.Preload("Renditions").Preload("Chapters", chapterOrder).Preload("Speakers").Preload("Tags").First(&updatedAudio, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &updatedAudio, nil
+}
+
+// Delete moves the audio to the trash. Its renditions, links and files are
+// kept so it can be restored; purging it from the trash removes them.
+func (s *AdminAudioService) Delete(id uint) error {
+	var audio audioModel.Audio
+	if err := s.db.First(&audio, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("audio not found")
+		}
+		return err
+	}
+
+	return s.db.Delete(&audio).Error
+}
+
+// Search matches query against titles, speaker names and transcripts, limited
+// like FindAll by tag slugs. Transcript hits carry the matching cues so
+// players can jump to them.
+func (s *AdminAudioService) Search(query string, tags []string) ([]audioModel.Audio, error) {
+	var audios []audioModel.Audio
+	// Mencari berdasarkan Title yang mengandung kata kunci (query)
+	// Menggunakan query LIKE %...%
+	// Audios also match on the name of any speaker heard on them.
+	bySpeaker := s.db.Table("audio_speakers").
+		Select("audio_speakers.audio_id").
+		Joins("JOIN speakers ON speakers.id = audio_speakers.speaker_id AND speakers.deleted_at IS NULL").
+		Where("speakers.name LIKE ?", "%"+query+"%")
+	byTranscript := s.db.Table("transcript_cues").
+		Select("transcript_cues.audio_id").
+		Where("transcript_cues.text LIKE ?", "%"+query+"%")
+	if err := s.db.Scopes(audioModel.Listed, s.taggedWith(tags)).Preload("Renditions").Preload("Speakers").Preload("Tags").
+		Where("audios.title LIKE ? OR audios.id IN (?) OR audios.id IN (?)", "%"+query+"%", bySpeaker, byTranscript).
+		Find(&audios).Error; err != nil {
+		return nil, err
+	}
+	if err := s.transcriptMatches(audios, query); err != nil {
+		return nil, err
+	}
+	return audios, nil
+}
+
+// SetSpeakers replaces the speakers linked to an audio.
+func (s *AdminAudioService) SetSpeakers(id uint, speakers []speakerModel.Speaker) error {
+	audio := audioModel.Audio{ID: id}
+	return s.db.Model(&audio).Association("Speakers").Replace(speakers)
+}
+
+// SetTags replaces the tags on an audio.
+func (s *AdminAudioService) SetTags(id uint, tags []tagModel.Tag) error {
+	audio := audioModel.Audio{ID: id}
+	return s.db.Model(&audio).Association("Tags").Replace(tags)
+}
+
+// taggedWith limits a query to audios carrying all of the given tag slugs.
+func (s *AdminAudioService) taggedWith(slugs []string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(slugs) == 0 {
+			return db
+		}
+		tagged := s.db.Table("audio_tags").
+			Select("audio_tags.audio_id").
+			Joins("JOIN tags ON tags.id = audio_tags.tag_id").
+			Where("tags.slug IN ?", slugs).
+			Group("audio_tags.audio_id").
+			Having("COUNT(DISTINCT tags.id) = ?", len(slugs))
+		return db.Where("audios.id IN (?)", tagged)
+	}
+}
+
+// fileKeys returns the storage keys carried by an update map.
+func fileKeys(updates map[string]interface{}) []string {
+	var keys []string
+	for _, column := range []string{"audio_url", "thumbnail"} {
+		if key, ok := updates[column].(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	if variants, ok := updates["thumbnail_variants"].(mediaModel.ImageVariants); ok {
+		keys = append(keys, variants.Keys()...)
+	}
+	return keys
+}
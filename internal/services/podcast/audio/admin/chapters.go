@@ -0,0 +1,295 @@
+package admin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	"mqfm-backend/internal/services/media"
+	"mqfm-backend/internal/storage"
+	"mqfm-backend/internal/utils"
+)
+
+var ErrChapterNotFound = errors.New("chapter not found")
+
+// ChapterInput is a chapter create or update request. Nil fields keep the
+// chapter's current values.
+type ChapterInput struct {
+	Title      *string
+	StartMs    *int
+	EndMs      *int
+	ClearEndMs bool
+	URL        *string
+	// Image is an ingested picture replacing the current one.
+	Image       *media.Asset
+	RemoveImage bool
+}
+
+// ChapterService manages the chapter markers of audios.
+type ChapterService struct {
+	db      *gorm.DB
+	store   storage.Storage
+	ingest  *media.IngestService
+	cleanup *media.CleanupService
+}
+
+func NewChapterService(db *gorm.DB, store storage.Storage, ingest *media.IngestService, cleanup *media.CleanupService) *ChapterService {
+	return &ChapterService{db: db, store: store, ingest: ingest, cleanup: cleanup}
+}
+
+// List returns the audio's chapters in start order.
+func (s *ChapterService) List(audioID uint) ([]audioModel.AudioChapter, error) {
+	chapters := []audioModel.AudioChapter{}
+	if err := s.db.Scopes(chapterOrder).Where("audio_id = ?", audioID).Find(&chapters).Error; err != nil {
+		return nil, err
+	}
+	return chapters, nil
+}
+
+func chapterOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("start_ms, id")
+}
+
+func (s *ChapterService) Create(audioID uint, input ChapterInput) (*audioModel.AudioChapter, error) {
+	chapter := audioModel.AudioChapter{AudioID: audioID}
+	if input.Title == nil || input.StartMs == nil {
+		s.discard(input.Image)
+		return nil, inputError("title and start_ms are required")
+	}
+	if err := s.save(&chapter, input); err != nil {
+		return nil, err
+	}
+	return &chapter, nil
+}
+
+func (s *ChapterService) Update(audioID, chapterID uint, input ChapterInput) (*audioModel.AudioChapter, error) {
+	var chapter audioModel.AudioChapter
+	if err := s.db.Where("audio_id = ?", audioID).First(&chapter, chapterID).Error; err != nil {
+		s.discard(input.Image)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChapterNotFound
+		}
+		return nil, err
+	}
+
+	oldImages := append(chapter.ImageVariants.Keys(), chapter.Image)
+	if err := s.save(&chapter, input); err != nil {
+		return nil, err
+	}
+	if input.Image != nil || input.RemoveImage {
+		s.cleanup.Release(oldImages...)
+	}
+	return &chapter, nil
+}
+
+func (s *ChapterService) Delete(audioID, chapterID uint) error {
+	var chapter audioModel.AudioChapter
+	if err := s.db.Where("audio_id = ?", audioID).First(&chapter, chapterID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrChapterNotFound
+		}
+		return err
+	}
+	if err := s.db.Delete(&chapter).Error; err != nil {
+		return err
+	}
+	s.cleanup.Release(append(chapter.ImageVariants.Keys(), chapter.Image)...)
+	return nil
+}
+
+// save applies input to chapter, checks the result against the audio and its
+// other chapters and stores it. A new image is released again on failure.
+func (s *ChapterService) save(chapter *audioModel.AudioChapter, input ChapterInput) error {
+	if input.Title != nil {
+		chapter.Title = strings.TrimSpace(*input.Title)
+	}
+	if input.StartMs != nil {
+		chapter.StartMs = *input.StartMs
+	}
+	if input.EndMs != nil {
+		chapter.EndMs = input.EndMs
+	}
+	if input.ClearEndMs {
+		chapter.EndMs = nil
+	}
+	if input.URL != nil {
+		chapter.URL = strings.TrimSpace(*input.URL)
+	}
+	if input.RemoveImage {
+		chapter.Image, chapter.ImageHash, chapter.ImageVariants = "", "", nil
+	}
+	if input.Image != nil {
+		chapter.Image, chapter.ImageHash, chapter.ImageVariants = input.Image.Path, input.Image.Hash, input.Image.Variants
+	}
+
+	err := s.validate(chapter)
+	if err == nil {
+		err = s.db.Save(chapter).Error
+	}
+	if err != nil {
+		s.discard(input.Image)
+		return err
+	}
+	return nil
+}
+
+func (s *ChapterService) validate(chapter *audioModel.AudioChapter) error {
+	if chapter.Title == "" {
+		return inputError("title is required")
+	}
+	if chapter.StartMs < 0 {
+		return inputError("start_ms cannot be negative")
+	}
+	if chapter.EndMs != nil && *chapter.EndMs <= chapter.StartMs {
+		return inputError("end_ms must be after start_ms")
+	}
+	if chapter.URL != "" && !isWebLink(chapter.URL) {
+		return inputError("url must be an http or https link")
+	}
+
+	var audio audioModel.Audio
+	if err := s.db.Select("id", "duration").First(&audio, chapter.AudioID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("audio not found")
+		}
+		return err
+	}
+	if audio.Duration > 0 && chapter.StartMs >= audio.Duration*1000 {
+		return inputError(fmt.Sprintf("start_ms must be before the end of the audio (%d ms)", audio.Duration*1000))
+	}
+
+	var clashes int64
+	if err := s.db.Model(&audioModel.AudioChapter{}).
+		Where("audio_id = ? AND start_ms = ? AND id <> ?", chapter.AudioID, chapter.StartMs, chapter.ID).
+		Count(&clashes).Error; err != nil {
+		return err
+	}
+	if clashes > 0 {
+		return inputError("another chapter already starts at that time")
+	}
+	return nil
+}
+
+func isWebLink(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (s *ChapterService) discard(asset *media.Asset) {
+	if asset != nil {
+		s.cleanup.Release(append(asset.Variants.Keys(), asset.Path)...)
+	}
+}
+
+// ImportID3 adds the chapters in the ID3 tag of the audio's file, with their
+// titles, links and pictures. Audios that already have chapters are left
+// alone so edits made here are never overwritten.
+func (s *ChapterService) ImportID3(audioID uint) ([]audioModel.AudioChapter, error) {
+	var audio audioModel.Audio
+	if err := s.db.Select("id", "audio_url").First(&audio, audioID).Error; err != nil {
+		return nil, err
+	}
+	// Linked audios have no stored file to read.
+	key := media.NormalizeKey(audio.AudioURL)
+	if key == "" {
+		return nil, nil
+	}
+	var existing int64
+	if err := s.db.Model(&audioModel.AudioChapter{}).Where("audio_id = ?", audioID).Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, nil
+	}
+
+	src, err := s.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	frames, err := utils.ID3Chapters(src)
+	src.Close()
+	if err != nil || len(frames) == 0 {
+		return nil, err
+	}
+
+	chapters := make([]audioModel.AudioChapter, 0, len(frames))
+	var images []string
+	for i, frame := range frames {
+		if i > 0 && frame.StartMs == frames[i-1].StartMs {
+			continue
+		}
+		chapter := audioModel.AudioChapter{AudioID: audioID, StartMs: frame.StartMs, Title: frame.Title}
+		if chapter.Title == "" {
+			chapter.Title = fmt.Sprintf("Chapter %d", len(chapters)+1)
+		}
+		if isWebLink(frame.URL) {
+			chapter.URL = frame.URL
+		}
+		// Tags without end times often write 0 or 0xFFFFFFFF.
+		if frame.EndMs > frame.StartMs && frame.EndMs != 0xFFFFFFFF {
+			end := frame.EndMs
+			chapter.EndMs = &end
+		}
+		if len(frame.Image) > 0 {
+			asset, err := s.ingest.Ingest(media.KindImage, "chapters", bytes.NewReader(frame.Image))
+			if err != nil {
+				utils.Log.Warn("[Chapters] Skipping unreadable ID3 chapter picture", zap.Uint("audio_id", audioID), zap.Error(err))
+			} else {
+				chapter.Image, chapter.ImageHash, chapter.ImageVariants = asset.Path, asset.Hash, asset.Variants
+				images = append(images, append(asset.Variants.Keys(), asset.Path)...)
+			}
+		}
+		chapters = append(chapters, chapter)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// An admin may have added chapters while the file was being read.
+		var count int64
+		if err := tx.Model(&audioModel.AudioChapter{}).Where("audio_id = ?", audioID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			chapters = nil
+			return nil
+		}
+		return tx.Create(&chapters).Error
+	})
+	if err != nil || len(chapters) == 0 {
+		s.cleanup.Release(images...)
+		return nil, err
+	}
+	return chapters, nil
+}
+
+// PodcastChapters renders the audio's chapters as a Podcasting 2.0 JSON
+// chapters document.
+func (s *ChapterService) PodcastChapters(audioID uint) (*audioModel.PodcastChapters, error) {
+	chapters, err := s.List(audioID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := audioModel.PodcastChapters{Version: audioModel.PodcastChaptersVersion, Chapters: make([]audioModel.PodcastChapter, 0, len(chapters))}
+	for _, c := range chapters {
+		chapter := audioModel.PodcastChapter{StartTime: seconds(c.StartMs), Title: c.Title, URL: c.URL}
+		if c.EndMs != nil {
+			end := seconds(*c.EndMs)
+			chapter.EndTime = &end
+		}
+		if c.Image != "" {
+			chapter.Img = s.store.URL(media.NormalizeKey(c.Image))
+		}
+		doc.Chapters = append(doc.Chapters, chapter)
+	}
+	return &doc, nil
+}
+
+func seconds(ms int) float64 {
+	return float64(ms) / 1000
+}
@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"fmt"
+
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+)
+
+// numberEpisode gives an episode without a number the next free one in its
+// season and refuses numbers another episode already has. Audios outside a
+// show carry no season or episode.
+func (s *AdminAudioService) numberEpisode(a *audioModel.Audio) error {
+	if a.ShowID == nil {
+		a.SeasonNumber, a.EpisodeNumber = 0, 0
+		return nil
+	}
+	if a.SeasonNumber < 0 || a.EpisodeNumber < 0 {
+		return inputError("season and episode numbers cannot be negative")
+	}
+
+	inSeason := s.db.Model(&audioModel.Audio{}).
+		Where("show_id = ? AND season_number = ? AND id <> ?", *a.ShowID, a.SeasonNumber, a.ID)
+
+	if a.EpisodeNumber == 0 {
+		var last int
+		if err := inSeason.Select("COALESCE(MAX(episode_number), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		a.EpisodeNumber = last + 1
+		return nil
+	}
+
+	var taken int64
+	if err := inSeason.Where("episode_number = ?", a.EpisodeNumber).Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		return inputError(fmt.Sprintf("episode %d already exists in season %d of this show", a.EpisodeNumber, a.SeasonNumber))
+	}
+	return nil
+}
+
+// applyEpisode renumbers an audio whose show, season or episode an update
+// changes and writes the result back into updates. Moving to another show or
+// season without an explicit number appends it to the end.
+func (s *AdminAudioService) applyEpisode(current audioModel.Audio, updates map[string]interface{}) error {
+	showID, showSet := updates["show_id"]
+	season, seasonSet := updates["season_number"].(int)
+	episode, episodeSet := updates["episode_number"].(int)
+	if !showSet && !seasonSet && !episodeSet {
+		return nil
+	}
+
+	next := current
+	if showSet {
+		next.ShowID, _ = showID.(*uint)
+	}
+	if seasonSet {
+		next.SeasonNumber = season
+	}
+	if episodeSet {
+		next.EpisodeNumber = episode
+	} else if !sameShow(current.ShowID, next.ShowID) || next.SeasonNumber != current.SeasonNumber {
+		next.EpisodeNumber = 0
+	}
+
+	if err := s.numberEpisode(&next); err != nil {
+		return err
+	}
+	updates["show_id"] = next.ShowID
+	updates["season_number"] = next.SeasonNumber
+	updates["episode_number"] = next.EpisodeNumber
+	return nil
+}
+
+func sameShow(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
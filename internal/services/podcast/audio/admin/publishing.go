@@ -0,0 +1,125 @@
+package admin
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	"mqfm-backend/internal/utils"
+)
+
+// InputError is a request the service refuses because of what was asked, as
+// opposed to a failure while doing it.
+type InputError struct {
+	Message string
+}
+
+func (e *InputError) Error() string {
+	return e.Message
+}
+
+func inputError(message string) error {
+	return &InputError{Message: message}
+}
+
+// Schedule is the publishing part of a create or update request. Empty fields
+// keep the audio's current values.
+type Schedule struct {
+	Status           string
+	PublishAt        *time.Time
+	UnpublishAt      *time.Time
+	ClearUnpublishAt bool
+}
+
+func (sched Schedule) empty() bool {
+	return sched.Status == "" && sched.PublishAt == nil && sched.UnpublishAt == nil && !sched.ClearUnpublishAt
+}
+
+// apply moves a to the requested state. Without an explicit status, a publish
+// time schedules the audio and a new audio is published straight away, as
+// before statuses existed. A scheduled time already in the past publishes.
+func (sched Schedule) apply(a *audioModel.Audio, now time.Time) error {
+	if sched.PublishAt != nil {
+		a.PublishAt = sched.PublishAt
+	}
+	if sched.ClearUnpublishAt {
+		a.UnpublishAt = nil
+	} else if sched.UnpublishAt != nil {
+		a.UnpublishAt = sched.UnpublishAt
+	}
+
+	status := sched.Status
+	if status == "" {
+		switch {
+		case sched.PublishAt != nil:
+			status = audioModel.StatusScheduled
+		case a.Status != "":
+			status = a.Status
+		default:
+			status = audioModel.StatusPublished
+		}
+	}
+
+	if status == audioModel.StatusScheduled {
+		if a.PublishAt == nil {
+			return inputError("publish_at is required to schedule an audio")
+		}
+		if !a.PublishAt.After(now) {
+			status = audioModel.StatusPublished
+		}
+	}
+	if status == audioModel.StatusPublished || status == audioModel.StatusUnlisted {
+		if a.PublishedAt == nil {
+			published := now
+			if a.PublishAt != nil && a.PublishAt.Before(now) {
+				published = *a.PublishAt
+			}
+			a.PublishedAt = &published
+		}
+	}
+
+	if a.UnpublishAt != nil {
+		if status == audioModel.StatusScheduled && !a.UnpublishAt.After(*a.PublishAt) {
+			return inputError("unpublish_at must be after publish_at")
+		}
+		if sched.UnpublishAt != nil && !a.UnpublishAt.After(now) {
+			return inputError("unpublish_at must be in the future")
+		}
+	}
+
+	a.Status = status
+	return nil
+}
+
+// ApplySchedule publishes scheduled audios whose time has come and archives
+// those past their unpublish time. It runs from the scheduler in main.
+func (s *AdminAudioService) ApplySchedule() error {
+	now := time.Now()
+
+	published := s.db.Model(&audioModel.Audio{}).
+		Where("status = ? AND publish_at <= ?", audioModel.StatusScheduled, now).
+		Updates(map[string]interface{}{
+			"status":       audioModel.StatusPublished,
+			"published_at": gorm.Expr("publish_at"),
+		})
+	if published.Error != nil {
+		return published.Error
+	}
+
+	archived := s.db.Model(&audioModel.Audio{}).
+		Where("status IN ? AND unpublish_at <= ?", []string{audioModel.StatusPublished, audioModel.StatusUnlisted}, now).
+		Update("status", audioModel.StatusArchived)
+	if archived.Error != nil {
+		return archived.Error
+	}
+
+	if published.RowsAffected > 0 || archived.RowsAffected > 0 {
+		utils.Log.Info("[Publishing] Schedule applied",
+			zap.Int64("published", published.RowsAffected),
+			zap.Int64("archived", archived.RowsAffected),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	adminModel "mqfm-backend/internal/models/auth/admin"
+	notificationModel "mqfm-backend/internal/models/notification"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	notificationService "mqfm-backend/internal/services/notification"
+	"mqfm-backend/internal/utils"
+)
+
+// ReviewService moves producer submissions through submit, approve and
+// reject, keeping a history of each step and notifying the people involved.
+type ReviewService struct {
+	db            *gorm.DB
+	notifications *notificationService.NotificationService
+}
+
+func NewReviewService(db *gorm.DB, notifications *notificationService.NotificationService) *ReviewService {
+	return &ReviewService{db: db, notifications: notifications}
+}
+
+// Pending returns audios waiting for a reviewer, oldest submission first.
+func (s *ReviewService) Pending() ([]audioModel.Audio, error) {
+	var audios []audioModel.Audio
+	if err := s.db.Where("review_status = ?", audioModel.ReviewSubmitted).Order("updated_at").Find(&audios).Error; err != nil {
+		return nil, err
+	}
+	return audios, nil
+}
+
+// Submit asks for a draft to be reviewed. Rejected audios can be submitted
+// again once they are fixed.
+func (s *ReviewService) Submit(audioID, actorID uint, comment string) (*audioModel.Audio, error) {
+	audio, err := s.transition(audioID, actorID, audioModel.ReviewSubmitted, comment, func(a *audioModel.Audio) error {
+		if a.Status != audioModel.StatusDraft {
+			return errors.New("only drafts can be submitted for review")
+		}
+		if a.ReviewStatus == audioModel.ReviewSubmitted {
+			return errors.New("audio is already waiting for review")
+		}
+		if a.ReviewStatus == audioModel.ReviewApproved {
+			return errors.New("audio has already been approved")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifications.NotifyRoles(notificationModel.TypeReviewSubmitted,
+		fmt.Sprintf("%q was submitted for review", audio.Title),
+		&audio.ID, actorID, adminModel.RoleReviewer, adminModel.RoleAdmin)
+	return audio, nil
+}
+
+// Approve accepts a submission and releases it according to sched, which
+// publishes immediately when it is empty.
+func (s *ReviewService) Approve(audioID, reviewerID uint, comment string, sched Schedule) (*audioModel.Audio, error) {
+	if sched.Status == "" && sched.PublishAt == nil {
+		sched.Status = audioModel.StatusPublished
+	}
+	if sched.Status == audioModel.StatusDraft {
+		return nil, errors.New("an approved audio cannot stay a draft")
+	}
+
+	audio, err := s.transition(audioID, reviewerID, audioModel.ReviewApproved, comment, func(a *audioModel.Audio) error {
+		if a.ReviewStatus != audioModel.ReviewSubmitted {
+			return errors.New("audio is not waiting for review")
+		}
+		return sched.apply(a, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifySubmitter(audio, reviewerID, notificationModel.TypeReviewApproved,
+		fmt.Sprintf("%q was approved", audio.Title), comment)
+	return audio, nil
+}
+
+// Reject sends a submission back to its producer with the reviewer's comment.
+func (s *ReviewService) Reject(audioID, reviewerID uint, comment string) (*audioModel.Audio, error) {
+	if comment == "" {
+		return nil, errors.New("a comment is required when rejecting")
+	}
+
+	audio, err := s.transition(audioID, reviewerID, audioModel.ReviewRejected, comment, func(a *audioModel.Audio) error {
+		if a.ReviewStatus != audioModel.ReviewSubmitted {
+			return errors.New("audio is not waiting for review")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifySubmitter(audio, reviewerID, notificationModel.TypeReviewRejected,
+		fmt.Sprintf("%q was rejected", audio.Title), comment)
+	return audio, nil
+}
+
+// History returns every review step for an audio, oldest first.
+func (s *ReviewService) History(audioID uint) ([]audioModel.AudioReview, error) {
+	var reviews []audioModel.AudioReview
+	if err := s.db.Where("audio_id = ?", audioID).Order("id").Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// transition checks the audio with allowed, moves it to the review state to
+// and records the step, all in one transaction.
+func (s *ReviewService) transition(audioID, actorID uint, to, comment string, allowed func(*audioModel.Audio) error) (*audioModel.Audio, error) {
+	var audio audioModel.Audio
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&audio, audioID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("audio not found")
+			}
+			return err
+		}
+
+		from := audio.ReviewStatus
+		if err := allowed(&audio); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&audioModel.Audio{}).Where("id = ?", audio.ID).Updates(map[string]interface{}{
+			"review_status": to,
+			"status":        audio.Status,
+			"publish_at":    audio.PublishAt,
+			"unpublish_at":  audio.UnpublishAt,
+			"published_at":  audio.PublishedAt,
+		}).Error; err != nil {
+			return err
+		}
+		audio.ReviewStatus = to
+
+		return tx.Create(&audioModel.AudioReview{
+			AudioID:    audio.ID,
+			ActorID:    actorID,
+			FromStatus: from,
+			ToStatus:   to,
+			Comment:    comment,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Log.Info("[Review] Audio review status changed",
+		zap.Uint("audio_id", audio.ID),
+		zap.Uint("actor_id", actorID),
+		zap.String("status", to),
+	)
+	return &audio, nil
+}
+
+func (s *ReviewService) notifySubmitter(audio *audioModel.Audio, reviewerID uint, kind, message, comment string) {
+	if audio.CreatedBy == 0 || audio.CreatedBy == reviewerID {
+		return
+	}
+	if comment != "" {
+		message += ": " + comment
+	}
+	s.notifications.Notify(kind, message, &audio.ID, audio.CreatedBy)
+}
@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	categoryModel "mqfm-backend/internal/models/category/admin"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	showModel "mqfm-backend/internal/models/podcast/show"
+	revisionModel "mqfm-backend/internal/models/revision"
+)
+
+// revisionFields are the audio fields kept in its revision history. Files are
+// left out since replaced ones are deleted, and so is publishing state, which
+// only changes through the schedule and review.
+func revisionFields(a audioModel.Audio) map[string]interface{} {
+	return map[string]interface{}{
+		"title":          a.Title,
+		"description":    a.Description,
+		"category_id":    a.CategoryID,
+		"show_id":        a.ShowID,
+		"season_number":  a.SeasonNumber,
+		"episode_number": a.EpisodeNumber,
+	}
+}
+
+// Revisions returns the audio's revision history, newest first.
+func (s *AdminAudioService) Revisions(id uint) ([]revisionModel.Revision, error) {
+	if _, err := s.Preview(id); err != nil {
+		return nil, err
+	}
+	return s.revisions.History(revisionModel.EntityAudio, id)
+}
+
+// DiffRevisions lists the fields that differ between two revisions.
+func (s *AdminAudioService) DiffRevisions(id uint, from, to int) (revisionModel.Changes, error) {
+	return s.revisions.Diff(revisionModel.EntityAudio, id, from, to)
+}
+
+// Revert sets the audio's tracked fields back to how they were in revision
+// number. The revert is recorded as a new revision. It is refused when the
+// revision's category or show has since been trashed or purged.
+func (s *AdminAudioService) Revert(id uint, number int, actorID uint) (*audioModel.Audio, error) {
+	revision, err := s.revisions.Find(revisionModel.EntityAudio, id, number)
+	if err != nil {
+		return nil, err
+	}
+
+	var target audioModel.Audio
+	if err := s.db.First(&target, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("audio not found")
+		}
+		return nil, err
+	}
+	if err := revision.Snapshot.Decode(&target); err != nil {
+		return nil, err
+	}
+	if err := s.checkLive(&categoryModel.Category{}, "category", target.CategoryID); err != nil {
+		return nil, err
+	}
+	if err := s.checkLive(&showModel.Show{}, "show", target.ShowID); err != nil {
+		return nil, err
+	}
+
+	return s.update(id, actorID, revisionFields(target), Schedule{}, &number)
+}
+
+// checkLive makes sure the referenced record exists and is not in the trash.
+// A nil id is always valid.
+func (s *AdminAudioService) checkLive(model interface{}, label string, id *uint) error {
+	if id == nil {
+		return nil
+	}
+	if err := s.db.Select("id").First(model, *id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return inputError(fmt.Sprintf("the %s of this revision no longer exists", label))
+		}
+		return err
+	}
+	return nil
+}
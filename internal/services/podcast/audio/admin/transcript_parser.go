@@ -0,0 +1,201 @@
+package admin
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+)
+
+// TranscriptError is a transcript file that could not be parsed. Line is the
+// 1-based line the problem was found on.
+type TranscriptError struct {
+	Line    int
+	Message string
+}
+
+func (e *TranscriptError) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Cue is a parsed transcript line, timed in milliseconds.
+type Cue struct {
+	StartMs int
+	EndMs   int
+	Text    string
+}
+
+var (
+	// timestampPattern matches WebVTT (00:01.500, 01:00:01.500) and SRT
+	// (01:00:01,500) timestamps.
+	timestampPattern = regexp.MustCompile(`^(?:(\d+):)?(\d{2}):(\d{2})[.,](\d{3})$`)
+	markupPattern    = regexp.MustCompile(`<[^>]*>|\{\\[^}]*\}`)
+)
+
+// DetectTranscriptFormat tells WebVTT from SRT by the WEBVTT header, falling
+// back to the file extension.
+func DetectTranscriptFormat(filename string, data []byte) (string, error) {
+	if strings.HasPrefix(strings.TrimPrefix(string(data), "\ufeff"), "WEBVTT") {
+		return audioModel.TranscriptVTT, nil
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".srt":
+		return audioModel.TranscriptSRT, nil
+	case ".vtt":
+		return audioModel.TranscriptVTT, nil
+	}
+	return "", &TranscriptError{Message: "transcript must be a WebVTT (.vtt) or SubRip (.srt) file"}
+}
+
+// ParseTranscript reads the cues of a WebVTT or SRT file. Styling markup is
+// stripped from cue text; notes, styles and regions are skipped.
+func ParseTranscript(format string, data []byte) ([]Cue, error) {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(text, "\n")
+
+	i := 0
+	if format == audioModel.TranscriptVTT {
+		if !strings.HasPrefix(lines[0], "WEBVTT") {
+			return nil, &TranscriptError{Line: 1, Message: "WebVTT files must start with WEBVTT"}
+		}
+		// The header runs up to the first blank line.
+		for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+			i++
+		}
+	}
+
+	var cues []Cue
+	for i < len(lines) {
+		if strings.TrimSpace(lines[i]) == "" {
+			i++
+			continue
+		}
+
+		// A block runs up to the next blank line.
+		start := i
+		for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+			i++
+		}
+		block := lines[start:i]
+
+		if format == audioModel.TranscriptVTT && isVTTMetadata(block[0]) {
+			continue
+		}
+
+		// The timing line may follow a cue identifier, or an SRT counter.
+		timing := 0
+		if !strings.Contains(block[0], "-->") {
+			timing = 1
+		}
+		if timing >= len(block) || !strings.Contains(block[timing], "-->") {
+			return nil, &TranscriptError{Line: start + 1, Message: "expected a cue timing line like 00:00:01.000 --> 00:00:04.000"}
+		}
+
+		cue, err := parseTiming(block[timing])
+		if err != nil {
+			return nil, &TranscriptError{Line: start + timing + 1, Message: err.Error()}
+		}
+		cue.Text = cleanCueText(block[timing+1:])
+		if cue.Text == "" {
+			continue
+		}
+		cues = append(cues, cue)
+	}
+
+	if len(cues) == 0 {
+		return nil, &TranscriptError{Message: "transcript has no cues"}
+	}
+	// SRT files are not always written in order.
+	sort.SliceStable(cues, func(a, b int) bool { return cues[a].StartMs < cues[b].StartMs })
+	return cues, nil
+}
+
+func isVTTMetadata(line string) bool {
+	for _, keyword := range []string{"NOTE", "STYLE", "REGION"} {
+		if line == keyword || strings.HasPrefix(line, keyword+" ") || strings.HasPrefix(line, keyword+"\t") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTiming reads "start --> end", ignoring any WebVTT cue settings after
+// the end time.
+func parseTiming(line string) (Cue, error) {
+	parts := strings.SplitN(line, "-->", 2)
+	end := strings.Fields(parts[1])
+	if len(end) == 0 {
+		return Cue{}, fmt.Errorf("cue timing %q has no end time", line)
+	}
+
+	startMs, err := parseTimestamp(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return Cue{}, err
+	}
+	endMs, err := parseTimestamp(end[0])
+	if err != nil {
+		return Cue{}, err
+	}
+	if endMs <= startMs {
+		return Cue{}, fmt.Errorf("cue ends at or before it starts (%s)", strings.TrimSpace(line))
+	}
+	return Cue{StartMs: startMs, EndMs: endMs}, nil
+}
+
+func parseTimestamp(value string) (int, error) {
+	m := timestampPattern.FindStringSubmatch(value)
+	if m == nil {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	hours := 0
+	if m[1] != "" {
+		hours, _ = strconv.Atoi(m[1])
+	}
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.Atoi(m[3])
+	millis, _ := strconv.Atoi(m[4])
+	if minutes > 59 || seconds > 59 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	return ((hours*60+minutes)*60+seconds)*1000 + millis, nil
+}
+
+// cleanCueText joins the cue's lines and strips tags such as <v Speaker>,
+// <i> and SRT {\an8} positioning.
+func cleanCueText(lines []string) string {
+	cleaned := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(html.UnescapeString(markupPattern.ReplaceAllString(line, "")))
+		if line != "" {
+			cleaned = append(cleaned, line)
+		}
+	}
+	return strings.Join(cleaned, "\n")
+}
+
+// FormatVTT writes cues as a WebVTT file.
+func FormatVTT(cues []audioModel.TranscriptCue) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "\n%d\n%s --> %s\n%s\n", cue.Position, vttTimestamp(cue.StartMs), vttTimestamp(cue.EndMs), escapeVTT(cue.Text))
+	}
+	return b.String()
+}
+
+func vttTimestamp(ms int) string {
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+func escapeVTT(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
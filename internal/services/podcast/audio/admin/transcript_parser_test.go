@@ -0,0 +1,140 @@
+package admin
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+)
+
+func TestParseTranscript(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   []Cue
+	}{
+		{
+			name:   "vtt",
+			format: audioModel.TranscriptVTT,
+			data:   "WEBVTT\n\n00:01.000 --> 00:04.500\nBismillah\n\n01:00:00.000 --> 01:00:02.250 align:start position:10%\nAlhamdulillah\n",
+			want: []Cue{
+				{StartMs: 1000, EndMs: 4500, Text: "Bismillah"},
+				{StartMs: 3600000, EndMs: 3602250, Text: "Alhamdulillah"},
+			},
+		},
+		{
+			name:   "vtt with bom, crlf and header metadata",
+			format: audioModel.TranscriptVTT,
+			data:   "\ufeffWEBVTT - Kajian\r\nKind: captions\r\n\r\n00:00:01.000 --> 00:00:02.000\r\nSatu\r\ndua\r\n",
+			want:   []Cue{{StartMs: 1000, EndMs: 2000, Text: "Satu\ndua"}},
+		},
+		{
+			name:   "vtt cue identifiers",
+			format: audioModel.TranscriptVTT,
+			data:   "WEBVTT\n\nintro\n00:00.000 --> 00:01.000\nPembuka\n\n2\n00:01.000 --> 00:02.000\nIsi\n",
+			want: []Cue{
+				{StartMs: 0, EndMs: 1000, Text: "Pembuka"},
+				{StartMs: 1000, EndMs: 2000, Text: "Isi"},
+			},
+		},
+		{
+			name:   "vtt note, style and region blocks",
+			format: audioModel.TranscriptVTT,
+			data: "WEBVTT\n\nNOTE this is\na comment\n\nSTYLE\n::cue { color: red }\n\nREGION\nid:left\n\n" +
+				"NOTE\n\n00:00.000 --> 00:01.000\nTeks\n",
+			want: []Cue{{StartMs: 0, EndMs: 1000, Text: "Teks"}},
+		},
+		{
+			name:   "vtt markup",
+			format: audioModel.TranscriptVTT,
+			data:   "WEBVTT\n\n00:00.000 --> 00:01.000\n<v Ustadz>Ini <i>penting</i></v> &amp; <c.loud>jelas</c>\n\n00:01.000 --> 00:02.000\n<b></b>\n",
+			want:   []Cue{{StartMs: 0, EndMs: 1000, Text: "Ini penting & jelas"}},
+		},
+		{
+			name:   "srt",
+			format: audioModel.TranscriptSRT,
+			data:   "1\r\n00:00:01,000 --> 00:00:02,000\r\n{\\an8}<font color=\"red\">Atas</font>\r\n\r\n2\r\n00:00:02,000 --> 00:00:03,500\r\nBawah\r\n",
+			want: []Cue{
+				{StartMs: 1000, EndMs: 2000, Text: "Atas"},
+				{StartMs: 2000, EndMs: 3500, Text: "Bawah"},
+			},
+		},
+		{
+			name:   "srt out of order",
+			format: audioModel.TranscriptSRT,
+			data:   "2\n00:00:05,000 --> 00:00:06,000\nKedua\n\n1\n00:00:01,000 --> 00:00:02,000\nPertama\n\n\n\n3\n00:00:09,000 --> 00:00:10,000\nKetiga",
+			want: []Cue{
+				{StartMs: 1000, EndMs: 2000, Text: "Pertama"},
+				{StartMs: 5000, EndMs: 6000, Text: "Kedua"},
+				{StartMs: 9000, EndMs: 10000, Text: "Ketiga"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTranscript(tt.format, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("ParseTranscript: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTranscriptErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		line   int
+	}{
+		{"vtt without header", audioModel.TranscriptVTT, "00:00.000 --> 00:01.000\nTeks\n", 1},
+		{"missing timing line", audioModel.TranscriptVTT, "WEBVTT\n\nhalo\ndunia\n", 3},
+		{"end before start", audioModel.TranscriptSRT, "1\n00:00:05,000 --> 00:00:04,000\nTeks\n", 2},
+		{"end equal to start", audioModel.TranscriptVTT, "WEBVTT\n\n00:00.000 --> 00:01.000\nA\n\n00:02.000 --> 00:02.000\nB\n", 6},
+		{"missing end time", audioModel.TranscriptSRT, "1\n00:00:01,000 -->\nTeks\n", 2},
+		{"invalid timestamp", audioModel.TranscriptVTT, "WEBVTT\n\nid\n00:61.000 --> 01:02.000\nTeks\n", 4},
+		{"no cues", audioModel.TranscriptVTT, "WEBVTT\n\nNOTE kosong\n", 0},
+		{"empty file", audioModel.TranscriptSRT, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cues, err := ParseTranscript(tt.format, []byte(tt.data))
+			var terr *TranscriptError
+			if !errors.As(err, &terr) {
+				t.Fatalf("got %+v, %v, want a TranscriptError", cues, err)
+			}
+			if terr.Line != tt.line {
+				t.Errorf("error %q is on line %d, want %d", terr, terr.Line, tt.line)
+			}
+		})
+	}
+}
+
+func TestFormatVTTRoundTrip(t *testing.T) {
+	cues := []audioModel.TranscriptCue{
+		{Position: 1, StartMs: 0, EndMs: 1500, Text: "Bismillah"},
+		{Position: 2, StartMs: 61500, EndMs: 3723004, Text: "Tanya <jawab> & diskusi\nbaris kedua"},
+	}
+
+	vtt := FormatVTT(cues)
+	got, err := ParseTranscript(audioModel.TranscriptVTT, []byte(vtt))
+	if err != nil {
+		t.Fatalf("ParseTranscript of FormatVTT output: %v\n%s", err, vtt)
+	}
+	if len(got) != len(cues) {
+		t.Fatalf("got %d cues, want %d", len(got), len(cues))
+	}
+	for i, cue := range cues {
+		want := Cue{StartMs: cue.StartMs, EndMs: cue.EndMs, Text: cue.Text}
+		if got[i] != want {
+			t.Errorf("cue %d is %+v, want %+v", i, got[i], want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package admin
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+)
+
+const (
+	// DefaultTranscriptLanguage is used when an upload names no language.
+	DefaultTranscriptLanguage = "id"
+	maxTranscriptBytes        = 5 << 20
+	// matchesPerAudio caps the jump-to-timestamp results on each search hit.
+	matchesPerAudio = 5
+)
+
+var (
+	ErrTranscriptNotFound = errors.New("transcript not found")
+	languagePattern       = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+)
+
+// TranscriptService stores audio transcripts as timed cues.
+type TranscriptService struct {
+	db *gorm.DB
+}
+
+func NewTranscriptService(db *gorm.DB) *TranscriptService {
+	return &TranscriptService{db: db}
+}
+
+// NormalizeLanguage lower-cases a BCP 47 tag such as "id" or "en-US", using
+// the default language when it is empty.
+func NormalizeLanguage(language string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		return DefaultTranscriptLanguage, nil
+	}
+	if !languagePattern.MatchString(language) {
+		return "", inputError("language must be a language tag such as id or en-us")
+	}
+	return language, nil
+}
+
+// Upload parses a WebVTT or SRT file and stores it as the audio's transcript
+// in language, replacing any it already had.
+func (s *TranscriptService) Upload(audioID, actorID uint, language, filename string, r io.Reader) (*audioModel.AudioTranscript, error) {
+	language, err := NormalizeLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Select("id").First(&audioModel.Audio{}, audioID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("audio not found")
+		}
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxTranscriptBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxTranscriptBytes {
+		return nil, inputError("transcript exceeds the maximum size of 5 MB")
+	}
+	format, err := DetectTranscriptFormat(filename, data)
+	if err != nil {
+		return nil, err
+	}
+	cues, err := ParseTranscript(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	transcript := audioModel.AudioTranscript{AudioID: audioID, Language: language}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("audio_id = ? AND language = ?", audioID, language).FirstOrInit(&transcript).Error; err != nil {
+			return err
+		}
+		transcript.Format = format
+		transcript.CueCount = len(cues)
+		transcript.CreatedBy = actorID
+		if err := tx.Save(&transcript).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("transcript_id = ?", transcript.ID).Delete(&audioModel.TranscriptCue{}).Error; err != nil {
+			return err
+		}
+		rows := make([]audioModel.TranscriptCue, 0, len(cues))
+		for i, cue := range cues {
+			rows = append(rows, audioModel.TranscriptCue{
+				TranscriptID: transcript.ID,
+				AudioID:      audioID,
+				Position:     i + 1,
+				StartMs:      cue.StartMs,
+				EndMs:        cue.EndMs,
+				Text:         cue.Text,
+			})
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transcript, nil
+}
+
+// List returns the audio's transcripts without their cues.
+func (s *TranscriptService) List(audioID uint) ([]audioModel.AudioTranscript, error) {
+	transcripts := []audioModel.AudioTranscript{}
+	if err := s.db.Where("audio_id = ?", audioID).Order("language").Find(&transcripts).Error; err != nil {
+		return nil, err
+	}
+	return transcripts, nil
+}
+
+// Find returns the audio's transcript in language with its cues in order.
+func (s *TranscriptService) Find(audioID uint, language string) (*audioModel.AudioTranscript, error) {
+	language, err := NormalizeLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+
+	var transcript audioModel.AudioTranscript
+	err = s.db.Preload("Cues", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Where("audio_id = ? AND language = ?", audioID, language).
+		First(&transcript).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTranscriptNotFound
+		}
+		return nil, err
+	}
+	return &transcript, nil
+}
+
+// Delete removes the audio's transcript in language.
+func (s *TranscriptService) Delete(audioID uint, language string) error {
+	transcript, err := s.Find(audioID, language)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transcript_id = ?", transcript.ID).Delete(&audioModel.TranscriptCue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(transcript).Error
+	})
+}
+
+// transcriptMatches finds the cues matching query in each audio's
+// transcripts, earliest first, and attaches them to the audios.
+func (s *AdminAudioService) transcriptMatches(audios []audioModel.Audio, query string) error {
+	if len(audios) == 0 {
+		return nil
+	}
+	index := make(map[uint]int, len(audios))
+	ids := make([]uint, 0, len(audios))
+	for i, a := range audios {
+		index[a.ID] = i
+		ids = append(ids, a.ID)
+	}
+
+	var rows []struct {
+		audioModel.TranscriptMatch
+		AudioID uint
+	}
+	err := s.db.Table("transcript_cues").
+		Select("transcript_cues.audio_id, audio_transcripts.language, transcript_cues.start_ms, transcript_cues.end_ms, transcript_cues.text").
+		Joins("JOIN audio_transcripts ON audio_transcripts.id = transcript_cues.transcript_id").
+		Where("transcript_cues.audio_id IN ? AND transcript_cues.text LIKE ?", ids, "%"+query+"%").
+		Order("transcript_cues.audio_id, audio_transcripts.language, transcript_cues.start_ms").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		a := &audios[index[row.AudioID]]
+		if len(a.TranscriptMatches) < matchesPerAudio {
+			a.TranscriptMatches = append(a.TranscriptMatches, row.TranscriptMatch)
+		}
+	}
+	return nil
+}
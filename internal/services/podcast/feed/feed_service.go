@@ -0,0 +1,302 @@
+package feed
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"mime"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	categoryModel "mqfm-backend/internal/models/category/admin"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	feedModel "mqfm-backend/internal/models/podcast/feed"
+	"mqfm-backend/internal/storage"
+)
+
+// podcastNamespace is the UUIDv5 namespace defined by Podcasting 2.0 for podcast:guid.
+var podcastNamespace = uuid.MustParse("ead4c236-bf58-58c6-a2c6-a6b28d128cb6")
+
+// releaseOrder sorts newest release first, falling back to creation time for
+// audios published before the publishing workflow existed.
+const releaseOrder = "COALESCE(published_at, created_at) DESC"
+
+// Config holds the channel-level metadata that is not stored in the database.
+type Config struct {
+	BaseURL     string
+	Title       string
+	Description string
+	Author      string
+	OwnerEmail  string
+	ImageURL    string
+	Language    string
+	Category    string
+	Subcategory string
+}
+
+// Feed is a rendered RSS document plus the validators used for conditional GETs.
+type Feed struct {
+	Body         []byte
+	ETag         string
+	LastModified time.Time
+}
+
+type FeedService struct {
+	db    *gorm.DB
+	store storage.Storage
+	cfg   Config
+	// started dates feeds that never had an audio, so their validators stay
+	// put between requests.
+	started time.Time
+}
+
+func NewFeedService(db *gorm.DB, store storage.Storage, cfg Config) *FeedService {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &FeedService{db: db, store: store, cfg: cfg, started: time.Now()}
+}
+
+func (s *FeedService) GlobalFeed() (*Feed, error) {
+	var audios []audioModel.Audio
+	if err := s.db.Scopes(audioModel.Listed).Where("audio_url <> ''").Order(releaseOrder).Find(&audios).Error; err != nil {
+		return nil, err
+	}
+
+	lastModified, err := s.lastModified(func(db *gorm.DB) *gorm.DB { return db })
+	if err != nil {
+		return nil, err
+	}
+
+	return s.render(s.cfg.Title, s.cfg.Description, s.cfg.BaseURL+"/api/feeds/", audios, lastModified)
+}
+
+func (s *FeedService) CategoryFeed(categoryID uint) (*Feed, error) {
+	var category categoryModel.Category
+	if err := s.db.First(&category, categoryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("category not found")
+		}
+		return nil, err
+	}
+
+	var audios []audioModel.Audio
+	if err := s.db.Scopes(audioModel.Listed).Where("category_id = ? AND audio_url <> ''", categoryID).Order(releaseOrder).Find(&audios).Error; err != nil {
+		return nil, err
+	}
+
+	description := category.Description
+	if description == "" {
+		description = s.cfg.Description
+	}
+	title := s.cfg.Title + " - " + category.Name
+	selfURL := s.cfg.BaseURL + "/api/feeds/categories/" + strconv.FormatUint(uint64(category.ID), 10)
+
+	lastModified, err := s.lastModified(func(db *gorm.DB) *gorm.DB { return db.Where("category_id = ?", categoryID) })
+	if err != nil {
+		return nil, err
+	}
+	if category.UpdatedAt.After(lastModified) {
+		lastModified = category.UpdatedAt
+	}
+
+	return s.render(title, description, selfURL, audios, lastModified)
+}
+
+func (s *FeedService) render(title, description, selfURL string, audios []audioModel.Audio, lastModified time.Time) (*Feed, error) {
+	chaptered, err := s.chaptered(audios)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]feedModel.Item, 0, len(audios))
+	for _, audio := range audios {
+		item := s.item(audio)
+		if chaptered[audio.ID] {
+			item.Chapters = &feedModel.Chapters{
+				URL:  s.cfg.BaseURL + "/api/audios/" + strconv.FormatUint(uint64(audio.ID), 10) + "/chapters.json",
+				Type: "application/json+chapters",
+			}
+		}
+		items = append(items, item)
+	}
+	if lastModified.IsZero() {
+		lastModified = s.started
+	}
+	lastModified = lastModified.UTC().Truncate(time.Second)
+
+	channel := feedModel.Channel{
+		Title:          title,
+		Link:           s.cfg.BaseURL,
+		Description:    description,
+		Language:       s.cfg.Language,
+		LastBuildDate:  lastModified.Format(time.RFC1123Z),
+		AtomLink:       feedModel.AtomLink{Href: selfURL, Rel: "self", Type: "application/rss+xml"},
+		ItunesAuthor:   s.cfg.Author,
+		ItunesSummary:  description,
+		ItunesType:     "episodic",
+		ItunesExplicit: "false",
+		ItunesOwner:    feedModel.ItunesOwner{Name: s.cfg.Author, Email: s.cfg.OwnerEmail},
+		ItunesCategory: feedModel.ItunesCategory{Text: s.cfg.Category},
+		PodcastGUID:    uuid.NewSHA1(podcastNamespace, []byte(stripScheme(selfURL))).String(),
+		PodcastLocked:  feedModel.PodcastLocked{Owner: s.cfg.OwnerEmail, Value: "yes"},
+		Items:          items,
+	}
+	if s.cfg.Subcategory != "" {
+		channel.ItunesCategory.Subcategory = &feedModel.ItunesCategory{Text: s.cfg.Subcategory}
+	}
+	if s.cfg.ImageURL != "" {
+		channel.ItunesImage = &feedModel.ItunesImage{Href: s.cfg.ImageURL}
+		channel.Image = &feedModel.Image{URL: s.cfg.ImageURL, Title: title, Link: s.cfg.BaseURL}
+	}
+
+	doc := feedModel.RSS{
+		Version:   "2.0",
+		ItunesNS:  "http://www.itunes.com/dtds/podcast-1.0.dtd",
+		PodcastNS: "https://podcastindex.org/namespace/1.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel:   channel,
+	}
+
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	body = append([]byte(xml.Header), body...)
+
+	sum := sha1.Sum(body)
+	return &Feed{
+		Body:         body,
+		ETag:         `"` + hex.EncodeToString(sum[:]) + `"`,
+		LastModified: lastModified,
+	}, nil
+}
+
+func (s *FeedService) item(audio audioModel.Audio) feedModel.Item {
+	item := feedModel.Item{
+		Title:          audio.Title,
+		Description:    feedModel.CDATA{Text: audio.Description},
+		GUID:           feedModel.GUID{Value: "mqfm-audio-" + strconv.FormatUint(uint64(audio.ID), 10)},
+		PubDate:        releasedAt(audio).UTC().Format(time.RFC1123Z),
+		ItunesTitle:    audio.Title,
+		ItunesDuration: audio.Duration,
+		ItunesExplicit: "false",
+		ItunesType:     "full",
+		ItunesSeason:   audio.SeasonNumber,
+		ItunesEpisode:  audio.EpisodeNumber,
+		Enclosure: feedModel.Enclosure{
+			URL:    s.mediaURL(audio.AudioURL),
+			Length: s.fileSize(audio),
+			Type:   mimeType(audio.AudioURL),
+		},
+	}
+	// Imported episodes keep their original GUID so subscribers don't see them twice.
+	if audio.GUID != nil && *audio.GUID != "" {
+		item.GUID.Value = *audio.GUID
+	}
+	if audio.Thumbnail != "" {
+		item.ItunesImage = &feedModel.ItunesImage{Href: s.mediaURL(audio.Thumbnail)}
+	}
+	return item
+}
+
+// lastModified is the latest change to any audio in scope or to its chapters.
+// Audios that have left the feed count too, through their updated_at when
+// archived or unlisted and their deleted_at when trashed, so removing the
+// newest episode still moves it forward.
+func (s *FeedService) lastModified(scope func(*gorm.DB) *gorm.DB) (time.Time, error) {
+	audios := func() *gorm.DB {
+		return scope(s.db.Unscoped().Model(&audioModel.Audio{}))
+	}
+
+	var updated, deleted, chapters []time.Time
+	if err := audios().Order("updated_at DESC").Limit(1).Pluck("updated_at", &updated).Error; err != nil {
+		return time.Time{}, err
+	}
+	if err := audios().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Limit(1).Pluck("deleted_at", &deleted).Error; err != nil {
+		return time.Time{}, err
+	}
+	err := s.db.Model(&audioModel.AudioChapter{}).
+		Where("audio_id IN (?)", audios().Select("id")).
+		Order("updated_at DESC").Limit(1).
+		Pluck("updated_at", &chapters).Error
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+	for _, t := range slices.Concat(updated, deleted, chapters) {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest, nil
+}
+
+// chaptered reports which of the audios have chapters.
+func (s *FeedService) chaptered(audios []audioModel.Audio) (map[uint]bool, error) {
+	ids := make([]uint, 0, len(audios))
+	for _, audio := range audios {
+		ids = append(ids, audio.ID)
+	}
+
+	var withChapters []uint
+	if len(ids) > 0 {
+		if err := s.db.Model(&audioModel.AudioChapter{}).Where("audio_id IN ?", ids).Distinct().Pluck("audio_id", &withChapters).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	chaptered := make(map[uint]bool, len(withChapters))
+	for _, id := range withChapters {
+		chaptered[id] = true
+	}
+	return chaptered, nil
+}
+
+// releasedAt is when the episode went public; audios from before the
+// publishing workflow only have their creation time.
+func releasedAt(audio audioModel.Audio) time.Time {
+	if audio.PublishedAt != nil {
+		return *audio.PublishedAt
+	}
+	return audio.CreatedAt
+}
+
+// mediaURL turns a stored key into an absolute URL.
+func (s *FeedService) mediaURL(key string) string {
+	if strings.HasPrefix(key, "http://") || strings.HasPrefix(key, "https://") {
+		return key
+	}
+	return s.store.URL(key)
+}
+
+func (s *FeedService) fileSize(audio audioModel.Audio) int64 {
+	if audio.FileSize > 0 {
+		return audio.FileSize
+	}
+	if info, err := s.store.Stat(audio.AudioURL); err == nil {
+		return info.Size
+	}
+	return 0
+}
+
+func mimeType(path string) string {
+	if t := mime.TypeByExtension(strings.ToLower(filepath.Ext(path))); t != "" {
+		return t
+	}
+	return "audio/mpeg"
+}
+
+func stripScheme(u string) string {
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	}
+	return strings.TrimRight(u, "/")
+}
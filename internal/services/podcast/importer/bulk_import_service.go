@@ -0,0 +1,594 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	categoryModel "mqfm-backend/internal/models/category/admin"
+	jobModel "mqfm-backend/internal/models/job"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	speakerModel "mqfm-backend/internal/models/podcast/speaker"
+	jobService "mqfm-backend/internal/services/job"
+	"mqfm-backend/internal/services/media"
+	audioService "mqfm-backend/internal/services/podcast/audio/admin"
+	"mqfm-backend/internal/services/podcast/transcode"
+	tagService "mqfm-backend/internal/services/tag"
+	"mqfm-backend/internal/utils"
+)
+
+const JobTypeBulkImport = "bulk_import"
+
+var (
+	ErrArchiveTooLarge = errors.New("archive exceeds the maximum size")
+	ErrNotResumable    = errors.New("only failed bulk imports can be resumed")
+	ErrArchiveGone     = errors.New("the archive for this import is no longer available")
+)
+
+// ManifestError is an archive refused before any import starts. Rows lists
+// the problems found in each manifest row.
+type ManifestError struct {
+	Message string
+	Rows    []BulkImportRowError
+}
+
+func (e *ManifestError) Error() string {
+	return e.Message
+}
+
+// BulkImportPayload is stored on the job so an interrupted run can resume.
+type BulkImportPayload struct {
+	Archive     string `json:"archive"`
+	ArchiveHash string `json:"archive_hash"`
+	Filename    string `json:"filename"`
+	Rows        int    `json:"rows"`
+}
+
+// BulkImportResult summarises a finished run.
+type BulkImportResult struct {
+	Imported int                  `json:"imported"`
+	Skipped  int                  `json:"skipped"`
+	Failed   []BulkImportRowError `json:"failed"`
+}
+
+type BulkImportRowError struct {
+	Row   int    `json:"row"`
+	Title string `json:"title"`
+	Error string `json:"error"`
+}
+
+// BulkImportRow is one entry of the manifest. File and Thumbnail are paths
+// inside the archive relative to the manifest. Category matches an ID, slug
+// or name; speakers must already exist, while missing tags are created.
+// PublishDate is RFC 3339 or YYYY-MM-DD; a future date schedules the audio.
+type BulkImportRow struct {
+	Row         int      `json:"-"`
+	File        string   `json:"file"`
+	Thumbnail   string   `json:"thumbnail"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Speakers    []string `json:"speakers"`
+	Tags        []string `json:"tags"`
+	PublishDate string   `json:"publish_date"`
+}
+
+// plannedRow is a manifest row resolved against the archive and database.
+type plannedRow struct {
+	BulkImportRow
+	audio      *zip.File
+	thumbnail  *zip.File
+	categoryID *uint
+	speakers   []speakerModel.Speaker
+	publishAt  *time.Time
+}
+
+// BulkImportService imports a catalogue from a ZIP archive holding the media
+// files and a manifest.csv or manifest.json describing each audio.
+type BulkImportService struct {
+	db         *gorm.DB
+	jobs       *jobService.JobService
+	audios     *audioService.AdminAudioService
+	tags       *tagService.TagService
+	transcoder *transcode.TranscodeService
+	ingest     *media.IngestService
+	maxSize    int64
+	active     sync.Map
+}
+
+func NewBulkImportService(db *gorm.DB, jobs *jobService.JobService, audios *audioService.AdminAudioService, tags *tagService.TagService, transcoder *transcode.TranscodeService, ingest *media.IngestService, maxSize int64) *BulkImportService {
+	return &BulkImportService{
+		db:         db,
+		jobs:       jobs,
+		audios:     audios,
+		tags:       tags,
+		transcoder: transcoder,
+		ingest:     ingest,
+		maxSize:    maxSize,
+	}
+}
+
+// Start stages the archive and checks every manifest row. Nothing is
+// imported unless all rows are valid; otherwise a *ManifestError lists the
+// problems. The import then runs as a background job.
+func (s *BulkImportService) Start(adminID uint, file *multipart.FileHeader) (uint, error) {
+	if file.Size > s.maxSize {
+		return 0, ErrArchiveTooLarge
+	}
+
+	staged, hash, err := media.StageArchive(file, "")
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := s.check(staged)
+	if err != nil {
+		os.Remove(staged)
+		return 0, err
+	}
+
+	payload := BulkImportPayload{
+		Archive:     filepath.Base(staged),
+		ArchiveHash: hash,
+		Filename:    file.Filename,
+		Rows:        rows,
+	}
+	job, err := s.jobs.Create(JobTypeBulkImport, adminID, payload)
+	if err != nil {
+		os.Remove(staged)
+		return 0, err
+	}
+
+	go s.run(job.ID, adminID, payload)
+	return job.ID, nil
+}
+
+// Resume runs a failed import again. Rows imported before it stopped are
+// recognised by their GUID and skipped.
+func (s *BulkImportService) Resume(jobID uint) error {
+	job, err := s.jobs.FindByID(jobID)
+	if err != nil {
+		return err
+	}
+	if job.Type != JobTypeBulkImport || job.Status != jobModel.StatusFailed {
+		return ErrNotResumable
+	}
+	return s.resume(job)
+}
+
+// ResumeUnfinished picks up imports cut short by a restart. It runs once
+// from main at startup.
+func (s *BulkImportService) ResumeUnfinished() error {
+	jobs, err := s.jobs.FindUnfinished(JobTypeBulkImport)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		if err := s.resume(&jobs[i]); err != nil {
+			utils.Log.Error("[Bulk Import] Failed to resume job", zap.Error(err), zap.Uint("job_id", jobs[i].ID))
+			s.jobs.Fail(jobs[i].ID, err, BulkImportResult{Failed: []BulkImportRowError{}})
+		}
+	}
+	return nil
+}
+
+func (s *BulkImportService) resume(job *jobModel.Job) error {
+	var payload BulkImportPayload
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return err
+	}
+	if _, err := os.Stat(media.ArchivePath(payload.Archive)); err != nil {
+		return ErrArchiveGone
+	}
+
+	go s.run(job.ID, job.CreatedBy, payload)
+	return nil
+}
+
+func (s *BulkImportService) run(jobID, adminID uint, payload BulkImportPayload) {
+	if _, running := s.active.LoadOrStore(jobID, true); running {
+		return
+	}
+	defer s.active.Delete(jobID)
+
+	s.jobs.Start(jobID)
+	utils.Log.Info("[Bulk Import] Job started",
+		zap.Uint("job_id", jobID),
+		zap.String("filename", payload.Filename),
+	)
+
+	result := BulkImportResult{Failed: []BulkImportRowError{}}
+
+	archive, err := zip.OpenReader(media.ArchivePath(payload.Archive))
+	if err != nil {
+		utils.Log.Error("[Bulk Import] Failed to open archive", zap.Error(err), zap.Uint("job_id", jobID))
+		s.jobs.Fail(jobID, err, result)
+		return
+	}
+	rows, base, err := readManifest(&archive.Reader)
+	if err != nil {
+		archive.Close()
+		s.jobs.Fail(jobID, err, result)
+		return
+	}
+
+	// The catalogue may have changed since the archive was checked, so rows
+	// are resolved again and any that no longer fit are reported as failed.
+	planned, invalid, err := s.plan(&archive.Reader, base, rows)
+	if err != nil {
+		archive.Close()
+		s.jobs.Fail(jobID, err, result)
+		return
+	}
+	result.Failed = append(result.Failed, invalid...)
+
+	total := len(rows)
+	done := len(invalid)
+	s.jobs.SetProgress(jobID, done, total)
+
+	thumbnails := make(map[string]*media.Asset)
+	for _, row := range planned {
+		guid := fmt.Sprintf("bulk:%s:%d", payload.ArchiveHash[:16], row.Row)
+		imported, err := s.importRow(row, guid, adminID, thumbnails)
+		switch {
+		case err != nil:
+			utils.Log.Warn("[Bulk Import] Row failed",
+				zap.Error(err),
+				zap.Uint("job_id", jobID),
+				zap.Int("row", row.Row),
+			)
+			result.Failed = append(result.Failed, BulkImportRowError{Row: row.Row, Title: row.Title, Error: err.Error()})
+		case imported:
+			result.Imported++
+		default:
+			result.Skipped++
+		}
+		done++
+		s.jobs.SetProgress(jobID, done, total)
+	}
+
+	archive.Close()
+	os.Remove(media.ArchivePath(payload.Archive))
+
+	s.jobs.Complete(jobID, result)
+	utils.Log.Info("[Bulk Import] Job finished",
+		zap.Uint("job_id", jobID),
+		zap.Int("imported", result.Imported),
+		zap.Int("skipped", result.Skipped),
+		zap.Int("failed", len(result.Failed)),
+	)
+}
+
+// check validates a staged archive and returns how many rows it holds.
+func (s *BulkImportService) check(staged string) (int, error) {
+	archive, err := zip.OpenReader(staged)
+	if err != nil {
+		return 0, &ManifestError{Message: "archive is not a valid ZIP file"}
+	}
+	defer archive.Close()
+
+	rows, base, err := readManifest(&archive.Reader)
+	if err != nil {
+		return 0, err
+	}
+	_, invalid, err := s.plan(&archive.Reader, base, rows)
+	if err != nil {
+		return 0, err
+	}
+	if len(invalid) > 0 {
+		return 0, &ManifestError{Message: fmt.Sprintf("%d of %d manifest rows are invalid", len(invalid), len(rows)), Rows: invalid}
+	}
+	return len(rows), nil
+}
+
+// plan resolves each row against the archive and the catalogue, splitting
+// them into rows ready to import and rows with problems.
+func (s *BulkImportService) plan(archive *zip.Reader, base string, rows []BulkImportRow) ([]plannedRow, []BulkImportRowError, error) {
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[path.Clean(f.Name)] = f
+	}
+
+	var categories []categoryModel.Category
+	if err := s.db.Find(&categories).Error; err != nil {
+		return nil, nil, err
+	}
+	var speakers []speakerModel.Speaker
+	if err := s.db.Find(&speakers).Error; err != nil {
+		return nil, nil, err
+	}
+	speakersByName := make(map[string]speakerModel.Speaker, len(speakers))
+	for _, sp := range speakers {
+		speakersByName[strings.ToLower(sp.Name)] = sp
+	}
+
+	planned := []plannedRow{}
+	invalid := []BulkImportRowError{}
+	for _, row := range rows {
+		p := plannedRow{BulkImportRow: row}
+		var problems []string
+
+		if strings.TrimSpace(row.Title) == "" {
+			problems = append(problems, "title is required")
+		}
+		if row.File == "" {
+			problems = append(problems, "file is required")
+		} else if p.audio = files[path.Join(base, row.File)]; p.audio == nil {
+			problems = append(problems, fmt.Sprintf("file %q is not in the archive", row.File))
+		}
+		if row.Thumbnail != "" {
+			if p.thumbnail = files[path.Join(base, row.Thumbnail)]; p.thumbnail == nil {
+				problems = append(problems, fmt.Sprintf("thumbnail %q is not in the archive", row.Thumbnail))
+			}
+		}
+		if row.Category != "" {
+			if p.categoryID = matchCategory(categories, row.Category); p.categoryID == nil {
+				problems = append(problems, fmt.Sprintf("category %q not found", row.Category))
+			}
+		}
+		for _, name := range row.Speakers {
+			speaker, ok := speakersByName[strings.ToLower(name)]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("speaker %q not found", name))
+				continue
+			}
+			p.speakers = append(p.speakers, speaker)
+		}
+		for _, name := range row.Tags {
+			if utils.Slugify(name) == "" {
+				problems = append(problems, fmt.Sprintf("tag %q is not a valid name", name))
+			}
+		}
+		if row.PublishDate != "" {
+			published, err := parsePublishDate(row.PublishDate)
+			if err != nil {
+				problems = append(problems, err.Error())
+			}
+			p.publishAt = published
+		}
+
+		if len(problems) > 0 {
+			invalid = append(invalid, BulkImportRowError{Row: row.Row, Title: row.Title, Error: strings.Join(problems, "; ")})
+			continue
+		}
+		planned = append(planned, p)
+	}
+	return planned, invalid, nil
+}
+
+// importRow creates the audio for one row. It returns false when the row was
+// imported by an earlier run, which is what makes resuming safe.
+func (s *BulkImportService) importRow(row plannedRow, guid string, adminID uint, thumbnails map[string]*media.Asset) (bool, error) {
+	var count int64
+	if err := s.db.Unscoped().Model(&audioModel.Audio{}).Where("guid = ?", guid).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	tags, err := s.tags.FindOrCreate(row.Tags)
+	if err != nil {
+		return false, err
+	}
+
+	audioAsset, err := s.ingestEntry(row.audio, media.KindAudio, "")
+	if err != nil {
+		return false, fmt.Errorf("audio file: %w", err)
+	}
+
+	audio := audioModel.Audio{
+		Title:       strings.TrimSpace(row.Title),
+		Description: strings.TrimSpace(row.Description),
+		AudioURL:    audioAsset.Path,
+		AudioHash:   audioAsset.Hash,
+		Duration:    audioAsset.Duration,
+		FileSize:    audioAsset.Size,
+		CategoryID:  row.categoryID,
+		GUID:        &guid,
+		CreatedBy:   adminID,
+		Speakers:    row.speakers,
+		Tags:        tags,
+	}
+
+	if row.thumbnail != nil {
+		thumbnail, ok := thumbnails[row.thumbnail.Name]
+		if !ok {
+			if thumbnail, err = s.ingestEntry(row.thumbnail, media.KindImage, "thumbnails"); err != nil {
+				s.audios.Discard(audioAsset.Path)
+				return false, fmt.Errorf("thumbnail: %w", err)
+			}
+			thumbnails[row.thumbnail.Name] = thumbnail
+		}
+		audio.Thumbnail = thumbnail.Path
+		audio.ThumbnailHash = thumbnail.Hash
+		audio.ThumbnailVariants = thumbnail.Variants
+		audio.Palette = thumbnail.Palette
+	}
+
+	var sched audioService.Schedule
+	if row.publishAt != nil {
+		if row.publishAt.After(time.Now()) {
+			sched.PublishAt = row.publishAt
+		} else {
+			audio.CreatedAt = *row.publishAt
+			audio.PublishedAt = row.publishAt
+		}
+	}
+
+	if err := s.audios.Create(&audio, sched); err != nil {
+		// Create releases the thumbnail too, so later rows must store it again.
+		if row.thumbnail != nil {
+			delete(thumbnails, row.thumbnail.Name)
+		}
+		return false, err
+	}
+
+	s.transcoder.Enqueue(audio.ID)
+	return true, nil
+}
+
+func (s *BulkImportService) ingestEntry(f *zip.File, kind media.Kind, subdir string) (*media.Asset, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return s.ingest.Ingest(kind, subdir, r)
+}
+
+// readManifest finds the archive's single manifest.csv or manifest.json and
+// parses it. It also returns the manifest's folder, which row paths are
+// relative to.
+func readManifest(archive *zip.Reader) ([]BulkImportRow, string, error) {
+	var manifest *zip.File
+	for _, f := range archive.File {
+		name := strings.ToLower(path.Base(f.Name))
+		if f.FileInfo().IsDir() || (name != "manifest.csv" && name != "manifest.json") {
+			continue
+		}
+		if manifest != nil {
+			return nil, "", &ManifestError{Message: "archive contains more than one manifest"}
+		}
+		manifest = f
+	}
+	if manifest == nil {
+		return nil, "", &ManifestError{Message: "archive has no manifest.csv or manifest.json"}
+	}
+
+	r, err := manifest.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Close()
+
+	var rows []BulkImportRow
+	if strings.HasSuffix(strings.ToLower(manifest.Name), ".json") {
+		rows, err = parseJSONManifest(r)
+	} else {
+		rows, err = parseCSVManifest(r)
+	}
+	if err != nil {
+		return nil, "", &ManifestError{Message: "invalid manifest: " + err.Error()}
+	}
+	if len(rows) == 0 {
+		return nil, "", &ManifestError{Message: "manifest has no rows"}
+	}
+	return rows, path.Dir(path.Clean(manifest.Name)), nil
+}
+
+// parseJSONManifest reads an array of rows. Rows are numbered from 1.
+func parseJSONManifest(r io.Reader) ([]BulkImportRow, error) {
+	var rows []BulkImportRow
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Row = i + 1
+	}
+	return rows, nil
+}
+
+// parseCSVManifest reads a CSV file with a header row. Speakers and tags hold
+// several values separated by semicolons. Rows are numbered by line, so the
+// first one after the header is row 2.
+func parseCSVManifest(r io.Reader) ([]BulkImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch name {
+		case "speaker":
+			name = "speakers"
+		case "tag":
+			name = "tags"
+		}
+		switch name {
+		case "file", "thumbnail", "title", "description", "category", "speakers", "tags", "publish_date":
+			columns[name] = i
+		default:
+			return nil, fmt.Errorf("unknown column %q", header[i])
+		}
+	}
+
+	var rows []BulkImportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, BulkImportRow{
+			Row:         line,
+			File:        field("file"),
+			Thumbnail:   field("thumbnail"),
+			Title:       field("title"),
+			Description: field("description"),
+			Category:    field("category"),
+			Speakers:    splitList(field("speakers")),
+			Tags:        splitList(field("tags")),
+			PublishDate: field("publish_date"),
+		})
+	}
+	return rows, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// matchCategory finds a category by ID, slug or name, ignoring case.
+func matchCategory(categories []categoryModel.Category, value string) *uint {
+	value = strings.TrimSpace(value)
+	id, _ := strconv.ParseUint(value, 10, 64)
+	for i := range categories {
+		c := &categories[i]
+		if uint64(c.ID) == id || strings.EqualFold(c.Slug, value) || strings.EqualFold(c.Name, value) {
+			return &c.ID
+		}
+	}
+	return nil
+}
+
+func parsePublishDate(value string) (*time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("publish_date %q is not RFC 3339 or YYYY-MM-DD", value)
+}
@@ -0,0 +1,286 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	feedModel "mqfm-backend/internal/models/podcast/feed"
+	jobService "mqfm-backend/internal/services/job"
+	"mqfm-backend/internal/services/media"
+	"mqfm-backend/internal/services/podcast/transcode"
+	"mqfm-backend/internal/utils"
+)
+
+const JobTypeRSSImport = "rss_import"
+
+// RSSImportPayload is stored on the job so a run can be inspected or repeated.
+type RSSImportPayload struct {
+	FeedURL    string `json:"feed_url"`
+	CategoryID uint   `json:"category_id"`
+}
+
+// RSSImportResult summarises a finished run.
+type RSSImportResult struct {
+	Imported int                  `json:"imported"`
+	Skipped  int                  `json:"skipped"`
+	Failed   []RSSImportItemError `json:"failed"`
+}
+
+type RSSImportItemError struct {
+	GUID  string `json:"guid"`
+	Title string `json:"title"`
+	Error string `json:"error"`
+}
+
+type RSSImportService struct {
+	db         *gorm.DB
+	jobs       *jobService.JobService
+	transcoder *transcode.TranscodeService
+	ingest     *media.IngestService
+	cleanup    *media.CleanupService
+	client     *http.Client
+}
+
+func NewRSSImportService(db *gorm.DB, jobs *jobService.JobService, transcoder *transcode.TranscodeService, ingest *media.IngestService, cleanup *media.CleanupService) *RSSImportService {
+	return &RSSImportService{
+		db:         db,
+		jobs:       jobs,
+		transcoder: transcoder,
+		ingest:     ingest,
+		cleanup:    cleanup,
+		client:     &http.Client{Timeout: 30 * time.Minute},
+	}
+}
+
+// Start queues an import job and runs it in the background.
+func (s *RSSImportService) Start(adminID uint, payload RSSImportPayload) (uint, error) {
+	if _, err := url.ParseRequestURI(payload.FeedURL); err != nil {
+		return 0, errors.New("invalid feed url")
+	}
+
+	job, err := s.jobs.Create(JobTypeRSSImport, adminID, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	go s.run(job.ID, payload)
+	return job.ID, nil
+}
+
+func (s *RSSImportService) run(jobID uint, payload RSSImportPayload) {
+	s.jobs.Start(jobID)
+	utils.Log.Info("[RSS Import] Job started",
+		zap.Uint("job_id", jobID),
+		zap.String("feed_url", payload.FeedURL),
+	)
+
+	result := RSSImportResult{Failed: []RSSImportItemError{}}
+
+	feed, err := s.fetchFeed(payload.FeedURL)
+	if err != nil {
+		utils.Log.Error("[RSS Import] Failed to fetch feed", zap.Error(err), zap.Uint("job_id", jobID))
+		s.jobs.Fail(jobID, err, result)
+		return
+	}
+
+	items := feed.Channel.Items
+	s.jobs.SetProgress(jobID, 0, len(items))
+
+	channelArtwork := feed.Channel.Artwork()
+	artworkCache := make(map[string]*media.Asset)
+
+	for i, item := range items {
+		imported, err := s.importItem(item, payload.CategoryID, channelArtwork, artworkCache)
+		switch {
+		case err != nil:
+			utils.Log.Warn("[RSS Import] Item failed",
+				zap.Error(err),
+				zap.Uint("job_id", jobID),
+				zap.String("guid", itemGUID(item)),
+			)
+			result.Failed = append(result.Failed, RSSImportItemError{
+				GUID:  itemGUID(item),
+				Title: item.Title,
+				Error: err.Error(),
+			})
+		case imported:
+			result.Imported++
+		default:
+			result.Skipped++
+		}
+		s.jobs.SetProgress(jobID, i+1, len(items))
+	}
+
+	s.jobs.Complete(jobID, result)
+	utils.Log.Info("[RSS Import] Job finished",
+		zap.Uint("job_id", jobID),
+		zap.Int("imported", result.Imported),
+		zap.Int("skipped", result.Skipped),
+		zap.Int("failed", len(result.Failed)),
+	)
+}
+
+func (s *RSSImportService) fetchFeed(feedURL string) (*feedModel.SourceRSS, error) {
+	resp, err := s.client.Get(feedURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed responded with status %d", resp.StatusCode)
+	}
+
+	var feed feedModel.SourceRSS
+	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("invalid feed: %w", err)
+	}
+	return &feed, nil
+}
+
+// importItem creates the audio for one feed item. It returns false when the
+// GUID was already imported, which is what makes re-runs safe.
+func (s *RSSImportService) importItem(item feedModel.SourceItem, categoryID uint, channelArtwork string, artworkCache map[string]*media.Asset) (bool, error) {
+	guid := itemGUID(item)
+	if guid == "" {
+		return false, errors.New("item has no guid or enclosure")
+	}
+	if item.Enclosure.URL == "" {
+		return false, errors.New("item has no enclosure")
+	}
+
+	var count int64
+	if err := s.db.Unscoped().Model(&audioModel.Audio{}).Where("guid = ?", guid).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	enclosure, err := s.download(item.Enclosure.URL, media.KindAudio)
+	if err != nil {
+		return false, fmt.Errorf("download enclosure: %w", err)
+	}
+
+	duration := parseDuration(item.ItunesDuration)
+	if duration == 0 {
+		duration = enclosure.Duration
+	}
+
+	artwork := item.ItunesImage.Href
+	if artwork == "" {
+		artwork = channelArtwork
+	}
+	var thumbnail *media.Asset
+	if artwork != "" {
+		if cached, ok := artworkCache[artwork]; ok {
+			thumbnail = cached
+		} else if asset, err := s.download(artwork, media.KindImage); err == nil {
+			thumbnail = asset
+			artworkCache[artwork] = asset
+		} else {
+			utils.Log.Warn("[RSS Import] Artwork download failed", zap.Error(err), zap.String("url", artwork))
+		}
+	}
+
+	description := item.Description
+	if description == "" {
+		description = item.ItunesSummary
+	}
+
+	var category *uint
+	if categoryID != 0 {
+		category = &categoryID
+	}
+
+	audio := audioModel.Audio{
+		Title:       strings.TrimSpace(item.Title),
+		Description: strings.TrimSpace(description),
+		AudioURL:    enclosure.Path,
+		AudioHash:   enclosure.Hash,
+		Duration:    duration,
+		FileSize:    enclosure.Size,
+		CategoryID:  category,
+		GUID:        &guid,
+		Status:      audioModel.StatusPublished,
+	}
+	if thumbnail != nil {
+		audio.Thumbnail = thumbnail.Path
+		audio.ThumbnailHash = thumbnail.Hash
+		audio.ThumbnailVariants = thumbnail.Variants
+		audio.Palette = thumbnail.Palette
+	}
+	if published, err := parsePubDate(item.PubDate); err == nil {
+		audio.CreatedAt = published
+		audio.PublishedAt = &published
+	}
+
+	if err := s.db.Create(&audio).Error; err != nil {
+		// Artwork is left alone because the cache may hand it to later items.
+		s.cleanup.Release(enclosure.Path)
+		return false, err
+	}
+
+	s.transcoder.Enqueue(audio.ID)
+	return true, nil
+}
+
+// download fetches a remote file and passes it through media ingestion.
+func (s *RSSImportService) download(rawURL string, kind media.Kind) (*media.Asset, error) {
+	resp, err := s.client.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	return s.ingest.Ingest(kind, "", resp.Body)
+}
+
+func itemGUID(item feedModel.SourceItem) string {
+	if guid := strings.TrimSpace(item.GUID); guid != "" {
+		return guid
+	}
+	return strings.TrimSpace(item.Enclosure.URL)
+}
+
+// parseDuration accepts itunes:duration as seconds, MM:SS or HH:MM:SS.
+func parseDuration(value string) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	total := 0
+	for _, part := range strings.Split(value, ":") {
+		n, err := strconv.Atoi(strings.SplitN(part, ".", 2)[0])
+		if err != nil {
+			return 0
+		}
+		total = total*60 + n
+	}
+	return total
+}
+
+func parsePubDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unrecognised pubDate")
+}
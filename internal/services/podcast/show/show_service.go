@@ -0,0 +1,157 @@
+package show
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	mediaModel "mqfm-backend/internal/models/media"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	showModel "mqfm-backend/internal/models/podcast/show"
+	"mqfm-backend/internal/services/media"
+)
+
+// episodeOrder lists a show's episodes as they are meant to be heard.
+const episodeOrder = "season_number, episode_number, id"
+
+type ShowService struct {
+	db      *gorm.DB
+	cleanup *media.CleanupService
+}
+
+func NewShowService(db *gorm.DB, cleanup *media.CleanupService) *ShowService {
+	return &ShowService{db: db, cleanup: cleanup}
+}
+
+func (s *ShowService) Create(show *showModel.Show) error {
+	if err := s.db.Create(show).Error; err != nil {
+		s.cleanup.Release(append(show.ArtworkVariants.Keys(), show.Artwork)...)
+		return err
+	}
+	return nil
+}
+
+func (s *ShowService) FindAll() ([]showModel.Show, error) {
+	var shows []showModel.Show
+	if err := s.db.Order("title").Find(&shows).Error; err != nil {
+		return nil, err
+	}
+	return shows, nil
+}
+
+func (s *ShowService) FindByID(id uint) (*showModel.Show, error) {
+	var show showModel.Show
+	if err := s.db.First(&show, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("show not found")
+		}
+		return nil, err
+	}
+	return &show, nil
+}
+
+func (s *ShowService) Update(id uint, updates map[string]interface{}) (*showModel.Show, error) {
+	current, err := s.FindByID(id)
+	if err != nil {
+		s.cleanup.Release(artworkKeys(updates)...)
+		return nil, err
+	}
+
+	if err := s.db.Model(&showModel.Show{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		s.cleanup.Release(artworkKeys(updates)...)
+		return nil, err
+	}
+	if _, ok := updates["artwork"]; ok {
+		s.cleanup.Release(append(current.ArtworkVariants.Keys(), current.Artwork)...)
+	}
+
+	return s.FindByID(id)
+}
+
+// Delete moves the show to the trash. Its episodes stay as standalone audios.
+func (s *ShowService) Delete(id uint) error {
+	show, err := s.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&audioModel.Audio{}).Where("show_id = ?", id).Update("show_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(show).Error
+	})
+}
+
+// Episodes returns a show's public episodes in order. A season above zero
+// limits the list to that season.
+func (s *ShowService) Episodes(showID uint, season int) ([]audioModel.Audio, error) {
+	if _, err := s.FindByID(showID); err != nil {
+		return nil, err
+	}
+
+	var audios []audioModel.Audio
+	query := s.db.Scopes(audioModel.Listed).Where("show_id = ?", showID)
+	if season > 0 {
+		query = query.Where("season_number = ?", season)
+	}
+	if err := query.Order(episodeOrder).Find(&audios).Error; err != nil {
+		return nil, err
+	}
+	return audios, nil
+}
+
+// Next returns the public episode after audioID in its show, or nil when it is
+// the last one or not part of a show.
+func (s *ShowService) Next(audioID uint) (*audioModel.Audio, error) {
+	return s.adjacent(audioID, true)
+}
+
+// Previous is Next in the other direction.
+func (s *ShowService) Previous(audioID uint) (*audioModel.Audio, error) {
+	return s.adjacent(audioID, false)
+}
+
+func (s *ShowService) adjacent(audioID uint, forward bool) (*audioModel.Audio, error) {
+	var current audioModel.Audio
+	if err := s.db.Scopes(audioModel.Reachable).First(&current, audioID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("audio not found")
+		}
+		return nil, err
+	}
+	if current.ShowID == nil {
+		return nil, nil
+	}
+
+	cmp, order := ">", episodeOrder
+	if !forward {
+		cmp, order = "<", "season_number DESC, episode_number DESC, id DESC"
+	}
+
+	var audio audioModel.Audio
+	err := s.db.Scopes(audioModel.Listed).
+		Where("show_id = ?", *current.ShowID).
+		Where("(season_number, episode_number, id) "+cmp+" (?, ?, ?)", current.SeasonNumber, current.EpisodeNumber, current.ID).
+		Order(order).
+		First(&audio).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &audio, nil
+}
+
+// artworkKeys returns the storage keys carried by an update map.
+func artworkKeys(updates map[string]interface{}) []string {
+	var keys []string
+	if key, ok := updates["artwork"].(string); ok {
+		keys = append(keys, key)
+	}
+	if variants, ok := updates["artwork_variants"].(mediaModel.ImageVariants); ok {
+		keys = append(keys, variants.Keys()...)
+	}
+	return keys
+}
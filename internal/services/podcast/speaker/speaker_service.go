@@ -0,0 +1,139 @@
+package speaker
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	mediaModel "mqfm-backend/internal/models/media"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	speakerModel "mqfm-backend/internal/models/podcast/speaker"
+	"mqfm-backend/internal/services/media"
+)
+
+type SpeakerService struct {
+	db      *gorm.DB
+	cleanup *media.CleanupService
+}
+
+func NewSpeakerService(db *gorm.DB, cleanup *media.CleanupService) *SpeakerService {
+	return &SpeakerService{db: db, cleanup: cleanup}
+}
+
+func (s *SpeakerService) Create(speaker *speakerModel.Speaker) error {
+	if err := s.db.Create(speaker).Error; err != nil {
+		s.cleanup.Release(append(speaker.PhotoVariants.Keys(), speaker.Photo)...)
+		return err
+	}
+	return nil
+}
+
+func (s *SpeakerService) FindAll() ([]speakerModel.Speaker, error) {
+	var speakers []speakerModel.Speaker
+	if err := s.db.Order("name").Find(&speakers).Error; err != nil {
+		return nil, err
+	}
+	return speakers, nil
+}
+
+func (s *SpeakerService) FindByID(id uint) (*speakerModel.Speaker, error) {
+	var speaker speakerModel.Speaker
+	if err := s.db.First(&speaker, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("speaker not found")
+		}
+		return nil, err
+	}
+	return &speaker, nil
+}
+
+// FindByIDs loads every speaker in ids, failing if any of them is missing.
+func (s *SpeakerService) FindByIDs(ids []uint) ([]speakerModel.Speaker, error) {
+	speakers := []speakerModel.Speaker{}
+	if len(ids) == 0 {
+		return speakers, nil
+	}
+	if err := s.db.Where("id IN ?", ids).Find(&speakers).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[uint]bool, len(speakers))
+	for _, sp := range speakers {
+		found[sp.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf("speaker %d not found", id)
+		}
+	}
+	return speakers, nil
+}
+
+func (s *SpeakerService) Search(query string) ([]speakerModel.Speaker, error) {
+	var speakers []speakerModel.Speaker
+	searchQuery := "%" + query + "%"
+	if err := s.db.Where("name LIKE ? OR bio LIKE ?", searchQuery, searchQuery).Order("name").Find(&speakers).Error; err != nil {
+		return nil, err
+	}
+	return speakers, nil
+}
+
+func (s *SpeakerService) Update(id uint, updates map[string]interface{}) (*speakerModel.Speaker, error) {
+	current, err := s.FindByID(id)
+	if err != nil {
+		s.cleanup.Release(photoKeys(updates)...)
+		return nil, err
+	}
+
+	if err := s.db.Model(&speakerModel.Speaker{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		s.cleanup.Release(photoKeys(updates)...)
+		return nil, err
+	}
+	if _, ok := updates["photo"]; ok {
+		s.cleanup.Release(append(current.PhotoVariants.Keys(), current.Photo)...)
+	}
+
+	return s.FindByID(id)
+}
+
+// Delete moves the speaker to the trash. It stays linked to its audios, but
+// trashed speakers are left out when audios are loaded.
+func (s *SpeakerService) Delete(id uint) error {
+	speaker, err := s.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Delete(speaker).Error
+}
+
+// Episodes returns the public audios a speaker appears on, newest first.
+func (s *SpeakerService) Episodes(speakerID uint) ([]audioModel.Audio, error) {
+	if _, err := s.FindByID(speakerID); err != nil {
+		return nil, err
+	}
+
+	var audios []audioModel.Audio
+	if err := s.db.Scopes(audioModel.Listed).
+		Joins("JOIN audio_speakers ON audio_speakers.audio_id = audios.id").
+		Where("audio_speakers.speaker_id = ?", speakerID).
+		Preload("Speakers").
+		Order("COALESCE(audios.published_at, audios.created_at) DESC").
+		Find(&audios).Error; err != nil {
+		return nil, err
+	}
+	return audios, nil
+}
+
+// photoKeys returns the storage keys carried by an update map.
+func photoKeys(updates map[string]interface{}) []string {
+	var keys []string
+	if key, ok := updates["photo"].(string); ok {
+		keys = append(keys, key)
+	}
+	if variants, ok := updates["photo_variants"].(mediaModel.ImageVariants); ok {
+		keys = append(keys, variants.Keys()...)
+	}
+	return keys
+}
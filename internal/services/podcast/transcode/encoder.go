@@ -0,0 +1,23 @@
+package transcode
+
+import "context"
+
+// Profile describes one rendition the pipeline should produce.
+type Profile struct {
+	Quality    string
+	Bitrate    int // kbps
+	SampleRate int
+	Channels   int
+}
+
+// DefaultProfiles are tuned for spoken-word content.
+var DefaultProfiles = []Profile{
+	{Quality: "low", Bitrate: 48, SampleRate: 22050, Channels: 1},
+	{Quality: "medium", Bitrate: 96, SampleRate: 44100, Channels: 2},
+	{Quality: "high", Bitrate: 128, SampleRate: 44100, Channels: 2},
+}
+
+// Encoder turns the source file into an MP3 rendition at dst.
+type Encoder interface {
+	Encode(ctx context.Context, src, dst string, p Profile) error
+}
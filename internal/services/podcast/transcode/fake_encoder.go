@@ -0,0 +1,40 @@
+package transcode
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+// FakeEncoder copies the source instead of transcoding it. Intended for tests.
+type FakeEncoder struct {
+	mu    sync.Mutex
+	Err   error
+	Calls []Profile
+}
+
+func (e *FakeEncoder) Encode(ctx context.Context, src, dst string, p Profile) error {
+	e.mu.Lock()
+	e.Calls = append(e.Calls, p)
+	err := e.Err
+	e.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
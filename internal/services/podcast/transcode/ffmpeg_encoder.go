@@ -0,0 +1,52 @@
+package transcode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+type FFmpegEncoder struct {
+	binary string
+}
+
+// NewFFmpegEncoder resolves the ffmpeg binary, defaulting to the one on PATH.
+func NewFFmpegEncoder(binary string) (*FFmpegEncoder, error) {
+	if binary == "" {
+		binary = "ffmpeg"
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, err
+	}
+	return &FFmpegEncoder{binary: path}, nil
+}
+
+func (e *FFmpegEncoder) Encode(ctx context.Context, src, dst string, p Profile) error {
+	cmd := exec.CommandContext(ctx, e.binary,
+		"-y", "-i", src,
+		"-vn",
+		"-codec:a", "libmp3lame",
+		"-b:a", strconv.Itoa(p.Bitrate)+"k",
+		"-ar", strconv.Itoa(p.SampleRate),
+		"-ac", strconv.Itoa(p.Channels),
+		dst,
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg %s: %w: %s", p.Quality, err, lastLine(stderr.Bytes()))
+	}
+	return nil
+}
+
+func lastLine(b []byte) string {
+	b = bytes.TrimSpace(b)
+	if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+		b = b[i+1:]
+	}
+	return string(b)
+}
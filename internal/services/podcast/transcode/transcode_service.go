@@ -0,0 +1,253 @@
+package transcode
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	"mqfm-backend/internal/services/media"
+	"mqfm-backend/internal/storage"
+	"mqfm-backend/internal/utils"
+)
+
+const encodeTimeout = 30 * time.Minute
+
+// sweepBatch caps how many audios one sweep queues, leaving room in the
+// queue for fresh uploads.
+const sweepBatch = 50
+
+type TranscodeService struct {
+	db       *gorm.DB
+	store    storage.Storage
+	cleanup  *media.CleanupService
+	encoder  Encoder
+	profiles []Profile
+	queue    chan uint
+}
+
+// NewTranscodeService builds the pipeline. A nil encoder disables transcoding.
+func NewTranscodeService(db *gorm.DB, store storage.Storage, cleanup *media.CleanupService, encoder Encoder) *TranscodeService {
+	return &TranscodeService{
+		db:       db,
+		store:    store,
+		cleanup:  cleanup,
+		encoder:  encoder,
+		profiles: DefaultProfiles,
+		queue:    make(chan uint, 100),
+	}
+}
+
+// Start launches the background worker that drains the queue. Audios queued
+// before a restart are handed back to the sweep.
+func (s *TranscodeService) Start() {
+	if err := s.resetInterrupted(); err != nil {
+		utils.Log.Error("[Transcode] Failed to reset interrupted jobs", zap.Error(err))
+	}
+
+	go func() {
+		for audioID := range s.queue {
+			if err := s.Process(audioID); err != nil {
+				utils.Log.Error("[Transcode] Job failed",
+					zap.Error(err),
+					zap.Uint("audio_id", audioID),
+				)
+			}
+		}
+	}()
+}
+
+func (s *TranscodeService) resetInterrupted() error {
+	return s.db.Model(&audioModel.Audio{}).
+		Where("transcode_status IN ?", []string{audioModel.TranscodePending, audioModel.TranscodeProcessing}).
+		UpdateColumn("transcode_status", "").Error
+}
+
+// Enqueue schedules renditions for the audio's current source file.
+func (s *TranscodeService) Enqueue(audioID uint) {
+	if s.encoder == nil {
+		return
+	}
+
+	s.setStatus(audioID, audioModel.TranscodePending)
+
+	select {
+	case s.queue <- audioID:
+		utils.Log.Info("[Transcode] Job queued", zap.Uint("audio_id", audioID))
+	default:
+		utils.Log.Warn("[Transcode] Queue full, left for the next sweep", zap.Uint("audio_id", audioID))
+		s.setStatus(audioID, "")
+	}
+}
+
+// EnqueueMissing queues stored audios that have no renditions yet or whose
+// last attempt failed, new ones first. Audios that didn't fit in the queue or
+// were imported in bulk are picked up this way.
+func (s *TranscodeService) EnqueueMissing() error {
+	if s.encoder == nil {
+		return nil
+	}
+
+	var ids []uint
+	err := s.db.Model(&audioModel.Audio{}).
+		Where("audio_url <> '' AND audio_url NOT LIKE 'http://%' AND audio_url NOT LIKE 'https://%'").
+		Where("COALESCE(transcode_status, '') IN ?", []string{"", audioModel.TranscodeFailed}).
+		Order("COALESCE(transcode_status, '') = '"+audioModel.TranscodeFailed+"', id").Limit(sweepBatch).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		s.Enqueue(id)
+	}
+	return nil
+}
+
+// Process encodes every profile and swaps the audio's renditions once all succeed.
+func (s *TranscodeService) Process(audioID uint) error {
+	var audio audioModel.Audio
+	if err := s.db.First(&audio, audioID).Error; err != nil {
+		return err
+	}
+	if audio.AudioURL == "" {
+		s.setStatus(audioID, "")
+		return nil
+	}
+
+	s.setStatus(audioID, audioModel.TranscodeProcessing)
+
+	workDir, err := os.MkdirTemp("", "transcode-*")
+	if err != nil {
+		s.setStatus(audioID, audioModel.TranscodeFailed)
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	src := filepath.Join(workDir, "source"+filepath.Ext(audio.AudioURL))
+	if err := s.download(audio.AudioURL, src); err != nil {
+		s.setStatus(audioID, audioModel.TranscodeFailed)
+		return err
+	}
+
+	stamp := time.Now().Unix()
+	renditions := make([]audioModel.AudioRendition, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		filename := fmt.Sprintf("%d_%s_%d.mp3", audioID, p.Quality, stamp)
+		dst := filepath.Join(workDir, filename)
+
+		ctx, cancel := context.WithTimeout(context.Background(), encodeTimeout)
+		err := s.encoder.Encode(ctx, src, dst, p)
+		cancel()
+		if err != nil {
+			s.fail(audioID, renditions)
+			return err
+		}
+
+		key := "uploads/audios/renditions/" + filename
+		size, err := s.upload(dst, key)
+		if err != nil {
+			s.fail(audioID, renditions)
+			return err
+		}
+
+		renditions = append(renditions, audioModel.AudioRendition{
+			AudioID: audioID,
+			Quality: p.Quality,
+			Bitrate: p.Bitrate,
+			URL:     key,
+			Size:    size,
+		})
+	}
+
+	var previous []audioModel.AudioRendition
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("audio_id = ?", audioID).Find(&previous).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("audio_id = ?", audioID).Delete(&audioModel.AudioRendition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&renditions).Error; err != nil {
+			return err
+		}
+		return tx.Model(&audioModel.Audio{}).Where("id = ?", audioID).
+			Update("transcode_status", audioModel.TranscodeDone).Error
+	})
+	if err != nil {
+		s.fail(audioID, renditions)
+		return err
+	}
+	s.cleanup.Release(renditionKeys(previous)...)
+
+	utils.Log.Info("[Transcode] Renditions ready",
+		zap.Uint("audio_id", audioID),
+		zap.Int("count", len(renditions)),
+	)
+	return nil
+}
+
+// download copies the source object to a local file for the encoder.
+func (s *TranscodeService) download(key, dst string) error {
+	src, err := s.store.Get(key)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, src)
+	return err
+}
+
+func (s *TranscodeService) upload(path, key string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if err := s.store.Put(key, f, info.Size(), "audio/mpeg"); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// fail marks the job failed and drops renditions uploaded before the error.
+func (s *TranscodeService) fail(audioID uint, uploaded []audioModel.AudioRendition) {
+	s.setStatus(audioID, audioModel.TranscodeFailed)
+	s.cleanup.Release(renditionKeys(uploaded)...)
+}
+
+func renditionKeys(renditions []audioModel.AudioRendition) []string {
+	keys := make([]string, 0, len(renditions))
+	for _, r := range renditions {
+		keys = append(keys, r.URL)
+	}
+	return keys
+}
+
+func (s *TranscodeService) setStatus(audioID uint, status string) {
+	if err := s.db.Model(&audioModel.Audio{}).Where("id = ?", audioID).Update("transcode_status", status).Error; err != nil {
+		utils.Log.Error("[Transcode] Failed to update status",
+			zap.Error(err),
+			zap.Uint("audio_id", audioID),
+			zap.String("status", status),
+		)
+	}
+}
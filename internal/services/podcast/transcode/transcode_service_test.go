@@ -0,0 +1,202 @@
+package transcode
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	userModel "mqfm-backend/internal/models/auth/user"
+	categoryModel "mqfm-backend/internal/models/category/admin"
+	playlistModel "mqfm-backend/internal/models/playlist/user"
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	showModel "mqfm-backend/internal/models/podcast/show"
+	speakerModel "mqfm-backend/internal/models/podcast/speaker"
+	tagModel "mqfm-backend/internal/models/tag"
+	"mqfm-backend/internal/services/media"
+	"mqfm-backend/internal/storage"
+)
+
+// newTestService returns a service with FakeEncoder over a temp database and
+// LocalStorage. Every table that can reference a file is migrated so the
+// cleanup service can tell which files are still in use.
+func newTestService(t *testing.T) (*TranscodeService, *FakeEncoder, *gorm.DB, storage.Storage) {
+	t.Helper()
+	dir := t.TempDir()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(
+		&userModel.User{},
+		&categoryModel.Category{},
+		&audioModel.Audio{},
+		&audioModel.AudioRendition{},
+		&audioModel.AudioChapter{},
+		&showModel.Show{},
+		&speakerModel.Speaker{},
+		&tagModel.Tag{},
+		&playlistModel.Playlist{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := storage.NewLocalStorage(dir, "http://localhost/")
+	encoder := &FakeEncoder{}
+	return NewTranscodeService(db, store, media.NewCleanupService(db, store), encoder), encoder, db, store
+}
+
+func putFile(t *testing.T, store storage.Storage, key, content string) {
+	t.Helper()
+	if err := store.Put(key, strings.NewReader(content), int64(len(content)), "audio/mpeg"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessRecordsRenditionsAndReleasesPrevious(t *testing.T) {
+	s, encoder, db, store := newTestService(t)
+
+	putFile(t, store, "uploads/audios/source.mp3", "source audio")
+	putFile(t, store, "uploads/audios/renditions/old_low.mp3", "old rendition")
+	audio := audioModel.Audio{Title: "Kajian", AudioURL: "uploads/audios/source.mp3"}
+	if err := db.Create(&audio).Error; err != nil {
+		t.Fatal(err)
+	}
+	old := audioModel.AudioRendition{AudioID: audio.ID, Quality: "low", Bitrate: 48, URL: "uploads/audios/renditions/old_low.mp3"}
+	if err := db.Create(&old).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Process(audio.ID); err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+
+	if len(encoder.Calls) != len(DefaultProfiles) {
+		t.Errorf("encoder called %d times, want %d", len(encoder.Calls), len(DefaultProfiles))
+	}
+
+	var renditions []audioModel.AudioRendition
+	db.Where("audio_id = ?", audio.ID).Order("bitrate").Find(&renditions)
+	if len(renditions) != len(DefaultProfiles) {
+		t.Fatalf("got %d renditions, want %d", len(renditions), len(DefaultProfiles))
+	}
+	for i, r := range renditions {
+		if r.Quality != DefaultProfiles[i].Quality || r.Bitrate != DefaultProfiles[i].Bitrate {
+			t.Errorf("rendition %d is %s/%d, want %s/%d", i, r.Quality, r.Bitrate, DefaultProfiles[i].Quality, DefaultProfiles[i].Bitrate)
+		}
+		if r.ID == old.ID || r.URL == old.URL {
+			t.Errorf("rendition %d still is the previous one", i)
+		}
+		src, err := store.Get(r.URL)
+		if err != nil {
+			t.Fatalf("rendition %s not stored: %v", r.URL, err)
+		}
+		content, _ := io.ReadAll(src)
+		src.Close()
+		if string(content) != "source audio" {
+			t.Errorf("rendition %s holds %q", r.URL, content)
+		}
+		if r.Size != int64(len("source audio")) {
+			t.Errorf("rendition %s has size %d", r.URL, r.Size)
+		}
+	}
+
+	if _, err := store.Stat(old.URL); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("previous rendition file was not released: %v", err)
+	}
+
+	var status string
+	db.Model(&audioModel.Audio{}).Where("id = ?", audio.ID).Pluck("transcode_status", &status)
+	if status != audioModel.TranscodeDone {
+		t.Errorf("transcode status is %q, want %q", status, audioModel.TranscodeDone)
+	}
+}
+
+func TestProcessFailureKeepsPreviousRenditions(t *testing.T) {
+	s, encoder, db, store := newTestService(t)
+	encoder.Err = errors.New("encoder crashed")
+
+	putFile(t, store, "uploads/audios/source.mp3", "source audio")
+	putFile(t, store, "uploads/audios/renditions/old_low.mp3", "old rendition")
+	audio := audioModel.Audio{Title: "Kajian", AudioURL: "uploads/audios/source.mp3"}
+	if err := db.Create(&audio).Error; err != nil {
+		t.Fatal(err)
+	}
+	old := audioModel.AudioRendition{AudioID: audio.ID, Quality: "low", Bitrate: 48, URL: "uploads/audios/renditions/old_low.mp3"}
+	if err := db.Create(&old).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Process(audio.ID); err == nil {
+		t.Fatal("Process succeeded with a failing encoder")
+	}
+
+	var renditions []audioModel.AudioRendition
+	db.Where("audio_id = ?", audio.ID).Find(&renditions)
+	if len(renditions) != 1 || renditions[0].ID != old.ID {
+		t.Errorf("renditions changed after a failed encode: %+v", renditions)
+	}
+	if _, err := store.Stat(old.URL); err != nil {
+		t.Errorf("previous rendition file was removed: %v", err)
+	}
+
+	var status string
+	db.Model(&audioModel.Audio{}).Where("id = ?", audio.ID).Pluck("transcode_status", &status)
+	if status != audioModel.TranscodeFailed {
+		t.Errorf("transcode status is %q, want %q", status, audioModel.TranscodeFailed)
+	}
+}
+
+func TestStartAndEnqueueMissingRequeueUnfinishedAudios(t *testing.T) {
+	s, _, db, _ := newTestService(t)
+
+	statuses := []string{"", audioModel.TranscodeFailed, audioModel.TranscodeDone, audioModel.TranscodePending, audioModel.TranscodeProcessing}
+	ids := make(map[string]uint)
+	for _, status := range statuses {
+		audio := audioModel.Audio{Title: "Kajian " + status, AudioURL: "uploads/audios/" + status + ".mp3", TranscodeStatus: status}
+		if err := db.Create(&audio).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids[status] = audio.ID
+	}
+	remote := audioModel.Audio{Title: "Remote", AudioURL: "https://example.com/a.mp3"}
+	if err := db.Create(&remote).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.resetInterrupted(); err != nil {
+		t.Fatalf("resetInterrupted: %v", err)
+	}
+	if err := s.EnqueueMissing(); err != nil {
+		t.Fatalf("EnqueueMissing: %v", err)
+	}
+	close(s.queue)
+	var queued []uint
+	for id := range s.queue {
+		queued = append(queued, id)
+	}
+
+	// Audios never transcoded come before failed ones.
+	want := []uint{ids[""], ids[audioModel.TranscodePending], ids[audioModel.TranscodeProcessing], ids[audioModel.TranscodeFailed]}
+	if len(queued) != len(want) {
+		t.Fatalf("queued %v, want %v", queued, want)
+	}
+	for i := range want {
+		if queued[i] != want[i] {
+			t.Fatalf("queued %v, want %v", queued, want)
+		}
+	}
+
+	var pending int64
+	db.Model(&audioModel.Audio{}).Where("transcode_status = ?", audioModel.TranscodePending).Count(&pending)
+	if pending != int64(len(want)) {
+		t.Errorf("%d audios pending, want %d", pending, len(want))
+	}
+}
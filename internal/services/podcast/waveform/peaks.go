@@ -0,0 +1,143 @@
+package waveform
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/hajimehoshi/go-mp3"
+
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+)
+
+// Resolution is a level of detail, sized by how many peaks span the audio.
+type Resolution struct {
+	Name   string
+	Points int
+}
+
+// Resolutions are the levels generated for every audio, coarsest first.
+var Resolutions = []Resolution{
+	{Name: "low", Points: 200},
+	{Name: "medium", Points: 1000},
+	{Name: "high", Points: 4000},
+}
+
+const DefaultResolution = "medium"
+
+// basePerPixel is the number of samples per peak gathered while decoding;
+// every resolution is merged down from it.
+const basePerPixel = 512
+
+// peaks holds the 16-bit min/max of the mono mix for each run of samples.
+type peaks struct {
+	sampleRate int
+	perPixel   int
+	min, max   []int16
+}
+
+// decodePeaks decodes an MP3 stream, mixing it down to mono as it goes.
+func decodePeaks(r io.Reader) (*peaks, error) {
+	d, err := mp3.NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &peaks{sampleRate: d.SampleRate(), perPixel: basePerPixel}
+
+	// The decoder always writes 16-bit little-endian stereo frames.
+	buf := make([]byte, 64*1024)
+	lo, hi, count := int16(math.MaxInt16), int16(math.MinInt16), 0
+	for {
+		n, err := io.ReadFull(d, buf)
+		for i := 0; i+4 <= n; i += 4 {
+			left := int16(binary.LittleEndian.Uint16(buf[i:]))
+			right := int16(binary.LittleEndian.Uint16(buf[i+2:]))
+			sample := int16((int32(left) + int32(right)) / 2)
+			lo, hi = min(lo, sample), max(hi, sample)
+			if count++; count == basePerPixel {
+				p.min, p.max = append(p.min, lo), append(p.max, hi)
+				lo, hi, count = math.MaxInt16, math.MinInt16, 0
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if count > 0 {
+		p.min, p.max = append(p.min, lo), append(p.max, hi)
+	}
+	if len(p.min) == 0 {
+		return nil, errors.New("no audio could be decoded")
+	}
+	return p, nil
+}
+
+// waveform merges the peaks down to about points pairs and scales them to
+// 8 bits.
+func (p *peaks) waveform(resolution Resolution) audioModel.AudioWaveform {
+	factor := max(1, (len(p.min)+resolution.Points-1)/resolution.Points)
+	length := (len(p.min) + factor - 1) / factor
+
+	data := make([]byte, 0, length*2)
+	for start := 0; start < len(p.min); start += factor {
+		end := min(start+factor, len(p.min))
+		lo, hi := p.min[start], p.max[start]
+		for i := start + 1; i < end; i++ {
+			lo, hi = min(lo, p.min[i]), max(hi, p.max[i])
+		}
+		data = append(data, byte(int8(lo>>8)), byte(int8(hi>>8)))
+	}
+
+	return audioModel.AudioWaveform{
+		Resolution:      resolution.Name,
+		SampleRate:      p.sampleRate,
+		SamplesPerPixel: p.perPixel * factor,
+		Length:          length,
+		Data:            data,
+	}
+}
+
+// JSON is the audiowaveform JSON format, version 2, as read by players such
+// as peaks.js.
+type JSON struct {
+	Version         int    `json:"version"`
+	Channels        int    `json:"channels"`
+	SampleRate      int    `json:"sample_rate"`
+	SamplesPerPixel int    `json:"samples_per_pixel"`
+	Bits            int    `json:"bits"`
+	Length          int    `json:"length"`
+	Data            []int8 `json:"data"`
+}
+
+func ToJSON(w *audioModel.AudioWaveform) JSON {
+	data := make([]int8, len(w.Data))
+	for i, b := range w.Data {
+		data[i] = int8(b)
+	}
+	return JSON{
+		Version:         2,
+		Channels:        1,
+		SampleRate:      w.SampleRate,
+		SamplesPerPixel: w.SamplesPerPixel,
+		Bits:            8,
+		Length:          w.Length,
+		Data:            data,
+	}
+}
+
+// ToDat writes the audiowaveform binary format, version 2: a little-endian
+// header followed by the 8-bit min/max pairs.
+func ToDat(w *audioModel.AudioWaveform) []byte {
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:], 2) // version
+	binary.LittleEndian.PutUint32(header[4:], 1) // flags: 8-bit data
+	binary.LittleEndian.PutUint32(header[8:], uint32(w.SampleRate))
+	binary.LittleEndian.PutUint32(header[12:], uint32(w.SamplesPerPixel))
+	binary.LittleEndian.PutUint32(header[16:], uint32(w.Length))
+	binary.LittleEndian.PutUint32(header[20:], 1) // channels
+	return append(header, w.Data...)
+}
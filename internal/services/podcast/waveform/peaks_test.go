@@ -0,0 +1,121 @@
+package waveform
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+)
+
+// silentMP3 returns count MPEG-1 layer III frames (128 kbps, 44.1 kHz, mono)
+// whose side information is all zeros, which decode to 1152 silent samples
+// each.
+func silentMP3(count int) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0xC4})
+	return bytes.Repeat(frame, count)
+}
+
+func TestDecodePeaks(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		peaks int
+	}{
+		// 11520 samples make 22 full runs of 512 and a partial one.
+		{"whole frames", silentMP3(10), 23},
+		{"truncated last frame", silentMP3(10)[:10*417-100], 21},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := decodePeaks(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("decodePeaks: %v", err)
+			}
+			if p.sampleRate != 44100 || p.perPixel != basePerPixel {
+				t.Errorf("got %d Hz at %d samples per peak, want 44100 Hz at %d", p.sampleRate, p.perPixel, basePerPixel)
+			}
+			if len(p.min) != tt.peaks || len(p.max) != tt.peaks {
+				t.Fatalf("got %d/%d peaks, want %d", len(p.min), len(p.max), tt.peaks)
+			}
+			for i := range p.min {
+				if p.min[i] != 0 || p.max[i] != 0 {
+					t.Fatalf("peak %d of silence is %d..%d", i, p.min[i], p.max[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDecodePeaksErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"not mp3", []byte("not an mp3 at all, just text")},
+		{"truncated first frame", silentMP3(1)[:200]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if p, err := decodePeaks(bytes.NewReader(tt.data)); err == nil {
+				t.Errorf("got %d peaks, want an error", len(p.min))
+			}
+		})
+	}
+}
+
+func TestWaveformMergesAndScalesPeaks(t *testing.T) {
+	p := &peaks{
+		sampleRate: 44100,
+		perPixel:   basePerPixel,
+		min:        []int16{-256, -32768, -512, 0, -1, -2, 100},
+		max:        []int16{256, 512, 32767, 0, 1, 2, 300},
+	}
+
+	w := p.waveform(Resolution{Name: "test", Points: 3})
+	want := audioModel.AudioWaveform{
+		Resolution:      "test",
+		SampleRate:      44100,
+		SamplesPerPixel: 3 * basePerPixel,
+		Length:          3,
+		// Runs of three peaks, the last one short, scaled to 8 bits.
+		Data: []byte{0x80, 0x7F, 0xFF, 0x00, 0x00, 0x01},
+	}
+	if w.Resolution != want.Resolution || w.SampleRate != want.SampleRate || w.SamplesPerPixel != want.SamplesPerPixel || w.Length != want.Length {
+		t.Errorf("got %+v, want %+v", w, want)
+	}
+	if !bytes.Equal(w.Data, want.Data) {
+		t.Errorf("got data % x, want % x", w.Data, want.Data)
+	}
+
+	if w := p.waveform(Resolution{Name: "fine", Points: 100}); w.Length != len(p.min) || w.SamplesPerPixel != basePerPixel {
+		t.Errorf("finer resolution than the peaks gave %d points at %d samples", w.Length, w.SamplesPerPixel)
+	}
+}
+
+func TestToDat(t *testing.T) {
+	w := &audioModel.AudioWaveform{
+		SampleRate:      44100,
+		SamplesPerPixel: 1024,
+		Length:          2,
+		Data:            []byte{0xF0, 0x10, 0x80, 0x7F},
+	}
+
+	dat := ToDat(w)
+	if len(dat) != 24+len(w.Data) {
+		t.Fatalf("got %d bytes, want %d", len(dat), 24+len(w.Data))
+	}
+	header := []uint32{2, 1, 44100, 1024, 2, 1} // version, flags, rate, samples per pixel, length, channels
+	for i, want := range header {
+		if got := binary.LittleEndian.Uint32(dat[i*4:]); got != want {
+			t.Errorf("header field %d is %d, want %d", i, got, want)
+		}
+	}
+	if !bytes.Equal(dat[24:], w.Data) {
+		t.Errorf("got data % x, want % x", dat[24:], w.Data)
+	}
+}
@@ -0,0 +1,216 @@
+package waveform
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	"mqfm-backend/internal/services/media"
+	"mqfm-backend/internal/storage"
+	"mqfm-backend/internal/utils"
+)
+
+// sweepBatch caps how many audios one sweep queues, leaving room in the
+// queue for fresh uploads.
+const sweepBatch = 50
+
+var (
+	ErrWaveformNotFound  = errors.New("waveform not found")
+	ErrUnknownResolution = errors.New("resolution must be low, medium or high")
+)
+
+// WaveformService computes the peak data players draw waveforms from.
+type WaveformService struct {
+	db    *gorm.DB
+	store storage.Storage
+	queue chan uint
+}
+
+func NewWaveformService(db *gorm.DB, store storage.Storage) *WaveformService {
+	return &WaveformService{db: db, store: store, queue: make(chan uint, 100)}
+}
+
+// Start launches the background worker. Audios queued before a restart are
+// handed back to the sweep.
+func (s *WaveformService) Start() {
+	err := s.db.Model(&audioModel.Audio{}).
+		Where("waveform_status IN ?", []string{audioModel.WaveformPending, audioModel.WaveformProcessing}).
+		UpdateColumn("waveform_status", "").Error
+	if err != nil {
+		utils.Log.Error("[Waveform] Failed to reset interrupted jobs", zap.Error(err))
+	}
+
+	go func() {
+		for audioID := range s.queue {
+			if err := s.Process(audioID); err != nil {
+				utils.Log.Error("[Waveform] Job failed",
+					zap.Error(err),
+					zap.Uint("audio_id", audioID),
+				)
+			}
+		}
+	}()
+}
+
+// Enqueue schedules peaks for the audio's current file.
+func (s *WaveformService) Enqueue(audioID uint) {
+	s.setStatus(audioID, audioModel.WaveformPending)
+
+	select {
+	case s.queue <- audioID:
+		utils.Log.Info("[Waveform] Job queued", zap.Uint("audio_id", audioID))
+	default:
+		utils.Log.Warn("[Waveform] Queue full, left for the next sweep", zap.Uint("audio_id", audioID))
+		s.setStatus(audioID, "")
+	}
+}
+
+// EnqueueMissing queues stored audios that have no peaks yet, whose peaks
+// were made for a file they no longer use, or that were waiting for an MP3
+// rendition which is now ready. Imports and audios from before waveforms
+// existed are picked up this way.
+func (s *WaveformService) EnqueueMissing() error {
+	var ids []uint
+	err := s.db.Model(&audioModel.Audio{}).
+		Where("audio_url <> '' AND audio_url NOT LIKE 'http://%' AND audio_url NOT LIKE 'https://%'").
+		Where(s.db.Where("COALESCE(waveform_status, '') = ''").
+			Or("waveform_status = ? AND transcode_status = ?", audioModel.WaveformUnsupported, audioModel.TranscodeDone).
+			Or(`waveform_status = ? AND NOT EXISTS (SELECT 1 FROM audio_waveforms
+				WHERE audio_waveforms.audio_id = audios.id AND audio_waveforms.source = audios.audio_url)`, audioModel.WaveformDone)).
+		Order("id").Limit(sweepBatch).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		s.Enqueue(id)
+	}
+	return nil
+}
+
+// Process decodes the audio and replaces its peaks at every resolution.
+func (s *WaveformService) Process(audioID uint) error {
+	var audio audioModel.Audio
+	if err := s.db.First(&audio, audioID).Error; err != nil {
+		return err
+	}
+	if media.NormalizeKey(audio.AudioURL) == "" {
+		// Nothing stored to decode.
+		s.setStatus(audioID, "")
+		return nil
+	}
+
+	key, err := s.mp3Key(audio)
+	if err != nil {
+		s.setStatus(audioID, audioModel.WaveformFailed)
+		return err
+	}
+	if key == "" {
+		s.setStatus(audioID, audioModel.WaveformUnsupported)
+		return nil
+	}
+
+	s.setStatus(audioID, audioModel.WaveformProcessing)
+
+	src, err := s.store.Get(key)
+	if err != nil {
+		s.setStatus(audioID, audioModel.WaveformFailed)
+		return err
+	}
+	p, err := decodePeaks(src)
+	src.Close()
+	if err != nil {
+		s.setStatus(audioID, audioModel.WaveformFailed)
+		return err
+	}
+
+	waveforms := make([]audioModel.AudioWaveform, 0, len(Resolutions))
+	for _, resolution := range Resolutions {
+		w := p.waveform(resolution)
+		w.AudioID = audioID
+		w.Source = audio.AudioURL
+		waveforms = append(waveforms, w)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("audio_id = ?", audioID).Delete(&audioModel.AudioWaveform{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&waveforms).Error; err != nil {
+			return err
+		}
+		return tx.Model(&audioModel.Audio{}).Where("id = ?", audioID).
+			UpdateColumn("waveform_status", audioModel.WaveformDone).Error
+	})
+	if err != nil {
+		s.setStatus(audioID, audioModel.WaveformFailed)
+		return err
+	}
+
+	utils.Log.Info("[Waveform] Peaks ready",
+		zap.Uint("audio_id", audioID),
+		zap.String("source", key),
+	)
+	return nil
+}
+
+// mp3Key picks the file to decode: the source when it is an MP3, otherwise
+// the smallest MP3 rendition once transcoding is done. It is empty when
+// there is neither.
+func (s *WaveformService) mp3Key(audio audioModel.Audio) (string, error) {
+	key := media.NormalizeKey(audio.AudioURL)
+	if strings.EqualFold(filepath.Ext(key), ".mp3") {
+		return key, nil
+	}
+	if audio.TranscodeStatus != audioModel.TranscodeDone {
+		return "", nil
+	}
+
+	var renditions []audioModel.AudioRendition
+	if err := s.db.Where("audio_id = ?", audio.ID).Order("bitrate").Limit(1).Find(&renditions).Error; err != nil {
+		return "", err
+	}
+	if len(renditions) == 0 {
+		return "", nil
+	}
+	return media.NormalizeKey(renditions[0].URL), nil
+}
+
+// Find returns the audio's peaks at the named resolution, or the default one
+// when the name is empty.
+func (s *WaveformService) Find(audioID uint, resolution string) (*audioModel.AudioWaveform, error) {
+	if resolution == "" {
+		resolution = DefaultResolution
+	}
+	known := false
+	for _, r := range Resolutions {
+		known = known || r.Name == resolution
+	}
+	if !known {
+		return nil, ErrUnknownResolution
+	}
+
+	var waveform audioModel.AudioWaveform
+	if err := s.db.Where("audio_id = ? AND resolution = ?", audioID, resolution).First(&waveform).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWaveformNotFound
+		}
+		return nil, err
+	}
+	return &waveform, nil
+}
+
+func (s *WaveformService) setStatus(audioID uint, status string) {
+	if err := s.db.Model(&audioModel.Audio{}).Where("id = ?", audioID).UpdateColumn("waveform_status", status).Error; err != nil {
+		utils.Log.Error("[Waveform] Failed to update status",
+			zap.Error(err),
+			zap.Uint("audio_id", audioID),
+			zap.String("status", status),
+		)
+	}
+}
@@ -0,0 +1,123 @@
+package revision
+
+import (
+	"encoding/json"
+	"errors"
+
+	"gorm.io/gorm"
+
+	revisionModel "mqfm-backend/internal/models/revision"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+type RevisionService struct {
+	db *gorm.DB
+}
+
+func NewRevisionService(db *gorm.DB) *RevisionService {
+	return &RevisionService{db: db}
+}
+
+// Record stores an edit of a record as its next revision, given its tracked
+// fields before and after. Edits that change none of them are not recorded.
+// revertedTo is set when the edit reverts to an earlier revision.
+func (s *RevisionService) Record(tx *gorm.DB, entityType string, entityID, actorID uint, before, after map[string]interface{}, revertedTo *int) error {
+	from, err := encode(before)
+	if err != nil {
+		return err
+	}
+	to, err := encode(after)
+	if err != nil {
+		return err
+	}
+	changes := from.Diff(to)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var last int
+	if err := tx.Model(&revisionModel.Revision{}).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&last).Error; err != nil {
+		return err
+	}
+	if last == 0 {
+		initial := revisionModel.Revision{
+			EntityType: entityType,
+			EntityID:   entityID,
+			Number:     1,
+			Action:     revisionModel.ActionInitial,
+			Snapshot:   from,
+		}
+		if err := tx.Create(&initial).Error; err != nil {
+			return err
+		}
+		last = initial.Number
+	}
+
+	action := revisionModel.ActionUpdate
+	if revertedTo != nil {
+		action = revisionModel.ActionRevert
+	}
+	return tx.Create(&revisionModel.Revision{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Number:     last + 1,
+		Action:     action,
+		ActorID:    &actorID,
+		RevertedTo: revertedTo,
+		Changes:    changes,
+		Snapshot:   to,
+	}).Error
+}
+
+// History returns the revisions of a record, newest first.
+func (s *RevisionService) History(entityType string, entityID uint) ([]revisionModel.Revision, error) {
+	revisions := []revisionModel.Revision{}
+	if err := s.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("number DESC").
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (s *RevisionService) Find(entityType string, entityID uint, number int) (*revisionModel.Revision, error) {
+	var revision revisionModel.Revision
+	if err := s.db.Where("entity_type = ? AND entity_id = ? AND number = ?", entityType, entityID, number).
+		First(&revision).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// Diff lists the tracked fields that differ between two revisions of a
+// record, with their values in from and to.
+func (s *RevisionService) Diff(entityType string, entityID uint, from, to int) (revisionModel.Changes, error) {
+	a, err := s.Find(entityType, entityID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.Find(entityType, entityID, to)
+	if err != nil {
+		return nil, err
+	}
+	return a.Snapshot.Diff(b.Snapshot), nil
+}
+
+func encode(fields map[string]interface{}) (revisionModel.Fields, error) {
+	encoded := make(revisionModel.Fields, len(fields))
+	for name, value := range fields {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		encoded[name] = raw
+	}
+	return encoded, nil
+}
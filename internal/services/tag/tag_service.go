@@ -0,0 +1,210 @@
+package tag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	audioModel "mqfm-backend/internal/models/podcast/audio/admin"
+	tagModel "mqfm-backend/internal/models/tag"
+	"mqfm-backend/internal/utils"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrTagExists is returned when another tag already has the name or slug.
+	ErrTagExists      = errors.New("a tag with this name already exists")
+	ErrInvalidTagName = errors.New("tag name must contain letters or digits")
+	ErrMergeIntoSelf  = errors.New("a tag cannot be merged into itself")
+)
+
+type TagService struct {
+	db *gorm.DB
+}
+
+func NewTagService(db *gorm.DB) *TagService {
+	return &TagService{db: db}
+}
+
+func (s *TagService) Create(name string) (*tagModel.Tag, error) {
+	tag := tagModel.Tag{Name: strings.TrimSpace(name), Slug: utils.Slugify(name)}
+	if tag.Slug == "" {
+		return nil, ErrInvalidTagName
+	}
+	if err := s.checkFree(tag.Name, tag.Slug, 0); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (s *TagService) FindAll() ([]tagModel.Tag, error) {
+	var tags []tagModel.Tag
+	if err := s.db.Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (s *TagService) FindByID(id uint) (*tagModel.Tag, error) {
+	var tag tagModel.Tag
+	if err := s.db.First(&tag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// FindByIDs loads every tag in ids, failing if any of them is missing.
+func (s *TagService) FindByIDs(ids []uint) ([]tagModel.Tag, error) {
+	tags := []tagModel.Tag{}
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	if err := s.db.Where("id IN ?", ids).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[uint]bool, len(tags))
+	for _, t := range tags {
+		found[t.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf("tag %d: %w", id, ErrTagNotFound)
+		}
+	}
+	return tags, nil
+}
+
+// FindOrCreate returns the tag for each name, matched by slug, creating the
+// ones that don't exist yet.
+func (s *TagService) FindOrCreate(names []string) ([]tagModel.Tag, error) {
+	tags := []tagModel.Tag{}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		slug := utils.Slugify(name)
+		if slug == "" {
+			return nil, fmt.Errorf("%q: %w", name, ErrInvalidTagName)
+		}
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+
+		tag := tagModel.Tag{Name: strings.TrimSpace(name), Slug: slug}
+		if err := s.db.Where("slug = ?", slug).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// Rename changes a tag's name and slug.
+func (s *TagService) Rename(id uint, name string) (*tagModel.Tag, error) {
+	if _, err := s.FindByID(id); err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	slug := utils.Slugify(name)
+	if slug == "" {
+		return nil, ErrInvalidTagName
+	}
+	if err := s.checkFree(name, slug, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(&tagModel.Tag{}).Where("id = ?", id).Updates(map[string]interface{}{"name": name, "slug": slug}).Error; err != nil {
+		return nil, err
+	}
+	return s.FindByID(id)
+}
+
+// Delete removes the tag from every audio and then the tag itself.
+func (s *TagService) Delete(id uint) error {
+	if _, err := s.FindByID(id); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM audio_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&tagModel.Tag{}, id).Error
+	})
+}
+
+// Merge moves every audio tagged with one of sourceIDs onto targetID and
+// deletes the source tags.
+func (s *TagService) Merge(targetID uint, sourceIDs []uint) (*tagModel.Tag, error) {
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return nil, ErrMergeIntoSelf
+		}
+	}
+	if _, err := s.FindByID(targetID); err != nil {
+		return nil, err
+	}
+	if _, err := s.FindByIDs(sourceIDs); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Audios that already carry the target keep a single link.
+		if err := tx.Exec(`INSERT INTO audio_tags (audio_id, tag_id)
+			SELECT DISTINCT audio_id, ? FROM audio_tags
+			WHERE tag_id IN ? AND audio_id NOT IN (SELECT audio_id FROM audio_tags WHERE tag_id = ?)`,
+			targetID, sourceIDs, targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM audio_tags WHERE tag_id IN ?", sourceIDs).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&tagModel.Tag{}, sourceIDs).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.FindByID(targetID)
+}
+
+// Cloud returns the tags used by public audios with their usage counts, most
+// used first.
+func (s *TagService) Cloud() ([]tagModel.TagCount, error) {
+	var cloud []tagModel.TagCount
+	err := s.db.Model(&tagModel.Tag{}).
+		Select("tags.*, COUNT(audios.id) AS count").
+		Joins("JOIN audio_tags ON audio_tags.tag_id = tags.id").
+		Joins("JOIN audios ON audios.id = audio_tags.audio_id AND audios.deleted_at IS NULL").
+		Scopes(audioModel.Listed).
+		Group("tags.id").
+		Order("count DESC, tags.name").
+		Scan(&cloud).Error
+	if err != nil {
+		return nil, err
+	}
+	return cloud, nil
+}
+
+// checkFree reports ErrTagExists when a tag other than exceptID already uses
+// name or slug.
+func (s *TagService) checkFree(name, slug string, exceptID uint) error {
+	var count int64
+	if err := s.db.Model(&tagModel.Tag{}).
+		Where("(name = ? OR slug = ?) AND id <> ?", name, slug, exceptID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrTagExists
+	}
+	return nil
+}
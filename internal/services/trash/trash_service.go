@@ -0,0 +1,370 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	mediaModel "mqfm-backend/internal/models/media"
+	revisionModel "mqfm-backend/internal/models/revision"
+	"mqfm-backend/internal/services/media"
+	"mqfm-backend/internal/utils"
+)
+
+var (
+	ErrUnknownType = errors.New("unknown trash type")
+	ErrNotInTrash  = errors.New("item is not in the trash")
+)
+
+// Item is a soft-deleted row as listed in the trash.
+type Item struct {
+	ID        uint       `json:"id"`
+	Label     string     `json:"label"`
+	DeletedAt time.Time  `json:"deleted_at"`
+	PurgeAt   *time.Time `json:"purge_at"`
+}
+
+// fileColumn is a column on a trashable table holding storage keys.
+type fileColumn struct {
+	Column   string
+	Variants bool
+}
+
+// trashable describes a soft-deleted table the trash manages. restore fixes
+// relations that may have gone stale while the row was away; purge removes
+// the rows depending on it and returns any storage keys they held.
+type trashable struct {
+	Table   string
+	Label   string
+	Files   []fileColumn
+	restore func(tx *gorm.DB, id uint) error
+	purge   func(tx *gorm.DB, id uint) ([]string, error)
+}
+
+// Types lists the trash types in the order they are summarised.
+var Types = []string{"audios", "categories", "shows", "speakers", "playlists", "users", "admins"}
+
+var playlist = trashable{
+	Table: "playlists",
+	Label: "name",
+	Files: []fileColumn{{Column: "image_url"}, {Column: "image_variants", Variants: true}},
+	purge: func(tx *gorm.DB, id uint) ([]string, error) {
+		return nil, execAll(tx, id, "DELETE FROM playlist_audios WHERE playlist_id = ?")
+	},
+}
+
+var trashables = map[string]trashable{
+	"audios": {
+		Table: "audios",
+		Label: "title",
+		Files: []fileColumn{{Column: "audio_url"}, {Column: "thumbnail"}, {Column: "thumbnail_variants", Variants: true}},
+		restore: func(tx *gorm.DB, id uint) error {
+			// Deleting a show only detaches its live episodes.
+			return tx.Exec(`UPDATE audios SET show_id = NULL WHERE id = ? AND show_id IS NOT NULL
+				AND show_id NOT IN (SELECT id FROM shows WHERE deleted_at IS NULL)`, id).Error
+		},
+		purge: purgeAudio,
+	},
+	"categories": {
+		Table: "categories",
+		Label: "name",
+		Files: []fileColumn{{Column: "icon"}, {Column: "icon_variants", Variants: true}, {Column: "cover"}, {Column: "cover_variants", Variants: true}},
+		restore: func(tx *gorm.DB, id uint) error {
+			return tx.Exec(`UPDATE categories SET parent_id = NULL WHERE id = ? AND parent_id IS NOT NULL
+				AND parent_id NOT IN (SELECT id FROM categories WHERE deleted_at IS NULL)`, id).Error
+		},
+		purge: func(tx *gorm.DB, id uint) ([]string, error) {
+			return nil, execAll(tx, id,
+				"UPDATE audios SET category_id = NULL WHERE category_id = ?",
+				"UPDATE shows SET category_id = NULL WHERE category_id = ?",
+				"UPDATE categories SET parent_id = NULL WHERE parent_id = ?",
+				"DELETE FROM revisions WHERE entity_type = '"+revisionModel.EntityCategory+"' AND entity_id = ?",
+			)
+		},
+	},
+	"shows": {
+		Table: "shows",
+		Label: "title",
+		Files: []fileColumn{{Column: "artwork"}, {Column: "artwork_variants", Variants: true}},
+		purge: func(tx *gorm.DB, id uint) ([]string, error) {
+			return nil, execAll(tx, id, "UPDATE audios SET show_id = NULL WHERE show_id = ?")
+		},
+	},
+	"speakers": {
+		Table: "speakers",
+		Label: "name",
+		Files: []fileColumn{{Column: "photo"}, {Column: "photo_variants", Variants: true}},
+		purge: func(tx *gorm.DB, id uint) ([]string, error) {
+			return nil, execAll(tx, id, "DELETE FROM audio_speakers WHERE speaker_id = ?")
+		},
+	},
+	"playlists": playlist,
+	"users": {
+		Table: "users",
+		Label: "username",
+		Files: []fileColumn{{Column: "profile_picture"}, {Column: "profile_picture_variants", Variants: true}},
+		purge: purgeUser,
+	},
+	"admins": {
+		Table: "admins",
+		Label: "username",
+		purge: func(tx *gorm.DB, id uint) ([]string, error) {
+			return nil, execAll(tx, id, "DELETE FROM notifications WHERE recipient_id = ?")
+		},
+	},
+}
+
+type TrashService struct {
+	db        *gorm.DB
+	cleanup   *media.CleanupService
+	retention time.Duration
+}
+
+// NewTrashService purges items retention after deletion when PurgeExpired
+// runs; a zero retention keeps them until purged by hand.
+func NewTrashService(db *gorm.DB, cleanup *media.CleanupService, retention time.Duration) *TrashService {
+	return &TrashService{db: db, cleanup: cleanup, retention: retention}
+}
+
+// Summary counts the items in the trash per type.
+func (s *TrashService) Summary() (map[string]int64, error) {
+	summary := make(map[string]int64, len(Types))
+	for _, kind := range Types {
+		var count int64
+		if err := s.db.Table(trashables[kind].Table).Where("deleted_at IS NOT NULL").Count(&count).Error; err != nil {
+			return nil, err
+		}
+		summary[kind] = count
+	}
+	return summary, nil
+}
+
+// List returns the trashed items of one type, most recently deleted first.
+func (s *TrashService) List(kind string) ([]Item, error) {
+	t, ok := trashables[kind]
+	if !ok {
+		return nil, ErrUnknownType
+	}
+
+	items := []Item{}
+	if err := s.db.Table(t.Table).
+		Select("id, " + t.Label + " AS label, deleted_at").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	if s.retention > 0 {
+		for i := range items {
+			purgeAt := items[i].DeletedAt.Add(s.retention)
+			items[i].PurgeAt = &purgeAt
+		}
+	}
+	return items, nil
+}
+
+// Restore takes an item out of the trash. Links kept while it was deleted,
+// such as renditions, tags or playlist entries, come back with it.
+func (s *TrashService) Restore(kind string, id uint) error {
+	t, ok := trashables[kind]
+	if !ok {
+		return ErrUnknownType
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(t.Table).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotInTrash
+		}
+		if t.restore != nil {
+			return t.restore(tx, id)
+		}
+		return nil
+	})
+}
+
+// Purge deletes a trashed item for good, along with the rows that depend on
+// it, and then removes files nothing else references.
+func (s *TrashService) Purge(kind string, id uint) error {
+	t, ok := trashables[kind]
+	if !ok {
+		return ErrUnknownType
+	}
+
+	var keys []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if keys, err = fileKeys(tx, t, id, true); err != nil {
+			return err
+		}
+		if t.purge != nil {
+			extra, err := t.purge(tx, id)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, extra...)
+		}
+		return tx.Exec("DELETE FROM "+t.Table+" WHERE id = ?", id).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cleanup.Release(keys...)
+	return nil
+}
+
+// PurgeExpired purges every item deleted longer ago than the retention
+// period. It runs from the scheduler in main.
+func (s *TrashService) PurgeExpired() error {
+	if s.retention <= 0 {
+		return nil
+	}
+	cutoff := time.Now().Add(-s.retention)
+
+	var failed error
+	for _, kind := range Types {
+		var ids []uint
+		if err := s.db.Table(trashables[kind].Table).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		purged := 0
+		for _, id := range ids {
+			if err := s.Purge(kind, id); err != nil {
+				utils.Log.Error("[Trash] Failed to purge expired item", zap.String("type", kind), zap.Uint("id", id), zap.Error(err))
+				failed = err
+				continue
+			}
+			purged++
+		}
+		if purged > 0 {
+			utils.Log.Info("[Trash] Expired items purged", zap.String("type", kind), zap.Int("count", purged))
+		}
+	}
+	return failed
+}
+
+// fileKeys reads the storage keys held by a row. With trashed set the row
+// must be in the trash.
+func fileKeys(tx *gorm.DB, t trashable, id uint, trashed bool) ([]string, error) {
+	row := map[string]interface{}{}
+	query := tx.Table(t.Table).Where("id = ?", id)
+	if trashed {
+		query = query.Where("deleted_at IS NOT NULL")
+	}
+	if len(t.Files) > 0 {
+		columns := make([]string, 0, len(t.Files))
+		for _, f := range t.Files {
+			columns = append(columns, f.Column)
+		}
+		query = query.Select(columns)
+	} else {
+		query = query.Select("id")
+	}
+	result := query.Take(&row)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotInTrash
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var keys []string
+	for _, f := range t.Files {
+		value := columnString(row[f.Column])
+		if !f.Variants {
+			keys = append(keys, value)
+			continue
+		}
+		var variants mediaModel.ImageVariants
+		if err := variants.Scan(value); err == nil {
+			keys = append(keys, variants.Keys()...)
+		}
+	}
+	return keys, nil
+}
+
+func columnString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+// purgeAudio removes an audio's renditions, chapters and every link to it,
+// returning the rendition files and chapter pictures.
+func purgeAudio(tx *gorm.DB, id uint) ([]string, error) {
+	var urls []string
+	if err := tx.Table("audio_renditions").Where("audio_id = ?", id).Pluck("url", &urls).Error; err != nil {
+		return nil, err
+	}
+	var chapters []struct {
+		Image         string
+		ImageVariants mediaModel.ImageVariants
+	}
+	if err := tx.Table("audio_chapters").Select("image, image_variants").Where("audio_id = ?", id).Scan(&chapters).Error; err != nil {
+		return nil, err
+	}
+	for _, chapter := range chapters {
+		urls = append(append(urls, chapter.Image), chapter.ImageVariants.Keys()...)
+	}
+	err := execAll(tx, id,
+		"DELETE FROM audio_renditions WHERE audio_id = ?",
+		"DELETE FROM audio_chapters WHERE audio_id = ?",
+		"DELETE FROM audio_waveforms WHERE audio_id = ?",
+		"DELETE FROM playlist_audios WHERE audio_id = ?",
+		"DELETE FROM likes WHERE audio_id = ?",
+		"DELETE FROM audio_speakers WHERE audio_id = ?",
+		"DELETE FROM audio_tags WHERE audio_id = ?",
+		"DELETE FROM audio_reviews WHERE audio_id = ?",
+		"DELETE FROM transcript_cues WHERE audio_id = ?",
+		"DELETE FROM audio_transcripts WHERE audio_id = ?",
+		"UPDATE notifications SET audio_id = NULL WHERE audio_id = ?",
+		"DELETE FROM revisions WHERE entity_type = '"+revisionModel.EntityAudio+"' AND entity_id = ?",
+	)
+	return urls, err
+}
+
+// purgeUser removes a user's likes and playlists, trashed or not, returning
+// the playlist covers.
+func purgeUser(tx *gorm.DB, id uint) ([]string, error) {
+	var playlists []uint
+	if err := tx.Table("playlists").Where("user_id = ?", id).Pluck("id", &playlists).Error; err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, playlistID := range playlists {
+		covers, err := fileKeys(tx, playlist, playlistID, false)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, covers...)
+		if err := execAll(tx, playlistID, "DELETE FROM playlist_audios WHERE playlist_id = ?", "DELETE FROM playlists WHERE id = ?"); err != nil {
+			return nil, err
+		}
+	}
+	return keys, execAll(tx, id, "DELETE FROM likes WHERE user_id = ?")
+}
+
+// execAll runs each statement with id as its only argument.
+func execAll(tx *gorm.DB, id uint, statements ...string) error {
+	for _, statement := range statements {
+		if err := tx.Exec(statement, id).Error; err != nil {
+			return fmt.Errorf("%s: %w", statement, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package upload
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	uploadModel "mqfm-backend/internal/models/upload"
+	"mqfm-backend/internal/services/media"
+	"mqfm-backend/internal/storage"
+	"mqfm-backend/internal/utils"
+)
+
+const uploadTTL = 24 * time.Hour
+
+// chunkPrefix is where upload chunks are kept in storage, away from the
+// served uploads/ tree.
+const chunkPrefix = "staging/tus/"
+
+var (
+	ErrUploadNotFound   = &media.IngestError{Status: http.StatusNotFound, Message: "upload not found"}
+	ErrOffsetMismatch   = &media.IngestError{Status: http.StatusConflict, Message: "upload offset does not match"}
+	ErrUploadIncomplete = &media.IngestError{Status: http.StatusBadRequest, Message: "upload is not complete"}
+	ErrUploadExpired    = &media.IngestError{Status: http.StatusGone, Message: "upload has expired"}
+	ErrUploadTooLarge   = &media.IngestError{Status: http.StatusRequestEntityTooLarge, Message: "upload length exceeds the maximum size"}
+)
+
+// TusService keeps upload state in the database and chunks in storage, so
+// requests for one upload may reach any instance.
+type TusService struct {
+	db      *gorm.DB
+	store   storage.Storage
+	ingest  *media.IngestService
+	maxSize int64
+	locks   sync.Map
+}
+
+func NewTusService(db *gorm.DB, store storage.Storage, ingest *media.IngestService, maxSize int64) *TusService {
+	return &TusService{db: db, store: store, ingest: ingest, maxSize: maxSize}
+}
+
+func (s *TusService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Create registers a new upload.
+func (s *TusService) Create(adminID uint, length int64, metadata string) (*uploadModel.Upload, error) {
+	if length > s.maxSize {
+		return nil, ErrUploadTooLarge
+	}
+
+	upload := uploadModel.Upload{
+		ID:        uuid.New().String(),
+		AdminID:   adminID,
+		Length:    length,
+		Metadata:  metadata,
+		Filename:  ParseMetadata(metadata)["filename"],
+		ExpiresAt: time.Now().Add(uploadTTL),
+	}
+
+	if err := s.db.Create(&upload).Error; err != nil {
+		return nil, err
+	}
+
+	utils.Log.Info("[Tus] Upload created",
+		zap.String("upload_id", upload.ID),
+		zap.Uint("admin_id", adminID),
+		zap.Int64("length", length),
+	)
+	return &upload, nil
+}
+
+func (s *TusService) Find(id string, adminID uint) (*uploadModel.Upload, error) {
+	var upload uploadModel.Upload
+	if err := s.db.Where("id = ? AND admin_id = ?", id, adminID).First(&upload).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return nil, ErrUploadExpired
+	}
+	return &upload, nil
+}
+
+// WriteChunk stores a PATCH body as the chunk at offset. Bytes received
+// before a dropped connection are kept, so the client can resume from the
+// returned offset.
+func (s *TusService) WriteChunk(id string, adminID uint, offset int64, body io.Reader) (*uploadModel.Upload, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	upload, err := s.Find(id, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if offset != upload.Offset {
+		return nil, ErrOffsetMismatch
+	}
+
+	// The body is spooled first so a partial one can still be stored with
+	// its size known.
+	tmp, err := os.CreateTemp("", "tus-chunk-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	written, copyErr := io.Copy(tmp, io.LimitReader(body, upload.Length-upload.Offset))
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	chunk := uploadModel.UploadChunk{
+		UploadID: id,
+		Offset:   offset,
+		Size:     written,
+		Key:      fmt.Sprintf("%s%s/%d-%s", chunkPrefix, id, offset, uuid.New().String()),
+	}
+	if written > 0 {
+		if err := s.store.Put(chunk.Key, tmp, written, "application/octet-stream"); err != nil {
+			return nil, err
+		}
+	}
+
+	upload.Offset += written
+	upload.ExpiresAt = time.Now().Add(uploadTTL)
+	updates := map[string]interface{}{
+		"offset":     upload.Offset,
+		"expires_at": upload.ExpiresAt,
+	}
+	if upload.IsComplete() {
+		now := time.Now()
+		upload.CompletedAt = &now
+		updates["completed_at"] = &now
+	}
+	// Another instance may have written at the same offset meanwhile; only
+	// one of them moves the offset on.
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&uploadModel.Upload{}).Where(map[string]interface{}{"id": id, "offset": offset}).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOffsetMismatch
+		}
+		if written == 0 {
+			return nil
+		}
+		return tx.Create(&chunk).Error
+	})
+	if err != nil {
+		if written > 0 {
+			s.store.Delete(chunk.Key)
+		}
+		return nil, err
+	}
+
+	if copyErr != nil {
+		utils.Log.Warn("[Tus] Chunk interrupted",
+			zap.Error(copyErr),
+			zap.String("upload_id", id),
+			zap.Int64("offset", upload.Offset),
+		)
+	}
+	return upload, nil
+}
+
+// Terminate discards an upload and its staged bytes.
+func (s *TusService) Terminate(id string, adminID uint) error {
+	unlock := s.lock(id)
+	defer unlock()
+
+	upload, err := s.Find(id, adminID)
+	if err != nil && !errors.Is(err, ErrUploadExpired) {
+		return err
+	}
+	if upload == nil {
+		upload = &uploadModel.Upload{ID: id}
+	}
+	return s.remove(upload)
+}
+
+// Consume hands a completed upload to media ingestion and removes its chunks.
+func (s *TusService) Consume(id string, adminID uint, kind media.Kind, subdir string) (*media.Asset, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	upload, err := s.Find(id, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if !upload.IsComplete() {
+		return nil, ErrUploadIncomplete
+	}
+
+	var chunks []uploadModel.UploadChunk
+	if err := s.db.Where("upload_id = ?", id).Order(clause.OrderByColumn{Column: clause.Column{Name: "offset"}}).Find(&chunks).Error; err != nil {
+		return nil, err
+	}
+	var next int64
+	for _, chunk := range chunks {
+		if chunk.Offset != next {
+			return nil, fmt.Errorf("upload %s is missing bytes at offset %d", id, next)
+		}
+		next += chunk.Size
+	}
+	if next != upload.Length {
+		return nil, fmt.Errorf("upload %s holds %d of %d bytes", id, next, upload.Length)
+	}
+
+	r := &chunkReader{store: s.store, chunks: chunks}
+	asset, err := s.ingest.Ingest(kind, subdir, r)
+	r.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.remove(upload); err != nil {
+		utils.Log.Warn("[Tus] Failed to remove consumed upload", zap.Error(err), zap.String("upload_id", id))
+	}
+	return asset, nil
+}
+
+// CleanupExpired removes uploads that were abandoned or never referenced.
+func (s *TusService) CleanupExpired() error {
+	var expired []uploadModel.Upload
+	if err := s.db.Where("expires_at < ?", time.Now()).Find(&expired).Error; err != nil {
+		return err
+	}
+
+	for i := range expired {
+		if err := s.remove(&expired[i]); err != nil {
+			utils.Log.Error("[Tus] Failed to remove expired upload", zap.Error(err), zap.String("upload_id", expired[i].ID))
+		}
+	}
+
+	if len(expired) > 0 {
+		utils.Log.Info("[Tus] Expired uploads removed", zap.Int("count", len(expired)))
+	}
+	return nil
+}
+
+func (s *TusService) remove(upload *uploadModel.Upload) error {
+	var chunks []uploadModel.UploadChunk
+	if err := s.db.Where("upload_id = ?", upload.ID).Find(&chunks).Error; err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if err := s.store.Delete(chunk.Key); err != nil {
+			return err
+		}
+	}
+	s.locks.Delete(upload.ID)
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("upload_id = ?", upload.ID).Delete(&uploadModel.UploadChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&uploadModel.Upload{}, "id = ?", upload.ID).Error
+	})
+}
+
+// chunkReader reads an upload's chunks in order, opening each one only when
+// the previous one is used up.
+type chunkReader struct {
+	store   storage.Storage
+	chunks  []uploadModel.UploadChunk
+	current io.ReadCloser
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.chunks) == 0 {
+				return 0, io.EOF
+			}
+			src, err := r.store.Get(r.chunks[0].Key)
+			if err != nil {
+				return 0, err
+			}
+			r.current, r.chunks = src, r.chunks[1:]
+		}
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *chunkReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Close()
+}
+
+func (s *TusService) lock(id string) func() {
+	m, _ := s.locks.LoadOrStore(id, &sync.Mutex{})
+	mu := m.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// ParseMetadata decodes the Upload-Metadata header ("key base64value,key2 ...").
+func ParseMetadata(header string) map[string]string {
+	meta := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), " ", 2)
+		if parts[0] == "" {
+			continue
+		}
+		value := ""
+		if len(parts) == 2 {
+			if decoded, err := base64.StdEncoding.DecodeString(parts[1]); err == nil {
+				value = string(decoded)
+			}
+		}
+		meta[parts[0]] = value
+	}
+	return meta
+}
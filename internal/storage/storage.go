@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"mqfm-backend/internal/config"
+)
+
+// ErrNotFound is returned when a key does not exist in the backend.
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Storage is where every piece of uploaded or generated media lives. Keys are
+// slash-separated paths such as "uploads/audios/<hash>.mp3", the same values
+// stored on the models.
+type Storage interface {
+	Put(key string, r io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	Stat(key string) (*ObjectInfo, error)
+	URL(key string) string
+	// List returns every object whose key starts with prefix.
+	List(prefix string) ([]ObjectInfo, error)
+}
+
+// New builds the driver selected by STORAGE_DRIVER.
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocalStorage(cfg.LocalRoot, cfg.PublicBaseURL), nil
+	case "s3":
+		return NewS3Storage(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// escapeKey percent-encodes each path segment so legacy names with spaces or
+// '#' still produce valid URLs.
+func escapeKey(key string) string {
+	segments := strings.Split(strings.TrimLeft(key, "/"), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+func cleanKey(key string) (string, error) {
+	key = strings.TrimLeft(key, "/")
+	for _, seg := range strings.Split(key, "/") {
+		if seg == ".." {
+			return "", fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	if key == "" {
+		return "", errors.New("empty storage key")
+	}
+	return key, nil
+}
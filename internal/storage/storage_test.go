@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"mqfm-backend/internal/config"
+)
+
+// testDriver runs the behaviour every driver must share against store, using
+// keys under a fresh prefix so runs against a shared bucket do not collide.
+func testDriver(t *testing.T, store Storage) {
+	prefix := "test-" + uuid.New().String() + "/"
+	keys := []string{prefix + "audios/a b#1.mp3", prefix + "audios/b.mp3", prefix + "images/c.jpg"}
+	t.Cleanup(func() {
+		for _, key := range keys {
+			store.Delete(key)
+		}
+	})
+
+	for _, key := range keys {
+		content := "content of " + key
+		if err := store.Put(key, strings.NewReader(content), int64(len(content)), "application/octet-stream"); err != nil {
+			t.Fatalf("Put %s: %v", key, err)
+		}
+	}
+
+	r, err := store.Get(keys[0])
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	content, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || string(content) != "content of "+keys[0] {
+		t.Errorf("Get returned %q, %v", content, err)
+	}
+
+	info, err := store.Stat(keys[1])
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Key != keys[1] || info.Size != int64(len("content of "+keys[1])) {
+		t.Errorf("Stat returned %+v", info)
+	}
+
+	objects, err := store.List(prefix + "audios/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var listed []string
+	for _, obj := range objects {
+		listed = append(listed, obj.Key)
+	}
+	sort.Strings(listed)
+	if len(listed) != 2 || listed[0] != keys[0] || listed[1] != keys[1] {
+		t.Errorf("List returned %v", listed)
+	}
+
+	if url := store.URL(keys[0]); !strings.HasSuffix(url, "/"+prefix+"audios/a%20b%231.mp3") {
+		t.Errorf("URL returned %s", url)
+	}
+
+	if err := store.Delete(keys[2]); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Stat(keys[2]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat after Delete returned %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(keys[2]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete returned %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(keys[2]); err != nil {
+		t.Errorf("Delete of a missing key returned %v", err)
+	}
+
+	if err := store.Put("../escape.mp3", strings.NewReader("x"), 1, "audio/mpeg"); err == nil {
+		t.Error("Put accepted a key leaving the store")
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	testDriver(t, NewLocalStorage(t.TempDir(), "http://localhost:8080"))
+}
+
+// TestS3Storage runs against a MinIO server, e.g.
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	MINIO_TEST_ENDPOINT=localhost:9000 go test ./internal/storage
+//
+// Credentials default to MinIO's minioadmin/minioadmin.
+func TestS3Storage(t *testing.T) {
+	endpoint := os.Getenv("MINIO_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("MINIO_TEST_ENDPOINT is not set")
+	}
+
+	store, err := NewS3Storage(config.StorageConfig{
+		Driver:      "s3",
+		S3Endpoint:  endpoint,
+		S3Bucket:    envOr("MINIO_TEST_BUCKET", "mqfm-test"),
+		S3AccessKey: envOr("MINIO_TEST_ACCESS_KEY", "minioadmin"),
+		S3SecretKey: envOr("MINIO_TEST_SECRET_KEY", "minioadmin"),
+		S3UseSSL:    os.Getenv("MINIO_TEST_USE_SSL") == "true",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	testDriver(t, store)
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,16 @@
+package utils
+
+import "strings"
+
+// ETagMatches reports whether an If-None-Match header lists etag. The
+// comparison is weak, as RFC 9110 asks for If-None-Match, so W/ tags match
+// too, and "*" matches anything.
+func ETagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
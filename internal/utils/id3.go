@@ -0,0 +1,235 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxID3TagBytes bounds how much of a tag is read; cover art makes most of it.
+const maxID3TagBytes = 32 << 20
+
+// ID3Chapter is a CHAP frame from an ID3v2 tag. Times are milliseconds and
+// Image holds the raw bytes of an embedded APIC picture.
+type ID3Chapter struct {
+	ElementID string
+	StartMs   int
+	EndMs     int
+	Title     string
+	URL       string
+	Image     []byte
+}
+
+// ID3Chapters reads the chapter frames of the ID3v2.3 or v2.4 tag at the
+// start of r, in start order. It returns none when there is no tag.
+func ID3Chapters(r io.Reader) ([]ID3Chapter, error) {
+	head := make([]byte, 10)
+	if _, err := io.ReadFull(r, head); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if string(head[:3]) != "ID3" {
+		return nil, nil
+	}
+	version := head[3]
+	if version != 3 && version != 4 {
+		// ID3v2.2 predates chapters.
+		return nil, nil
+	}
+	flags := head[5]
+	size := synchsafe(head[6:10])
+	if size > maxID3TagBytes {
+		return nil, errors.New("id3 tag is too large")
+	}
+
+	tag := make([]byte, size)
+	if _, err := io.ReadFull(r, tag); err != nil {
+		return nil, err
+	}
+	// ID3v2.3 unsynchronises the whole tag, v2.4 each frame.
+	if version == 3 && flags&0x80 != 0 {
+		tag = unsynchronise(tag)
+	}
+	if flags&0x40 != 0 {
+		if len(tag) < 4 {
+			return nil, errors.New("id3 extended header is truncated")
+		}
+		skip := int(binary.BigEndian.Uint32(tag[:4])) + 4
+		if version == 4 {
+			skip = synchsafe(tag[:4])
+		}
+		if skip > len(tag) {
+			return nil, errors.New("id3 extended header is truncated")
+		}
+		tag = tag[skip:]
+	}
+
+	var chapters []ID3Chapter
+	for _, frame := range id3Frames(tag, version) {
+		if frame.id != "CHAP" {
+			continue
+		}
+		if chapter, ok := parseCHAP(frame.data, version); ok {
+			chapters = append(chapters, chapter)
+		}
+	}
+	sort.SliceStable(chapters, func(a, b int) bool { return chapters[a].StartMs < chapters[b].StartMs })
+	return chapters, nil
+}
+
+type id3Frame struct {
+	id   string
+	data []byte
+}
+
+// id3Frames splits data into frames, skipping compressed and encrypted ones.
+// It stops at padding or at the first frame that runs past the end.
+func id3Frames(data []byte, version byte) []id3Frame {
+	var frames []id3Frame
+	for len(data) >= 10 && data[0] != 0 {
+		id := string(data[:4])
+		size := int(binary.BigEndian.Uint32(data[4:8]))
+		if version == 4 {
+			size = synchsafe(data[4:8])
+		}
+		format := data[9]
+		if size > len(data)-10 {
+			break
+		}
+		body := data[10 : 10+size]
+		data = data[10+size:]
+
+		// Flags that put extra bytes before the frame data differ by version.
+		compressed, group, unsynced, lengthIndicator := format&0xC0 != 0, format&0x20 != 0, false, false
+		if version == 4 {
+			compressed, group, unsynced, lengthIndicator = format&0x0C != 0, format&0x40 != 0, format&0x02 != 0, format&0x01 != 0
+		}
+		if compressed {
+			continue
+		}
+		if group && len(body) > 0 {
+			body = body[1:]
+		}
+		if lengthIndicator && len(body) >= 4 {
+			body = body[4:]
+		}
+		if unsynced {
+			body = unsynchronise(body)
+		}
+		frames = append(frames, id3Frame{id: id, data: body})
+	}
+	return frames
+}
+
+// parseCHAP reads a CHAP frame: element ID, start and end times, byte offsets
+// and then sub-frames for the title, link and picture.
+func parseCHAP(data []byte, version byte) (ID3Chapter, bool) {
+	end := bytes.IndexByte(data, 0)
+	if end < 0 || len(data) < end+17 {
+		return ID3Chapter{}, false
+	}
+	chapter := ID3Chapter{
+		ElementID: string(data[:end]),
+		StartMs:   int(binary.BigEndian.Uint32(data[end+1:])),
+		EndMs:     int(binary.BigEndian.Uint32(data[end+5:])),
+	}
+
+	for _, frame := range id3Frames(data[end+17:], version) {
+		switch {
+		case frame.id == "TIT2":
+			if len(frame.data) > 0 {
+				chapter.Title = strings.TrimSpace(decodeID3Text(frame.data[0], frame.data[1:]))
+			}
+		case frame.id == "WXXX":
+			if len(frame.data) > 0 {
+				_, rest := splitID3String(frame.data[0], frame.data[1:])
+				chapter.URL = strings.TrimSpace(decodeID3Text(0, rest))
+			}
+		case frame.id == "APIC":
+			chapter.Image = apicData(frame.data)
+		case strings.HasPrefix(frame.id, "W") && chapter.URL == "":
+			chapter.URL = strings.TrimSpace(decodeID3Text(0, frame.data))
+		}
+	}
+	return chapter, true
+}
+
+// apicData returns the picture bytes of an APIC frame: encoding, MIME type,
+// picture type and description come first.
+func apicData(data []byte) []byte {
+	if len(data) < 2 {
+		return nil
+	}
+	encoding := data[0]
+	mimeEnd := bytes.IndexByte(data[1:], 0)
+	if mimeEnd < 0 || len(data) < mimeEnd+3 {
+		return nil
+	}
+	_, picture := splitID3String(encoding, data[mimeEnd+3:])
+	return picture
+}
+
+// splitID3String cuts a terminated string in the given text encoding off the
+// front of data.
+func splitID3String(encoding byte, data []byte) (string, []byte) {
+	if encoding == 1 || encoding == 2 {
+		for i := 0; i+1 < len(data); i += 2 {
+			if data[i] == 0 && data[i+1] == 0 {
+				return decodeID3Text(encoding, data[:i]), data[i+2:]
+			}
+		}
+		return decodeID3Text(encoding, data), nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return decodeID3Text(encoding, data[:i]), data[i+1:]
+	}
+	return decodeID3Text(encoding, data), nil
+}
+
+// decodeID3Text decodes ISO-8859-1 (0), UTF-16 with a BOM (1), UTF-16BE (2)
+// or UTF-8 (3) text, dropping terminators.
+func decodeID3Text(encoding byte, data []byte) string {
+	switch encoding {
+	case 1, 2:
+		bigEndian := encoding == 2
+		if len(data) >= 2 {
+			if data[0] == 0xFF && data[1] == 0xFE {
+				bigEndian, data = false, data[2:]
+			} else if data[0] == 0xFE && data[1] == 0xFF {
+				bigEndian, data = true, data[2:]
+			}
+		}
+		units := make([]uint16, 0, len(data)/2)
+		for i := 0; i+1 < len(data); i += 2 {
+			if bigEndian {
+				units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+			} else {
+				units = append(units, uint16(data[i+1])<<8|uint16(data[i]))
+			}
+		}
+		return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+	case 3:
+		return strings.TrimRight(string(data), "\x00")
+	}
+	runes := make([]rune, 0, len(data))
+	for _, b := range data {
+		runes = append(runes, rune(b))
+	}
+	return strings.TrimRight(string(runes), "\x00")
+}
+
+func synchsafe(b []byte) int {
+	return int(b[0]&0x7F)<<21 | int(b[1]&0x7F)<<14 | int(b[2]&0x7F)<<7 | int(b[3]&0x7F)
+}
+
+// unsynchronise undoes ID3 unsynchronisation, which inserts a zero byte after
+// every 0xFF.
+func unsynchronise(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte{0xFF, 0x00}, []byte{0xFF})
+}
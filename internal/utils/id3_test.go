@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+// chapterTag is an ID3v2.4 tag laid out the way podcast taggers write it: a
+// title, a table of contents and two chapters stored out of order, followed by
+// padding.
+var chapterTag = "" +
+	// header, v2.4
+	"49443304000000000170" +
+	// TIT2 "Kajian Subuh", UTF-8
+	"544954320000000d0000034b616a69616e205375627568" +
+	// CTOC "toc", top level and ordered, chp0 chp1
+	"43544f43000000100000746f6300030263687030006368703100" +
+	// CHAP "chp1" 60000-120000 ms, no byte offsets
+	"434841500000002a000063687031000000ea600001d4c0ffffffffffffffff54" +
+	"4954320000000b00000350656d6261686173616e" +
+	// CHAP "chp0" 0-60000 ms with a UTF-16 title, link and picture
+	"434841500000007100006368703000000000000000ea60ffffffffffffffff54" +
+	"49543200000013000001fffe500065006d00620075006b006100000057585858" +
+	"000000190000000068747470733a2f2f6d71666d2e69642f70656d62756b6141" +
+	"50494300000012000000696d6167652f6a706567000300ffd8ffe0" +
+	// padding
+	"00000000000000000000000000000000"
+
+func TestID3ChaptersFixture(t *testing.T) {
+	tag, err := hex.DecodeString(chapterTag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	audio := append(tag, 0xFF, 0xFB, 0x90, 0xC4)
+
+	chapters, err := ID3Chapters(bytes.NewReader(audio))
+	if err != nil {
+		t.Fatalf("ID3Chapters: %v", err)
+	}
+	want := []ID3Chapter{
+		{ElementID: "chp0", StartMs: 0, EndMs: 60000, Title: "Pembuka", URL: "https://mqfm.id/pembuka", Image: []byte{0xFF, 0xD8, 0xFF, 0xE0}},
+		{ElementID: "chp1", StartMs: 60000, EndMs: 120000, Title: "Pembahasan"},
+	}
+	if !reflect.DeepEqual(chapters, want) {
+		t.Errorf("got %+v, want %+v", chapters, want)
+	}
+}
+
+// testFrame encodes an ID3v2 frame; v2.4 sizes are synchsafe.
+func testFrame(version byte, id string, flags uint16, data []byte) []byte {
+	size := make([]byte, 4)
+	if version == 4 {
+		size = synchsafeBytes(len(data))
+	} else {
+		binary.BigEndian.PutUint32(size, uint32(len(data)))
+	}
+	frame := append([]byte(id), size...)
+	frame = binary.BigEndian.AppendUint16(frame, flags)
+	return append(frame, data...)
+}
+
+func testCHAP(version byte, elementID string, startMs, endMs uint32, sub ...[]byte) []byte {
+	data := append([]byte(elementID), 0)
+	data = binary.BigEndian.AppendUint32(data, startMs)
+	data = binary.BigEndian.AppendUint32(data, endMs)
+	data = append(data, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)
+	return testFrame(version, "CHAP", 0, append(data, bytes.Join(sub, nil)...))
+}
+
+func testTag(version, flags byte, frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	tag := append([]byte{'I', 'D', '3', version, 0, flags}, synchsafeBytes(len(body))...)
+	return append(tag, body...)
+}
+
+func synchsafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+}
+
+func TestID3Chapters(t *testing.T) {
+	title := func(version byte, text string) []byte {
+		return testFrame(version, "TIT2", 0, append([]byte{3}, text...))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want []ID3Chapter
+	}{
+		{
+			name: "v2.3",
+			data: testTag(3, 0,
+				testCHAP(3, "b", 5000, 9000, title(3, "Dua")),
+				testCHAP(3, "a", 0, 5000, title(3, "Satu"), testFrame(3, "WOAR", 0, []byte("https://mqfm.id"))),
+			),
+			want: []ID3Chapter{
+				{ElementID: "a", StartMs: 0, EndMs: 5000, Title: "Satu", URL: "https://mqfm.id"},
+				{ElementID: "b", StartMs: 5000, EndMs: 9000, Title: "Dua"},
+			},
+		},
+		{
+			name: "v2.3 unsynchronised with an extended header",
+			data: func() []byte {
+				body := testCHAP(3, "a", 0, 0xFF00, title(3, "Satu"))
+				body = bytes.ReplaceAll(body, []byte{0xFF}, []byte{0xFF, 0x00})
+				extended := []byte{0, 0, 0, 6, 0, 0, 0, 0, 0, 0}
+				return testTag(3, 0xC0, extended, body)
+			}(),
+			want: []ID3Chapter{{ElementID: "a", StartMs: 0, EndMs: 0xFF00, Title: "Satu"}},
+		},
+		{
+			name: "v2.4 frame with data length indicator",
+			data: testTag(4, 0, testFrame(4, "CHAP", 0x0001, append([]byte{0, 0, 0, 30}, testCHAP(4, "a", 0, 1000)[10:]...))),
+			want: []ID3Chapter{{ElementID: "a", StartMs: 0, EndMs: 1000}},
+		},
+		{
+			name: "compressed frames are skipped",
+			data: testTag(3, 0, testFrame(3, "CHAP", 0x0080, testCHAP(3, "x", 0, 1)[10:]), testCHAP(3, "a", 0, 1000)),
+			want: []ID3Chapter{{ElementID: "a", StartMs: 0, EndMs: 1000}},
+		},
+		{
+			name: "truncated CHAP is skipped",
+			data: testTag(3, 0, testFrame(3, "CHAP", 0, []byte("a\x00\x00\x00")), testCHAP(3, "b", 0, 1000)),
+			want: []ID3Chapter{{ElementID: "b", StartMs: 0, EndMs: 1000}},
+		},
+		{
+			name: "oversized frame ends the tag",
+			data: func() []byte {
+				tag := testTag(3, 0, testCHAP(3, "a", 0, 1000), testCHAP(3, "b", 1000, 2000))
+				// Claim the second CHAP runs well past the end of the tag.
+				second := len(tag) - len(testCHAP(3, "b", 1000, 2000))
+				binary.BigEndian.PutUint32(tag[second+4:], 1<<20)
+				return tag
+			}(),
+			want: []ID3Chapter{{ElementID: "a", StartMs: 0, EndMs: 1000}},
+		},
+		{
+			name: "oversized sub-frame keeps the chapter",
+			data: func() []byte {
+				chap := testCHAP(3, "a", 0, 1000, title(3, "Satu"))
+				binary.BigEndian.PutUint32(chap[len(chap)-len(title(3, "Satu"))+4:], 1000)
+				return testTag(3, 0, chap)
+			}(),
+			want: []ID3Chapter{{ElementID: "a", StartMs: 0, EndMs: 1000}},
+		},
+		{name: "no tag", data: []byte{0xFF, 0xFB, 0x90, 0xC4}},
+		{name: "v2.2 tag", data: testTag(2, 0, testCHAP(3, "a", 0, 1000))},
+		{name: "shorter than a header", data: []byte("ID3")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chapters, err := ID3Chapters(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("ID3Chapters: %v", err)
+			}
+			if !reflect.DeepEqual(chapters, tt.want) {
+				t.Errorf("got %+v, want %+v", chapters, tt.want)
+			}
+		})
+	}
+}
+
+func TestID3ChaptersErrors(t *testing.T) {
+	full := testTag(3, 0, testCHAP(3, "a", 0, 1000))
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated tag", full[:len(full)-5]},
+		{"oversized tag", append([]byte{'I', 'D', '3', 3, 0, 0}, synchsafeBytes(maxID3TagBytes+1)...)},
+		{"truncated extended header", testTag(3, 0x40, []byte{0, 0})},
+		{"extended header past the tag", testTag(3, 0x40, []byte{0, 0, 0, 60, 0, 0})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if chapters, err := ID3Chapters(bytes.NewReader(tt.data)); err == nil {
+				t.Errorf("got %+v, want an error", chapters)
+			}
+		})
+	}
+}
+
+func TestID3Frames(t *testing.T) {
+	a := testFrame(3, "TIT2", 0, []byte("\x00Satu"))
+	b := testFrame(3, "TALB", 0, []byte("\x00Dua"))
+
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{"frames", append(a, b...), []string{"TIT2", "TALB"}},
+		{"padding", append(append(a, make([]byte, 20)...), b...), []string{"TIT2"}},
+		{"truncated header", append(a, b[:8]...), []string{"TIT2"}},
+		{"truncated body", append(a, b[:len(b)-1]...), []string{"TIT2"}},
+		{"size past the end", append(testFrame(3, "TIT2", 0, nil)[:4], 0x7F, 0xFF, 0xFF, 0xFF, 0, 0), nil},
+		{"empty frame", testFrame(3, "TIT2", 0, nil), []string{"TIT2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			for _, frame := range id3Frames(tt.data, 3) {
+				ids = append(ids, frame.id)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("got frames %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
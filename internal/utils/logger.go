@@ -1,21 +1,21 @@
-package utils
-
-import (
-	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
-
-)
-
-var Log *zap.Logger
-
-func init() {
-	config := zap.NewProductionConfig()
-	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	config.OutputPaths = []string{"stdout"}
-
-	var err error
-	Log, err = config.Build()
-	if err != nil {
-		panic(err)
-	}
-}
\ No newline at end of file
+package utils
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+)
+
+var Log *zap.Logger
+
+func init() {
+	config := zap.NewProductionConfig()
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	config.OutputPaths = []string{"stdout"}
+
+	var err error
+	Log, err = config.Build()
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+var (
+	mp3Bitrates = [2][3][16]int{
+		{ // MPEG-1: layer I, II, III
+			{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0},
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},
+			{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+		},
+		{ // MPEG-2 / 2.5: layer I, II, III
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+		},
+	}
+	mp3SampleRates = [4][3]int{
+		{11025, 12000, 8000},  // MPEG-2.5
+		{},                    // reserved
+		{22050, 24000, 16000}, // MPEG-2
+		{44100, 48000, 32000}, // MPEG-1
+	}
+)
+
+// MP3Duration walks the MPEG frame headers of an MP3 file and returns its
+// playing time in whole seconds.
+func MP3Duration(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReaderSize(f, 64*1024)
+	if err := skipID3v2(r); err != nil {
+		return 0, err
+	}
+
+	var seconds float64
+	var frames int
+	header := make([]byte, 4)
+	for {
+		b, err := r.Peek(4)
+		if err != nil {
+			break
+		}
+		copy(header, b)
+
+		size, samples, rate, ok := parseFrameHeader(header)
+		if !ok {
+			// Resync one byte at a time past junk or trailing tags.
+			if _, err := r.Discard(1); err != nil {
+				break
+			}
+			continue
+		}
+
+		seconds += float64(samples) / float64(rate)
+		frames++
+		if _, err := r.Discard(size); err != nil {
+			break
+		}
+	}
+
+	if frames == 0 {
+		return 0, errors.New("no mpeg audio frames found")
+	}
+	return int(seconds + 0.5), nil
+}
+
+func skipID3v2(r *bufio.Reader) error {
+	head, err := r.Peek(10)
+	if err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	if string(head[:3]) != "ID3" {
+		return nil
+	}
+	size := int(head[6])<<21 | int(head[7])<<14 | int(head[8])<<7 | int(head[9])
+	if head[5]&0x10 != 0 {
+		size += 10 // footer
+	}
+	_, err = r.Discard(10 + size)
+	return err
+}
+
+func parseFrameHeader(h []byte) (size, samples, rate int, ok bool) {
+	v := binary.BigEndian.Uint32(h)
+	if v>>21 != 0x7FF {
+		return 0, 0, 0, false
+	}
+
+	version := (v >> 19) & 0x3
+	layer := (v >> 17) & 0x3
+	bitrateIdx := (v >> 12) & 0xF
+	rateIdx := (v >> 10) & 0x3
+	padding := int((v >> 9) & 0x1)
+
+	if version == 1 || layer == 0 || bitrateIdx == 0 || bitrateIdx == 0xF || rateIdx == 3 {
+		return 0, 0, 0, false
+	}
+
+	row := 0
+	if version != 3 {
+		row = 1
+	}
+	layerIdx := 3 - int(layer) // 0 = layer I
+	bitrate := mp3Bitrates[row][layerIdx][bitrateIdx] * 1000
+	rate = mp3SampleRates[version][rateIdx]
+
+	switch layerIdx {
+	case 0:
+		samples = 384
+		size = (12*bitrate/rate + padding) * 4
+	case 1:
+		samples = 1152
+		size = 144*bitrate/rate + padding
+	default:
+		samples = 1152
+		if version != 3 {
+			samples = 576
+		}
+		size = samples/8*bitrate/rate + padding
+	}
+
+	if size < 4 {
+		return 0, 0, 0, false
+	}
+	return size, samples, rate, true
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	// MPEG-1 layer III, 128 kbps, 44.1 kHz: 417 bytes (418 padded) and 1152
+	// samples a frame.
+	mpeg1Frame       = mp3Frame([]byte{0xFF, 0xFB, 0x90, 0xC4}, 417)
+	mpeg1PaddedFrame = mp3Frame([]byte{0xFF, 0xFB, 0x92, 0xC4}, 418)
+	// MPEG-2 layer III, 64 kbps, 22.05 kHz: 208 bytes and 576 samples a frame.
+	mpeg2Frame = mp3Frame([]byte{0xFF, 0xF3, 0x80, 0xC4}, 208)
+)
+
+func mp3Frame(header []byte, size int) []byte {
+	frame := make([]byte, size)
+	copy(frame, header)
+	return frame
+}
+
+func writeMP3(t *testing.T, parts ...[]byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audio.mp3")
+	if err := os.WriteFile(path, bytes.Join(parts, nil), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMP3Duration(t *testing.T) {
+	// 383 frames of 1152 samples at 44.1 kHz play for 10.005 seconds.
+	tenSeconds := bytes.Repeat(mpeg1Frame, 383)
+	id3 := testTag(3, 0, testFrame(3, "TIT2", 0, []byte("\x00Kajian")))
+	id3WithFooter := append(testTag(4, 0x10, testFrame(4, "TIT2", 0, []byte("\x00Kajian"))), []byte("3DI\x04\x00\x10\x00\x00\x00\x00")...)
+	copy(id3WithFooter[len(id3WithFooter)-4:], id3WithFooter[6:10])
+	id3v1 := append([]byte("TAG"), make([]byte, 125)...)
+
+	tests := []struct {
+		name  string
+		parts [][]byte
+		want  int
+	}{
+		{"mpeg-1", [][]byte{tenSeconds}, 10},
+		{"mpeg-2", [][]byte{bytes.Repeat(mpeg2Frame, 383)}, 10},
+		{"padded frames", [][]byte{bytes.Repeat(append(mpeg1Frame, mpeg1PaddedFrame...), 100)}, 5},
+		{"id3v2 tag", [][]byte{id3, tenSeconds}, 10},
+		{"id3v2 tag with footer", [][]byte{id3WithFooter, tenSeconds}, 10},
+		{"junk and id3v1 tag", [][]byte{[]byte("junk"), tenSeconds[:len(tenSeconds)/2], {0, 0, 0}, tenSeconds[len(tenSeconds)/2:], id3v1}, 10},
+		{"truncated last frame", [][]byte{tenSeconds[:len(tenSeconds)-100]}, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seconds, err := MP3Duration(writeMP3(t, tt.parts...))
+			if err != nil {
+				t.Fatalf("MP3Duration: %v", err)
+			}
+			if seconds != tt.want {
+				t.Errorf("got %d seconds, want %d", seconds, tt.want)
+			}
+		})
+	}
+}
+
+func TestMP3DurationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"missing file", filepath.Join(t.TempDir(), "missing.mp3")},
+		{"empty file", writeMP3(t)},
+		{"no frames", writeMP3(t, []byte("not an mp3 at all"))},
+		{"bad frame headers", writeMP3(t, mp3Frame([]byte{0xFF, 0xFB, 0xF0, 0xC4}, 417), mp3Frame([]byte{0xFF, 0xFB, 0x9C, 0xC4}, 417))},
+		{"tag only", writeMP3(t, testTag(3, 0, testFrame(3, "TIT2", 0, []byte("\x00Kajian"))))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if seconds, err := MP3Duration(tt.path); err == nil {
+				t.Errorf("got %d seconds, want an error", seconds)
+			}
+		})
+	}
+}